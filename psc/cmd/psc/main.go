@@ -0,0 +1,265 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/compiler"
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/parser"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/vm"
+)
+
+const usage = `usage: psc <command> [flags] <file>
+
+commands:
+  lex     cetak token
+  parse   cetak parse tree dan error sintaks
+  check   cek semantik, cetak semua diagnostik
+  run     jalankan program
+  dump    cetak DST dan tabel simbol, atau P-code (--what pcode)
+
+jalankan 'psc <command> -h' untuk flag tiap command
+`
+
+var commands = map[string]func(args []string) int{
+	"lex":   lexCommand,
+	"parse": parseCommand,
+	"check": checkCommand,
+	"run":   runCommand,
+	"dump":  dumpCommand,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "-h" || name == "--help" || name == "help" {
+		fmt.Print(usage)
+		return
+	}
+
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
+	}
+
+	os.Exit(command(os.Args[2:]))
+}
+
+type sourceFlags struct {
+	rules   *string
+	lang    *string
+	input   *string
+	defines compiler.Defines
+	units   compiler.UnitPath
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *sourceFlags) {
+	fs := flag.NewFlagSet("psc "+name, flag.ExitOnError)
+
+	src := &sourceFlags{
+		rules: fs.String("rules", "", "path ke DFA JSON (default: sesuai --lang)"),
+		lang:  fs.String("lang", "indo", "bahasa keyword: indo | en"),
+		input: fs.String("input", "", "path file sumber, atau - untuk stdin (boleh juga argumen posisi)"),
+	}
+	fs.Var(&src.defines, "D", "definisikan simbol untuk {$IFDEF}; boleh diulang atau dipisah koma")
+	fs.Var(&src.units, "unit-path", "direktori tambahan untuk mencari unit; boleh diulang atau dipisah seperti PATH")
+
+	return fs, src
+}
+
+// compile runs the pipeline up to stage. A nil result means the failure was
+// already printed and the command should exit with code.
+func (s *sourceFlags) compile(fs *flag.FlagSet, stage compiler.Stage) (*compiler.Result, int) {
+	path := *s.input
+	if path == "" && fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "missing input file")
+		return nil, 2
+	}
+
+	lang, err := dt.LookupLanguageProfile(*s.lang)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, 2
+	}
+
+	res, err := compiler.CompileFile(path, compiler.Options{
+		Lang:      lang,
+		Rules:     *s.rules,
+		StopAfter: stage,
+		Defines:   s.defines,
+		UnitPath:  s.units,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, 1
+	}
+
+	return res, 0
+}
+
+// printDiagnostics writes every diagnostic of res to stderr and reports
+// whether there were any.
+func printDiagnostics(res *compiler.Result) bool {
+	for _, e := range res.Diagnostics() {
+		fmt.Fprintln(os.Stderr, e)
+	}
+
+	if n := len(res.SemanticErrors); n > 0 {
+		fmt.Fprintf(os.Stderr, "%d semantic error(s)\n", n)
+	}
+
+	return res.HasErrors()
+}
+
+func lexCommand(args []string) int {
+	fs, src := newFlagSet("lex")
+	fs.Parse(args)
+
+	res, code := src.compile(fs, compiler.STAGE_LEX)
+	if res == nil {
+		return code
+	}
+
+	for _, t := range res.Tokens {
+		fmt.Printf("%s(%s)\n", t.Type.String(), t.Lexeme)
+	}
+
+	if printDiagnostics(res) {
+		return 1
+	}
+	return 0
+}
+
+func parseCommand(args []string) int {
+	fs, src := newFlagSet("parse")
+	maxErrors := fs.Int("max-errors", 20, "jumlah maksimum error sintaks yang dicetak (0 = semua)")
+	fs.Parse(args)
+
+	res, code := src.compile(fs, compiler.STAGE_PARSE)
+	if res == nil {
+		return code
+	}
+
+	if len(res.LexErrors) > 0 {
+		printDiagnostics(res)
+		return 1
+	}
+
+	parser.PrintErrors(os.Stdout, res.ParseErrors, *maxErrors)
+
+	if res.Tree != nil {
+		fmt.Println(res.Tree.String())
+	}
+
+	if len(res.ParseErrors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func checkCommand(args []string) int {
+	fs, src := newFlagSet("check")
+	fs.Parse(args)
+
+	res, code := src.compile(fs, compiler.STAGE_CHECK)
+	if res == nil {
+		return code
+	}
+
+	if printDiagnostics(res) {
+		return 1
+	}
+	return 0
+}
+
+func runCommand(args []string) int {
+	fs, src := newFlagSet("run")
+	engine := fs.String("engine", string(compiler.ENGINE_INTERP), "mesin eksekusi: interp | vm")
+	stackSize := fs.Int("stack", vm.DefaultStackSize, "ukuran stack VM dalam sel")
+	fs.Parse(args)
+
+	if *engine != string(compiler.ENGINE_INTERP) && *engine != string(compiler.ENGINE_VM) {
+		fmt.Fprintf(os.Stderr, "unknown --engine value %q\n", *engine)
+		return 2
+	}
+
+	res, code := src.compile(fs, compiler.STAGE_CHECK)
+	if res == nil {
+		return code
+	}
+
+	if printDiagnostics(res) {
+		return 1
+	}
+
+	err := res.Run(os.Stdout, compiler.RunOptions{
+		Engine:    compiler.Engine(*engine),
+		StackSize: *stackSize,
+		In:        os.Stdin,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func dumpCommand(args []string) int {
+	fs, src := newFlagSet("dump")
+	what := fs.String("what", "dst", "keluaran: dst | pcode")
+	fs.Parse(args)
+
+	stage := compiler.STAGE_CHECK
+	switch *what {
+	case "dst":
+	case "pcode":
+		stage = compiler.STAGE_CODEGEN
+	default:
+		fmt.Fprintf(os.Stderr, "unknown --what value %q\n", *what)
+		return 2
+	}
+
+	res, code := src.compile(fs, stage)
+	if res == nil {
+		return code
+	}
+
+	if printDiagnostics(res) {
+		return 1
+	}
+
+	if *what == "pcode" {
+		fmt.Print(res.Program.String())
+		return 0
+	}
+
+	fmt.Println(res.DST.StringWithSymbols(res.Tab, res.Atab, res.Btab, res.StrTab))
+	fmt.Println()
+
+	fmt.Println("=== Symbol Table (TAB) ===")
+	fmt.Println(res.Tab.String())
+	fmt.Println()
+
+	fmt.Println("=== Array Table (ATAB) ===")
+	fmt.Println(res.Atab.String())
+	fmt.Println()
+
+	fmt.Println("=== Block Table (BTAB) ===")
+	fmt.Println(res.Btab.String())
+	fmt.Println()
+
+	fmt.Println("=== String Table (STRTAB) ===")
+	fmt.Println(res.StrTab.String())
+	return 0
+}
@@ -5,18 +5,19 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"slices"
 
-	iox "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/common"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/compiler"
 	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
-	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/lexer"
-	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/parser"
-	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/semantic"
 )
 
 func main() {
-	rules := flag.String("rules", "config/tokenizer_m3.json", "path ke DFA JSON")
-	in := flag.String("input", "", "path file sumber")
+	rules := flag.String("rules", "", "path ke DFA JSON (default: sesuai --lang)")
+	langName := flag.String("lang", "indo", "bahasa keyword: indo | en")
+	in := flag.String("input", "", "path file sumber, atau - untuk stdin")
+	var defines compiler.Defines
+	flag.Var(&defines, "D", "definisikan simbol untuk {$IFDEF}; boleh diulang atau dipisah koma")
+	var unitPath compiler.UnitPath
+	flag.Var(&unitPath, "unit-path", "direktori tambahan untuk mencari unit; boleh diulang atau dipisah seperti PATH")
 	flag.Parse()
 
 	if *in == "" {
@@ -24,61 +25,46 @@ func main() {
 		os.Exit(2)
 	}
 
-	// Load DFA rules
-	d, err := lexer.LoadJSON(*rules)
+	lang, err := dt.LookupLanguageProfile(*langName)
 	if err != nil {
-		log.Fatal(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
 
-	// Read source file
-	rr, err := iox.NewRuneReaderFromFile(*in)
+	res, err := compiler.CompileFile(*in, compiler.Options{
+		Lang:     lang,
+		Rules:    *rules,
+		Defines:  defines,
+		UnitPath: unitPath,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// Lexical analysis
-	tokens, errs := lexer.New(d, rr).ScanAll()
-
-	for _, e := range errs {
+	for _, e := range res.LexErrors {
 		fmt.Fprintln(os.Stderr, e)
 	}
 
-	if len(errs) > 0 {
+	if len(res.LexErrors) > 0 {
 		os.Exit(1)
 	}
 
-	// Filter out comments
-	tokens = slices.Collect(func(yield func(dt.Token) bool) {
-		for _, token := range tokens {
-			if token.Type != dt.COMMENT {
-				if !yield(token) {
-					return
-				}
-			}
+	if len(res.ParseErrors) > 0 {
+		for _, e := range res.ParseErrors {
+			fmt.Fprintf(os.Stderr, "Parse error: %v\n", e)
 		}
-	})
-
-	// Syntax analysis
-	parseTree, err := parser.New(tokens).Parse()
-
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Parse error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if parseTree == nil {
-		fmt.Fprintln(os.Stderr, "Parse tree is nil")
+	if len(res.SemanticErrors) > 0 {
+		for _, d := range res.SemanticErrors {
+			fmt.Fprintln(os.Stderr, d)
+		}
+		fmt.Fprintf(os.Stderr, "%d semantic error(s)\n", len(res.SemanticErrors))
 		os.Exit(1)
 	}
 
-	// Semantic analysis
-	analyzer := semantic.New(parseTree)
-	tab, atab, btab, strtab, dst, err := analyzer.Analyze()
-
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Semantic error: %v\n", err)
-		os.Exit(1)
-	}
+	tab, atab, btab, strtab, dst := res.Tab, res.Atab, res.Btab, res.StrTab, res.DST
 
 	// Display decorated syntax tree
 	if dst != nil {
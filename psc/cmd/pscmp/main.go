@@ -5,62 +5,93 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"slices"
 
-	iox "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/common"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/compiler"
 	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
-	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/lexer"
 	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/parser"
 )
 
 func main() {
-	rules := flag.String("rules", "config/tokenizer_m3.json", "path ke DFA JSON")
-	in := flag.String("input", "", "path file sumber")
+	rules := flag.String("rules", "", "path ke DFA JSON (default: sesuai --lang)")
+	langName := flag.String("lang", "indo", "bahasa keyword: indo | en")
+	in := flag.String("input", "", "path file sumber, atau - untuk stdin")
+	emit := flag.String("emit", "tree", "keluaran: tree | pcode")
+	maxErrors := flag.Int("max-errors", 20, "jumlah maksimum error sintaks yang dicetak (0 = semua)")
+	var defines compiler.Defines
+	flag.Var(&defines, "D", "definisikan simbol untuk {$IFDEF}; boleh diulang atau dipisah koma")
+	var unitPath compiler.UnitPath
+	flag.Var(&unitPath, "unit-path", "direktori tambahan untuk mencari unit; boleh diulang atau dipisah seperti PATH")
 	flag.Parse()
 
+	if *emit != "tree" && *emit != "pcode" {
+		fmt.Fprintf(os.Stderr, "unknown --emit value %q\n", *emit)
+		os.Exit(2)
+	}
+
 	if *in == "" {
 		fmt.Fprintln(os.Stderr, "missing --input <file>")
 		os.Exit(2)
 	}
 
-	d, err := lexer.LoadJSON(*rules)
+	lang, err := dt.LookupLanguageProfile(*langName)
 	if err != nil {
-		log.Fatal(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
 
-	rr, err := iox.NewRuneReaderFromFile(*in)
-	if err != nil {
-		log.Fatal(err)
+	stage := compiler.STAGE_PARSE
+	if *emit == "pcode" {
+		stage = compiler.STAGE_CODEGEN
 	}
 
-	tokens, errs := lexer.New(d, rr).ScanAll()
+	res, err := compiler.CompileFile(*in, compiler.Options{
+		Lang:      lang,
+		Rules:     *rules,
+		StopAfter: stage,
+		Defines:   defines,
+		UnitPath:  unitPath,
+	})
+	if res == nil {
+		log.Fatal(err)
+	}
 
-	for _, e := range errs {
+	for _, e := range res.LexErrors {
 		fmt.Fprintln(os.Stderr, e)
 	}
 
-	if len(errs) > 0 {
+	if len(res.LexErrors) > 0 {
 		os.Exit(1)
 	}
 
-	tokens = slices.Collect(func(yield func(dt.Token) bool) {
-		for _, token := range tokens {
-			if token.Type != dt.COMMENT {
-				if !yield(token) {
-					return
-				}
+	if *emit == "pcode" {
+		if len(res.ParseErrors) > 0 {
+			parser.PrintErrors(os.Stderr, res.ParseErrors, *maxErrors)
+			os.Exit(1)
+		}
+
+		if len(res.SemanticErrors) > 0 {
+			for _, d := range res.SemanticErrors {
+				fmt.Fprintln(os.Stderr, d)
 			}
+			os.Exit(1)
 		}
-	})
 
-	parseTree, err := parser.New(tokens).Parse()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 
-	if err != nil {
-		fmt.Printf("%v", err)
+		fmt.Print(res.Program.String())
+		return
 	}
 
-	if parseTree != nil {
-		fmt.Println(parseTree.String())
+	parser.PrintErrors(os.Stdout, res.ParseErrors, *maxErrors)
+
+	if res.Tree != nil {
+		fmt.Println(res.Tree.String())
 	}
 
+	if len(res.ParseErrors) > 0 {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/lexer"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/lexer/gen"
+)
+
+const usage = `usage: psdfa <command> [flags]
+
+commands:
+  gen       bangun DFA dari file aturan regex, tulis sebagai JSON
+  check     cek DFA JSON: id state, label output, state tak terjangkau dan mati
+  minimize  minimalkan DFA JSON (Hopcroft)
+
+jalankan 'psdfa <command> -h' untuk flag tiap command
+`
+
+var commands = map[string]func(args []string) int{
+	"gen":      genCommand,
+	"check":    checkCommand,
+	"minimize": minimizeCommand,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "-h" || name == "--help" || name == "help" {
+		fmt.Print(usage)
+		return
+	}
+
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
+	}
+
+	os.Exit(command(os.Args[2:]))
+}
+
+func genCommand(args []string) int {
+	fs := flag.NewFlagSet("psdfa gen", flag.ExitOnError)
+	rulesPath := fs.String("rules", "", "path file aturan (boleh juga argumen posisi)")
+	out := fs.String("out", "", "opsional: file output JSON (default: stdout)")
+	fs.Parse(args)
+
+	path := *rulesPath
+	if path == "" && fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "missing --rules <file>")
+		return 2
+	}
+
+	rules, err := gen.LoadRules(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	d, err := gen.Compile(rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+
+	if err := writeDFA(*out, d.Lexer()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "%d state(s)\n", len(d.Accept))
+	return 0
+}
+
+func checkCommand(args []string) int {
+	fs := flag.NewFlagSet("psdfa check", flag.ExitOnError)
+	rules := fs.String("rules", "", "path DFA JSON (boleh juga argumen posisi)")
+	fs.Parse(args)
+
+	path, d, code := loadDFA(fs, *rules)
+	if d == nil {
+		return code
+	}
+
+	errs := lexer.Validate(d)
+	for _, err := range errs {
+		fmt.Printf("%s: %v\n", path, err)
+	}
+
+	if len(errs) > 0 {
+		fmt.Fprintf(os.Stderr, "%d problem(s)\n", len(errs))
+		return 1
+	}
+	return 0
+}
+
+func minimizeCommand(args []string) int {
+	fs := flag.NewFlagSet("psdfa minimize", flag.ExitOnError)
+	rules := fs.String("rules", "", "path DFA JSON (boleh juga argumen posisi)")
+	out := fs.String("out", "", "opsional: file output JSON (default: stdout)")
+	fs.Parse(args)
+
+	_, d, code := loadDFA(fs, *rules)
+	if d == nil {
+		return code
+	}
+
+	small := lexer.Minimize(d)
+	if err := writeDFA(*out, small); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "%d -> %d state(s)\n", len(d.StateIDs()), small.States)
+	return 0
+}
+
+func loadDFA(fs *flag.FlagSet, path string) (string, *lexer.DFA, int) {
+	if path == "" && fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "missing --rules <file>")
+		return "", nil, 2
+	}
+
+	d, err := lexer.LoadJSON(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return path, nil, 1
+	}
+	return path, d, 0
+}
+
+// writeDFA writes d as JSON to path, or to stdout when path is empty.
+func writeDFA(path string, d *lexer.DFA) error {
+	w := os.Stdout
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := lexer.WriteJSON(bw, d); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
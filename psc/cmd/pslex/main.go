@@ -2,38 +2,96 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
-	iox "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/common"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/compiler"
 	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
 	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/lexer"
 )
 
 func main() {
-	rules := flag.String("rules", "config/tokenizer_m3.json", "path ke DFA JSON")
-	in := flag.String("input", "", "path file sumber")
+	rules := flag.String("rules", "", "path ke DFA JSON (default: sesuai --lang)")
+	langName := flag.String("lang", "indo", "bahasa keyword: indo | en")
+	in := flag.String("input", "", "path file sumber, atau - untuk stdin")
 	out := flag.String("out", "", "opsional: file output token")
+	dumpDFA := flag.String("dump-dfa", "", "cetak DFA sebagai diagram lalu keluar: dot | mermaid")
+	dumpFrom := flag.Int("dump-from", -1, "opsional: hanya state yang terjangkau dari state ini")
+	trace := flag.Bool("trace", false, "cetak jejak DFA per rune ke stderr")
+	traceFormat := flag.String("trace-format", "text", "format jejak: text | json")
+	asJSON := flag.Bool("json", false, "cetak token dan error sebagai JSON")
+	var defines compiler.Defines
+	flag.Var(&defines, "D", "definisikan simbol untuk {$IFDEF}; boleh diulang atau dipisah koma")
 	flag.Parse()
 
+	if *dumpDFA != "" {
+		if err := dumpDiagram(*dumpDFA, *rules, *langName, *dumpFrom); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+
 	if *in == "" {
 		fmt.Fprintln(os.Stderr, "missing --input <file>")
 		os.Exit(2)
 	}
 
-	d, err := lexer.LoadJSON(*rules)
+	lang, err := dt.LookupLanguageProfile(*langName)
 	if err != nil {
-		log.Fatal(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var tracer lexer.Tracer
+	if *trace {
+		switch *traceFormat {
+		case "text":
+			tracer = lexer.NewTextTracer(os.Stderr)
+		case "json":
+			tracer = lexer.NewJSONTracer(os.Stderr)
+		default:
+			fmt.Fprintf(os.Stderr, "unknown --trace-format value %q\n", *traceFormat)
+			os.Exit(2)
+		}
 	}
 
-	rr, err := iox.NewRuneReaderFromFile(*in)
+	res, err := compiler.CompileFile(*in, compiler.Options{
+		Lang:      lang,
+		Rules:     *rules,
+		StopAfter: compiler.STAGE_LEX,
+		Tracer:    tracer,
+		Defines:   defines,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	tokens, errs := lexer.New(d, rr).ScanAll()
+	tokens, errs := res.Tokens, res.LexErrors
+
+	if *asJSON {
+		w := os.Stdout
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if err := WriteJSON(w, tokens, errs); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
 	PrintTokens(tokens)
 
@@ -48,6 +106,41 @@ func main() {
 	}
 }
 
+// dumpDiagram writes the DFA pslex would use to stdout.
+func dumpDiagram(format, rules, langName string, from int) error {
+	write := map[string]func(*lexer.DFA, io.Writer, lexer.DiagramOptions) error{
+		"dot":     lexer.WriteDOT,
+		"mermaid": lexer.WriteMermaid,
+	}[format]
+	if write == nil {
+		return fmt.Errorf("unknown --dump-dfa value %q", format)
+	}
+
+	if rules == "" {
+		lang, err := dt.LookupLanguageProfile(langName)
+		if err != nil {
+			return err
+		}
+		rules = lang.Rules
+	}
+
+	d, err := lexer.LoadJSON(rules)
+	if err != nil {
+		return err
+	}
+
+	opts := lexer.DiagramOptions{Name: strings.TrimSuffix(filepath.Base(rules), filepath.Ext(rules))}
+	if from != -1 {
+		start := lexer.State(from)
+		if !slices.Contains(d.StateIDs(), start) {
+			return fmt.Errorf("unknown --dump-from state %d", from)
+		}
+		opts.From = &start
+	}
+
+	return write(d, os.Stdout, opts)
+}
+
 func PrintTokens(tokens []dt.Token) {
 	for _, t := range tokens {
 		fmt.Printf("%s(%s)\n", t.Type.String(), t.Lexeme)
@@ -60,6 +153,68 @@ func PrintErrorslexe(errs []error) {
 	}
 }
 
+type jsonSpan struct {
+	Offset    int `json:"offset"`
+	Line      int `json:"line"`
+	Col       int `json:"col"`
+	EndOffset int `json:"end_offset"`
+	EndLine   int `json:"end_line"`
+	EndCol    int `json:"end_col"`
+}
+
+func newJSONSpan(s dt.Span) jsonSpan {
+	return jsonSpan{s.Start.Offset, s.Start.Line, s.Start.Col, s.End.Offset, s.End.Line, s.End.Col}
+}
+
+type jsonToken struct {
+	Type   string `json:"type"`
+	Lexeme string `json:"lexeme"`
+	File   string `json:"file,omitempty"`
+	jsonSpan
+}
+
+type jsonError struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Text    string `json:"text,omitempty"`
+	Fix     string `json:"fix,omitempty"`
+	File    string `json:"file,omitempty"`
+	*jsonSpan
+}
+
+// WriteJSON writes tokens and errs as one JSON object. An error that is not
+// a *lexer.LexError, such as a failed read, has kind "io" and no position.
+func WriteJSON(w io.Writer, tokens []dt.Token, errs []error) error {
+	doc := struct {
+		Tokens []jsonToken `json:"tokens"`
+		Errors []jsonError `json:"errors"`
+	}{
+		Tokens: make([]jsonToken, 0, len(tokens)),
+		Errors: make([]jsonError, 0, len(errs)),
+	}
+
+	for _, t := range tokens {
+		doc.Tokens = append(doc.Tokens, jsonToken{t.Type.String(), t.Lexeme, t.File, newJSONSpan(t.Span())})
+	}
+
+	for _, e := range errs {
+		je := jsonError{Kind: "io", Message: e.Error()}
+		if le, ok := e.(*lexer.LexError); ok {
+			span := newJSONSpan(le.Span)
+			je.Kind = strings.ReplaceAll(le.Kind.String(), " ", "_")
+			je.Text = le.Text
+			je.Fix = le.Fix
+			je.File = le.File
+			je.jsonSpan = &span
+		}
+		doc.Errors = append(doc.Errors, je)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
 func WriteTokensAndErrorsToFile(path string, tokens []dt.Token, errs []error) error {
 	f, err := os.Create(path)
 	if err != nil {
@@ -5,17 +5,19 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"slices"
 
-	iox "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/common"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/compiler"
 	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
-	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/lexer"
 	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/parser"
 )
 
 func main() {
-	rules := flag.String("rules", "config/tokenizer_m3.json", "path ke DFA JSON")
-	in := flag.String("input", "", "path file sumber")
+	rules := flag.String("rules", "", "path ke DFA JSON (default: sesuai --lang)")
+	langName := flag.String("lang", "indo", "bahasa keyword: indo | en")
+	in := flag.String("input", "", "path file sumber, atau - untuk stdin")
+	maxErrors := flag.Int("max-errors", 20, "jumlah maksimum error sintaks yang dicetak (0 = semua)")
+	var defines compiler.Defines
+	flag.Var(&defines, "D", "definisikan simbol untuk {$IFDEF}; boleh diulang atau dipisah koma")
 	flag.Parse()
 
 	if *in == "" {
@@ -23,44 +25,37 @@ func main() {
 		os.Exit(2)
 	}
 
-	d, err := lexer.LoadJSON(*rules)
+	lang, err := dt.LookupLanguageProfile(*langName)
 	if err != nil {
-		log.Fatal(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
 
-	rr, err := iox.NewRuneReaderFromFile(*in)
+	res, err := compiler.CompileFile(*in, compiler.Options{
+		Lang:      lang,
+		Rules:     *rules,
+		StopAfter: compiler.STAGE_PARSE,
+		Defines:   defines,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	tokens, errs := lexer.New(d, rr).ScanAll()
-
-	for _, e := range errs {
+	for _, e := range res.LexErrors {
 		fmt.Fprintln(os.Stderr, e)
 	}
 
-	if len(errs) > 0 {
+	if len(res.LexErrors) > 0 {
 		os.Exit(1)
 	}
 
-	tokens = slices.Collect(func(yield func(dt.Token) bool) {
-		for _, token := range tokens {
-			if token.Type != dt.COMMENT {
-				if !yield(token) {
-					return
-				}
-			}
-		}
-	})
+	parser.PrintErrors(os.Stdout, res.ParseErrors, *maxErrors)
 
-	parseTree, err := parser.New(tokens).Parse()
-
-	if err != nil {
-		fmt.Printf("%v", err)
+	if res.Tree != nil {
+		fmt.Println(res.Tree.String())
 	}
 
-	if parseTree != nil {
-		fmt.Println(parseTree.String())
+	if len(res.ParseErrors) > 0 {
+		os.Exit(1)
 	}
-
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/compiler"
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/vm"
+)
+
+func main() {
+	rules := flag.String("rules", "", "path ke DFA JSON (default: sesuai --lang)")
+	langName := flag.String("lang", "indo", "bahasa keyword: indo | en")
+	in := flag.String("input", "", "path file sumber, atau - untuk stdin")
+	engine := flag.String("engine", "interp", "mesin eksekusi: interp | vm")
+	stackSize := flag.Int("stack", vm.DefaultStackSize, "ukuran stack VM dalam sel")
+	var defines compiler.Defines
+	flag.Var(&defines, "D", "definisikan simbol untuk {$IFDEF}; boleh diulang atau dipisah koma")
+	var unitPath compiler.UnitPath
+	flag.Var(&unitPath, "unit-path", "direktori tambahan untuk mencari unit; boleh diulang atau dipisah seperti PATH")
+	flag.Parse()
+
+	if *engine != "interp" && *engine != "vm" {
+		fmt.Fprintf(os.Stderr, "unknown --engine value %q\n", *engine)
+		os.Exit(2)
+	}
+
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "missing --input <file>")
+		os.Exit(2)
+	}
+
+	lang, err := dt.LookupLanguageProfile(*langName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	res, err := compiler.CompileFile(*in, compiler.Options{
+		Lang:     lang,
+		Rules:    *rules,
+		Defines:  defines,
+		UnitPath: unitPath,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, e := range res.LexErrors {
+		fmt.Fprintln(os.Stderr, e)
+	}
+
+	if len(res.LexErrors) > 0 {
+		os.Exit(1)
+	}
+
+	if len(res.ParseErrors) > 0 {
+		for _, e := range res.ParseErrors {
+			fmt.Fprintf(os.Stderr, "Parse error: %v\n", e)
+		}
+		os.Exit(1)
+	}
+
+	if len(res.SemanticErrors) > 0 {
+		for _, d := range res.SemanticErrors {
+			fmt.Fprintln(os.Stderr, d)
+		}
+		os.Exit(1)
+	}
+
+	err = res.Run(os.Stdout, compiler.RunOptions{
+		Engine:    compiler.Engine(*engine),
+		StackSize: *stackSize,
+		In:        os.Stdin,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,211 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+type Generator struct {
+	tab    dt.Tab
+	atab   dt.Atab
+	btab   dt.Btab
+	strtab dt.StrTab
+	dst    *dt.DecoratedSyntaxTree
+
+	program *Program
+	blocks  map[int]int
+	reals   map[float64]int
+	line    int
+}
+
+func New(tab dt.Tab, atab dt.Atab, btab dt.Btab, strtab dt.StrTab, dst *dt.DecoratedSyntaxTree) *Generator {
+	return &Generator{
+		tab:    tab,
+		atab:   atab,
+		btab:   btab,
+		strtab: strtab,
+		dst:    dst,
+		blocks: make(map[int]int),
+		reals:  make(map[float64]int),
+	}
+}
+
+func (g *Generator) Generate() (*Program, error) {
+	if g.dst == nil || g.dst.SelfType != dt.DST_PROGRAM {
+		return nil, errors.New("expected program")
+	}
+
+	g.program = &Program{
+		Strings: make([]string, len(g.strtab)),
+	}
+
+	for i, s := range g.strtab {
+		g.program.Strings[i] = s.String
+	}
+
+	for _, a := range g.atab {
+		g.program.Arrays = append(g.program.Arrays, Array{
+			Low:         a.LowBound,
+			High:        a.HighBound,
+			ElementSize: a.ElementSize,
+		})
+	}
+
+	g.program.Blocks = append(g.program.Blocks, Block{
+		Name:    g.tab[g.dst.Data].Identifier,
+		Level:   0,
+		VarSize: g.declaredSize(g.dst),
+	})
+	g.blocks[g.dst.Data] = 0
+	g.registerBlocks(g.dst)
+
+	if err := g.genBlock(g.dst); err != nil {
+		return nil, err
+	}
+
+	return g.program, nil
+}
+
+// registerBlocks gives every subprogram its block number up front, so MKS can
+// refer to blocks whose code has not been generated yet.
+func (g *Generator) registerBlocks(node *dt.DecoratedSyntaxTree) {
+	for i := range node.Children {
+		child := &node.Children[i]
+
+		if child.SelfType != dt.DST_FUNCTION && child.SelfType != dt.DST_PROCEDURE {
+			continue
+		}
+
+		entry := g.tab[child.Data]
+		block := g.btab[entry.Data]
+
+		g.blocks[child.Data] = len(g.program.Blocks)
+		g.program.Blocks = append(g.program.Blocks, Block{
+			Name:      entry.Identifier,
+			Level:     entry.Level + 1,
+			ParamSize: block.ParamSize,
+			VarSize:   block.VariableSize,
+		})
+
+		g.registerBlocks(child)
+	}
+}
+
+// declaredSize is the data area needed by the variables declared directly
+// under node. The program has no btab entry, so its size is derived from the
+// offsets the analyzer assigned.
+func (g *Generator) declaredSize(node *dt.DecoratedSyntaxTree) int {
+	size := 0
+
+	for _, child := range node.Children {
+		if child.SelfType != dt.DST_VARIABLE_DECLARATIONS {
+			continue
+		}
+
+		for _, decl := range child.Children {
+			entry := g.tab[decl.Data]
+			size = max(size, entry.Data+g.slotSize(decl.Data))
+		}
+	}
+
+	return size
+}
+
+func (g *Generator) genBlock(node *dt.DecoratedSyntaxTree) error {
+	for i := range node.Children {
+		child := &node.Children[i]
+
+		if child.SelfType == dt.DST_FUNCTION || child.SelfType == dt.DST_PROCEDURE {
+			if err := g.genBlock(child); err != nil {
+				return err
+			}
+		}
+	}
+
+	blockIndex := g.blocks[node.Data]
+	g.program.Blocks[blockIndex].Entry = len(g.program.Code)
+
+	if len(node.Children) > 0 {
+		if err := g.genStatement(&node.Children[len(node.Children)-1]); err != nil {
+			return err
+		}
+	}
+
+	switch node.SelfType {
+	case dt.DST_FUNCTION:
+		g.emit(EXF, 0, 0, g.tab[node.Data].Identifier)
+	case dt.DST_PROCEDURE:
+		g.emit(EXP, 0, 0, g.tab[node.Data].Identifier)
+	default:
+		g.emit(HLT, 0, 0, "")
+	}
+
+	return nil
+}
+
+func (g *Generator) emit(op Opcode, x int, y int, comment string) int {
+	g.program.Code = append(g.program.Code, Instruction{Op: op, X: x, Y: y, Line: g.line, Comment: comment})
+	return len(g.program.Code) - 1
+}
+
+func (g *Generator) patch(at int) {
+	g.program.Code[at].Y = len(g.program.Code)
+}
+
+func (g *Generator) realIndex(v float64) int {
+	if i, ok := g.reals[v]; ok {
+		return i
+	}
+
+	i := len(g.program.Reals)
+	g.program.Reals = append(g.program.Reals, v)
+	g.reals[v] = i
+	return i
+}
+
+func (g *Generator) resolveType(typ dt.TabEntryType, ref int) (dt.TabEntryType, int) {
+	for typ == dt.TAB_ENTRY_ALIAS {
+		typ, ref = g.tab[ref].Type, g.tab[ref].Reference
+	}
+	return typ, ref
+}
+
+// sizeOf mirrors the sizes the analyzer used when it assigned offsets, so
+// every cell address can be taken straight from tab[].Data.
+func (g *Generator) sizeOf(typ dt.TabEntryType, ref int) int {
+	typ, ref = g.resolveType(typ, ref)
+
+	switch typ {
+	case dt.TAB_ENTRY_INTEGER, dt.TAB_ENTRY_REAL:
+		return strconv.IntSize / 8
+	case dt.TAB_ENTRY_BOOLEAN, dt.TAB_ENTRY_CHAR:
+		return 1
+	case dt.TAB_ENTRY_ARRAY:
+		return g.atab[ref].TotalSize
+	case dt.TAB_ENTRY_RECORD:
+		return g.btab[ref].VariableSize
+	default:
+		return 0
+	}
+}
+
+func (g *Generator) slotSize(tabIndex int) int {
+	entry := g.tab[tabIndex]
+
+	if entry.Object == dt.TAB_ENTRY_PARAM && !entry.Normal {
+		return strconv.IntSize
+	}
+
+	return g.sizeOf(entry.Type, entry.Reference)
+}
+
+func isBlockType(typ dt.TabEntryType) bool {
+	return typ == dt.TAB_ENTRY_ARRAY || typ == dt.TAB_ENTRY_RECORD
+}
+
+func (g *Generator) unsupported(node *dt.DecoratedSyntaxTree) error {
+	return fmt.Errorf("code generation for %s is not supported", node.SelfType)
+}
@@ -0,0 +1,75 @@
+package codegen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/codegen"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/compiler"
+)
+
+const rules = "../../config/tokenizer_m3.json"
+
+// generate analyzes src and generates its P-code.
+func generate(t *testing.T, src string) (*codegen.Program, error) {
+	t.Helper()
+
+	res, err := compiler.Compile([]byte(src), compiler.Options{Rules: rules, Path: "test.pas", StopAfter: compiler.STAGE_CODEGEN})
+	if err != nil {
+		return nil, err
+	}
+	if res.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %v", res.Diagnostics())
+	}
+	return res.Program, nil
+}
+
+func TestStringComparison(t *testing.T) {
+	for _, op := range []string{"=", "<>", "<", "<=", ">", ">="} {
+		program, err := generate(t, `program Banding;
+variabel s, u: string;
+mulai
+  s := 'hi';
+  u := s;
+  jika s `+op+` 'hi' maka write('ya', '..');
+  jika s `+op+` u maka write('ya', '..')
+selesai.
+`)
+		if err != nil {
+			t.Errorf("%s: %v", op, err)
+			continue
+		}
+
+		n := 0
+		for _, ins := range program.Code {
+			if ins.Op == codegen.CMB {
+				n++
+			}
+		}
+		if n != 2 {
+			t.Errorf("%s: generated %d CMB instructions, want 2", op, n)
+		}
+	}
+}
+
+func TestListingHasNoTrailingSpaces(t *testing.T) {
+	program, err := generate(t, `program L;
+variabel x: integer;
+prosedur P(a: integer);
+mulai
+  x := a
+selesai;
+mulai
+  P(1)
+selesai.
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, line := range strings.Split(program.String(), "\n") {
+		if strings.TrimRight(line, " ") != line {
+			t.Errorf("line %d of the listing ends in spaces: %q", i+1, line)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package codegen
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// genAddress pushes the address of an assignable location.
+func (g *Generator) genAddress(node *dt.DecoratedSyntaxTree) error {
+	switch node.SelfType {
+	case dt.DST_VARIABLE:
+		entry := g.tab[node.Data]
+
+		if entry.Object == dt.TAB_ENTRY_PARAM && !entry.Normal {
+			g.emit(LOD, entry.Level, g.frameOffset(node.Data), entry.Identifier)
+		} else {
+			g.emit(LDA, entry.Level, g.frameOffset(node.Data), entry.Identifier)
+		}
+		return nil
+
+	case dt.DST_RECORD_FIELD:
+		if len(node.Children) == 0 {
+			return fmt.Errorf("field '%s' accessed without a record", g.tab[node.Data].Identifier)
+		}
+
+		if err := g.genAddress(&node.Children[0]); err != nil {
+			return err
+		}
+
+		g.emit(FLD, 0, g.tab[node.Data].Data, g.tab[node.Data].Identifier)
+		return nil
+
+	case dt.DST_ARRAY_ELEMENT:
+		if len(node.Children) != 2 {
+			return fmt.Errorf("malformed array access")
+		}
+
+		if err := g.genAddress(&node.Children[0]); err != nil {
+			return err
+		}
+
+		if err := g.genValue(&node.Children[1]); err != nil {
+			return err
+		}
+
+		g.emit(IDX, 0, node.Data, "")
+		return nil
+
+	default:
+		return fmt.Errorf("%s is not assignable", node.SelfType)
+	}
+}
+
+// genValue pushes the value of a scalar expression.
+func (g *Generator) genValue(node *dt.DecoratedSyntaxTree) error {
+	switch node.SelfType {
+	case dt.DST_INT_LITERAL, dt.DST_BOOL_LITERAL, dt.DST_CHAR_LITERAL:
+		g.emit(LDC, 0, node.Data, "")
+		return nil
+
+	case dt.DST_REAL_LITERAL:
+		g.emit(LDR, 0, g.realIndex(math.Float64frombits(uint64(node.Data))), "")
+		return nil
+
+	case dt.DST_CONST:
+		entry := g.tab[node.Data]
+		typ, _ := g.resolveType(entry.Type, entry.Reference)
+
+		switch typ {
+		case dt.TAB_ENTRY_REAL:
+			g.emit(LDR, 0, g.realIndex(math.Float64frombits(uint64(entry.Data))), entry.Identifier)
+		case dt.TAB_ENTRY_INTEGER, dt.TAB_ENTRY_BOOLEAN, dt.TAB_ENTRY_CHAR:
+			g.emit(LDC, 0, entry.Data, entry.Identifier)
+		default:
+			return fmt.Errorf("constant '%s' is not a scalar", entry.Identifier)
+		}
+		return nil
+
+	case dt.DST_VARIABLE:
+		entry := g.tab[node.Data]
+
+		if typ, _ := g.resolveType(entry.Type, entry.Reference); isBlockType(typ) {
+			return fmt.Errorf("'%s' is not a scalar", entry.Identifier)
+		}
+
+		if entry.Object == dt.TAB_ENTRY_PARAM && !entry.Normal {
+			g.emit(LDI, entry.Level, g.frameOffset(node.Data), entry.Identifier)
+		} else {
+			g.emit(LOD, entry.Level, g.frameOffset(node.Data), entry.Identifier)
+		}
+		return nil
+
+	case dt.DST_RECORD_FIELD, dt.DST_ARRAY_ELEMENT:
+		if err := g.genAddress(node); err != nil {
+			return err
+		}
+		g.emit(IND, 0, 0, "")
+		return nil
+
+	case dt.DST_FUNCTION_CALL:
+		return g.genCall(node)
+
+	case dt.DST_BUILTIN_CALL:
+		return g.genBuiltin(node)
+
+	case dt.DST_CAST_OPERATOR:
+		if err := g.genValue(&node.Children[0]); err != nil {
+			return err
+		}
+		return g.convert(&node.Children[0], dt.TabEntryType(node.Data))
+
+	case dt.DST_NEG_OPERATOR:
+		if err := g.genValue(&node.Children[0]); err != nil {
+			return err
+		}
+
+		typ, _, err := g.typeOf(&node.Children[0])
+		if err != nil {
+			return err
+		}
+
+		if typ == dt.TAB_ENTRY_REAL {
+			g.emit(MUS, 1, 0, "")
+		} else {
+			g.emit(MUS, 0, 0, "")
+		}
+		return nil
+
+	case dt.DST_NOT_OPERATOR:
+		if err := g.genValue(&node.Children[0]); err != nil {
+			return err
+		}
+		g.emit(NOT, 0, 0, "")
+		return nil
+
+	case dt.DST_ADD_OPERATOR, dt.DST_SUB_OPERATOR, dt.DST_MUL_OPERATOR,
+		dt.DST_DIV_OPERATOR, dt.DST_MOD_OPERATOR, dt.DST_AND_OPERATOR, dt.DST_OR_OPERATOR,
+		dt.DST_EQ_OPERATOR, dt.DST_NE_OPERATOR, dt.DST_GT_OPERATOR,
+		dt.DST_LT_OPERATOR, dt.DST_GE_OPERATOR, dt.DST_LE_OPERATOR:
+		return g.genBinary(node)
+
+	default:
+		return fmt.Errorf("cannot evaluate %s", node.SelfType)
+	}
+}
+
+func (g *Generator) genBinary(node *dt.DecoratedSyntaxTree) error {
+	lhs := &node.Children[0]
+	rhs := &node.Children[1]
+
+	ltyp, lref, err := g.typeOf(lhs)
+	if err != nil {
+		return err
+	}
+
+	rtyp, rref, err := g.typeOf(rhs)
+	if err != nil {
+		return err
+	}
+
+	if isBlockType(ltyp) || isBlockType(rtyp) {
+		if isRelational(node.SelfType) && g.isString(ltyp, lref) && g.isString(rtyp, rref) {
+			return g.genStringCompare(node)
+		}
+		return fmt.Errorf("%s cannot be applied to %s and %s", node.SelfType, ltyp, rtyp)
+	}
+
+	isReal := ltyp == dt.TAB_ENTRY_REAL || rtyp == dt.TAB_ENTRY_REAL
+
+	if err := g.genValue(lhs); err != nil {
+		return err
+	}
+	if isReal && ltyp == dt.TAB_ENTRY_INTEGER {
+		g.emit(FLT, 0, 0, "")
+	}
+
+	if err := g.genValue(rhs); err != nil {
+		return err
+	}
+	if isReal && rtyp == dt.TAB_ENTRY_INTEGER {
+		g.emit(FLT, 0, 0, "")
+	}
+
+	switch node.SelfType {
+	case dt.DST_AND_OPERATOR:
+		g.emit(AND, 0, 0, "")
+	case dt.DST_OR_OPERATOR:
+		g.emit(ORR, 0, 0, "")
+	case dt.DST_MOD_OPERATOR:
+		if isReal {
+			return fmt.Errorf("mod cannot be applied to real operands")
+		}
+		g.emit(MOD, 0, 0, "")
+	default:
+		op := binaryOps[node.SelfType]
+		if isReal {
+			g.emit(op[1], 0, 0, "")
+		} else {
+			g.emit(op[0], 0, 0, "")
+		}
+	}
+
+	return nil
+}
+
+// genStringCompare compares two character blocks the way the interpreter
+// compares strings. CMB leaves -1, 0 or 1, which the integer comparison for
+// the operator then tests against 0.
+func (g *Generator) genStringCompare(node *dt.DecoratedSyntaxTree) error {
+	lsize, err := g.genString(&node.Children[0])
+	if err != nil {
+		return err
+	}
+
+	rsize, err := g.genString(&node.Children[1])
+	if err != nil {
+		return err
+	}
+
+	g.emit(CMB, lsize, rsize, "")
+	g.emit(LDC, 0, 0, "")
+	g.emit(binaryOps[node.SelfType][0], 0, 0, "")
+	return nil
+}
+
+// genString pushes the characters of a string as a block and returns its
+// size: a string constant takes as many cells as it has characters, any
+// other string the cells stored at its address.
+func (g *Generator) genString(node *dt.DecoratedSyntaxTree) (int, error) {
+	if idx, ok := g.stringConstant(node); ok {
+		size := utf8.RuneCountInString(g.program.Strings[idx])
+		g.emit(LDS, size, idx, "")
+		return size, nil
+	}
+
+	typ, ref, err := g.typeOf(node)
+	if err != nil {
+		return 0, err
+	}
+
+	size := g.sizeOf(typ, ref)
+
+	if err := g.genAddress(node); err != nil {
+		return 0, err
+	}
+
+	g.emit(LDB, 0, size, "")
+	return size, nil
+}
+
+func isRelational(op dt.DSTNodeType) bool {
+	switch op {
+	case dt.DST_EQ_OPERATOR, dt.DST_NE_OPERATOR, dt.DST_GT_OPERATOR,
+		dt.DST_LT_OPERATOR, dt.DST_GE_OPERATOR, dt.DST_LE_OPERATOR:
+		return true
+	default:
+		return false
+	}
+}
+
+var binaryOps = map[dt.DSTNodeType][2]Opcode{
+	dt.DST_ADD_OPERATOR: {ADD, ADR},
+	dt.DST_SUB_OPERATOR: {SUB, SUR},
+	dt.DST_MUL_OPERATOR: {MUL, MUR},
+	dt.DST_DIV_OPERATOR: {DIV, DIR},
+	dt.DST_EQ_OPERATOR:  {EQL, EQR},
+	dt.DST_NE_OPERATOR:  {NEQ, NER},
+	dt.DST_LT_OPERATOR:  {LSS, LSR},
+	dt.DST_LE_OPERATOR:  {LEQ, LER},
+	dt.DST_GT_OPERATOR:  {GRT, GTR},
+	dt.DST_GE_OPERATOR:  {GEQ, GER},
+}
+
+// convert turns the integer value of node, already on the stack, into a real
+// when it is stored into or passed as a real.
+func (g *Generator) convert(node *dt.DecoratedSyntaxTree, to dt.TabEntryType) error {
+	if to != dt.TAB_ENTRY_REAL {
+		return nil
+	}
+
+	typ, _, err := g.typeOf(node)
+	if err != nil {
+		return err
+	}
+
+	if typ == dt.TAB_ENTRY_INTEGER {
+		g.emit(FLT, 0, 0, "")
+	}
+
+	return nil
+}
+
+// typeOf reconstructs the static type of an expression. The DST only keeps
+// the type on a few nodes, so the rest is derived the same way the analyzer
+// derived it.
+func (g *Generator) typeOf(node *dt.DecoratedSyntaxTree) (dt.TabEntryType, int, error) {
+	switch node.SelfType {
+	case dt.DST_INT_LITERAL:
+		return dt.TAB_ENTRY_INTEGER, 0, nil
+	case dt.DST_REAL_LITERAL:
+		return dt.TAB_ENTRY_REAL, 0, nil
+	case dt.DST_BOOL_LITERAL:
+		return dt.TAB_ENTRY_BOOLEAN, 0, nil
+	case dt.DST_CHAR_LITERAL:
+		return dt.TAB_ENTRY_CHAR, 0, nil
+	case dt.DST_STR_LITERAL:
+		return dt.TAB_ENTRY_ARRAY, 0, nil
+
+	case dt.DST_CONST, dt.DST_VARIABLE, dt.DST_RECORD_FIELD, dt.DST_FUNCTION_CALL:
+		entry := g.tab[node.Data]
+		typ, ref := g.resolveType(entry.Type, entry.Reference)
+		return typ, ref, nil
+
+	case dt.DST_ARRAY_ELEMENT:
+		entry := g.atab[node.Data]
+		typ, ref := g.resolveType(entry.ElementType, entry.ElementReference)
+		return typ, ref, nil
+
+	case dt.DST_BUILTIN_CALL:
+		switch dt.Builtin(node.Data) {
+		case dt.BUILTIN_ABS, dt.BUILTIN_SQR, dt.BUILTIN_SUCC, dt.BUILTIN_PRED:
+			return g.typeOf(&node.Children[0])
+		case dt.BUILTIN_ODD:
+			return dt.TAB_ENTRY_BOOLEAN, 0, nil
+		case dt.BUILTIN_ORD, dt.BUILTIN_TRUNC, dt.BUILTIN_ROUND:
+			return dt.TAB_ENTRY_INTEGER, 0, nil
+		case dt.BUILTIN_CHR:
+			return dt.TAB_ENTRY_CHAR, 0, nil
+		case dt.BUILTIN_SQRT, dt.BUILTIN_SIN, dt.BUILTIN_COS, dt.BUILTIN_EXP, dt.BUILTIN_LN:
+			return dt.TAB_ENTRY_REAL, 0, nil
+		}
+		return dt.TAB_ENTRY_NONE, 0, nil
+
+	case dt.DST_CAST_OPERATOR:
+		typ, _, err := g.typeOf(&node.Children[0])
+		if err != nil {
+			return 0, 0, err
+		}
+		if typ == dt.TAB_ENTRY_INTEGER {
+			return dt.TabEntryType(node.Data), 0, nil
+		}
+		return typ, 0, nil
+
+	case dt.DST_NEG_OPERATOR:
+		return g.typeOf(&node.Children[0])
+
+	case dt.DST_NOT_OPERATOR, dt.DST_AND_OPERATOR, dt.DST_OR_OPERATOR,
+		dt.DST_EQ_OPERATOR, dt.DST_NE_OPERATOR, dt.DST_GT_OPERATOR,
+		dt.DST_LT_OPERATOR, dt.DST_GE_OPERATOR, dt.DST_LE_OPERATOR:
+		return dt.TAB_ENTRY_BOOLEAN, 0, nil
+
+	case dt.DST_ADD_OPERATOR, dt.DST_SUB_OPERATOR, dt.DST_MUL_OPERATOR,
+		dt.DST_DIV_OPERATOR, dt.DST_MOD_OPERATOR:
+		ltyp, _, err := g.typeOf(&node.Children[0])
+		if err != nil {
+			return 0, 0, err
+		}
+
+		rtyp, _, err := g.typeOf(&node.Children[1])
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if ltyp == dt.TAB_ENTRY_REAL || rtyp == dt.TAB_ENTRY_REAL {
+			return dt.TAB_ENTRY_REAL, 0, nil
+		}
+		return dt.TAB_ENTRY_INTEGER, 0, nil
+
+	default:
+		return 0, 0, fmt.Errorf("cannot determine the type of %s", node.SelfType)
+	}
+}
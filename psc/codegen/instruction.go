@@ -0,0 +1,158 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Opcode int
+
+// The instruction set follows Wirth's Pascal-S stack machine. s is the
+// runtime stack, t its top, b the base of the current frame and display[l]
+// the base of the innermost active frame at level l.
+const (
+	LDA Opcode = iota // push display[x]+y
+	LOD               // push s[display[x]+y]
+	LDI               // push s[s[display[x]+y]]
+	IND               // s[t] := s[s[t]]
+	FLD               // s[t] := s[t]+y, record field offset
+	INT               // t := t+y
+	LDC               // push y
+	LDR               // push reals[y]
+	LDS               // push strings[y] as a block of x cells, padded with 0
+	LDB               // pop address, push the y cells stored there
+	STO               // s[s[t-1]] := s[t], pop both
+	STB               // pop a block of y cells, pop address, store the block
+	CPB               // copy y cells from address s[t] to address s[t-1], pop both
+	CMB               // pop blocks of y and x cells, push -1, 0 or 1 as the x block compares to the y block as a string
+	IDX               // s[t-1] := s[t-1]+(s[t]-low)*elsize using atab[y], pop index
+	JMP               // pc := y
+	JPC               // pop, jump to y if false
+	SWT               // pop selector, search the CAS table at y
+	CAS               // case table entry for labels x..y, followed by a JMP to the arm
+	F1U               // for-up entry, stack holds counter address, initial and final value
+	F2U               // for-up continuation
+	F1D               // for-down entry
+	F2D               // for-down continuation
+	MKS               // push a mark stack for blocks[y]
+	CAL               // call, y is the size of the parameter area
+	EXP               // exit procedure
+	EXF               // exit function, leaves the result on top
+	FLT               // s[t] := float(s[t])
+	NOT
+	MUS // negate, x = 1 for real
+	EQL
+	NEQ
+	LSS
+	LEQ
+	GRT
+	GEQ
+	EQR
+	NER
+	LSR
+	LER
+	GTR
+	GER
+	ORR
+	AND
+	ADD
+	SUB
+	MUL
+	DIV
+	MOD
+	ADR
+	SUR
+	MUR
+	DIR
+	SFN // s[t] := builtin x of s[t], y = 1 when the argument is real
+	RED // pop address, read a value of type x into it
+	RDL // skip the rest of the input line
+	WRS // write strings[y]
+	WRW // pop and write a value of type x
+	WRB // pop address and write the character block of y cells stored there
+	WRL // write a newline
+	HLT
+)
+
+func (o Opcode) String() string {
+	names := [...]string{
+		"LDA", "LOD", "LDI", "IND", "FLD", "INT", "LDC", "LDR", "LDS", "LDB",
+		"STO", "STB", "CPB", "CMB", "IDX", "JMP", "JPC", "SWT", "CAS", "F1U", "F2U", "F1D", "F2D",
+		"MKS", "CAL", "EXP", "EXF", "FLT", "NOT", "MUS",
+		"EQL", "NEQ", "LSS", "LEQ", "GRT", "GEQ",
+		"EQR", "NER", "LSR", "LER", "GTR", "GER",
+		"ORR", "AND", "ADD", "SUB", "MUL", "DIV", "MOD", "ADR", "SUR", "MUR", "DIR",
+		"SFN", "RED", "RDL", "WRS", "WRW", "WRB", "WRL", "HLT",
+	}
+	if int(o) < 0 || int(o) >= len(names) {
+		return "???"
+	}
+	return names[o]
+}
+
+type Instruction struct {
+	Op      Opcode
+	X       int
+	Y       int
+	Line    int
+	Comment string
+}
+
+// FrameHeader is the size of the mark stack at the start of every frame:
+// function result, return address, static link, dynamic link and block index.
+const FrameHeader = 5
+
+type Block struct {
+	Name      string
+	Level     int
+	Entry     int
+	ParamSize int
+	VarSize   int
+}
+
+// Array carries the part of an atab entry IDX needs at run time.
+type Array struct {
+	Low         int
+	High        int
+	ElementSize int
+}
+
+type Program struct {
+	Code    []Instruction
+	Blocks  []Block
+	Arrays  []Array
+	Reals   []float64
+	Strings []string
+}
+
+func (p *Program) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("Blk  Name             Level  Entry  ParamSize  VarSize\n")
+	sb.WriteString("---- ---------------- ------ ------ ---------- --------\n")
+
+	for i, b := range p.Blocks {
+		name := b.Name
+		if len(name) > 16 {
+			name = name[:13] + "..."
+		}
+		line := fmt.Sprintf("%-4d %-16s %-6d %-6d %-10d %-8d", i, name, b.Level, b.Entry, b.ParamSize, b.VarSize)
+		sb.WriteString(strings.TrimRight(line, " "))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString("   PC Line  Op      X, Y\n")
+	sb.WriteString("----- ----  ---- ------------\n")
+
+	for i, ins := range p.Code {
+		line := fmt.Sprintf("%5d %4d  %-4s %4d, %-6d", i, ins.Line, ins.Op, ins.X, ins.Y)
+		if ins.Comment != "" {
+			line += " ; " + ins.Comment
+		}
+		sb.WriteString(strings.TrimRight(line, " "))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,449 @@
+package codegen
+
+import (
+	"fmt"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+func (g *Generator) genStatement(node *dt.DecoratedSyntaxTree) error {
+	if node.Line != 0 {
+		defer func(line int) { g.line = line }(g.line)
+		g.line = node.Line
+	}
+
+	switch node.SelfType {
+	case dt.DST_BLOCK:
+		for i := range node.Children {
+			if err := g.genStatement(&node.Children[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case dt.DST_ASSIGNMENT_OPERATOR:
+		return g.genAssignment(node)
+	case dt.DST_IF_BLOCK:
+		return g.genIf(node)
+	case dt.DST_WHILE_BLOCK:
+		return g.genWhile(node)
+	case dt.DST_REPEAT_BLOCK:
+		return g.genRepeat(node)
+	case dt.DST_FOR_BLOCK:
+		return g.genFor(node)
+	case dt.DST_CASE_BLOCK:
+		return g.genCase(node)
+	case dt.DST_PROCEDURE_CALL, dt.DST_FUNCTION_CALL:
+		if err := g.genCall(node); err != nil {
+			return err
+		}
+
+		if _, ok := g.blocks[node.Data]; ok && g.tab[node.Data].Object == dt.TAB_ENTRY_FUNC {
+			g.emit(INT, 0, -1, "discard result")
+		}
+		return nil
+	case dt.DST_BUILTIN_CALL:
+		return g.genBuiltin(node)
+	default:
+		return g.unsupported(node)
+	}
+}
+
+func (g *Generator) genAssignment(node *dt.DecoratedSyntaxTree) error {
+	target := &node.Children[0]
+	value := &node.Children[1]
+
+	typ, ref, err := g.typeOf(target)
+	if err != nil {
+		return err
+	}
+
+	if err := g.genAddress(target); err != nil {
+		return err
+	}
+
+	if isBlockType(typ) {
+		size := g.sizeOf(typ, ref)
+
+		if idx, ok := g.stringConstant(value); ok {
+			g.emit(LDS, size, idx, "")
+			g.emit(STB, 0, size, "")
+			return nil
+		}
+
+		if err := g.genAddress(value); err != nil {
+			return err
+		}
+
+		g.emit(CPB, 0, size, "")
+		return nil
+	}
+
+	if err := g.genValue(value); err != nil {
+		return err
+	}
+
+	if err := g.convert(value, typ); err != nil {
+		return err
+	}
+
+	g.emit(STO, 0, 0, "")
+	return nil
+}
+
+func (g *Generator) genIf(node *dt.DecoratedSyntaxTree) error {
+	if err := g.genValue(&node.Children[0]); err != nil {
+		return err
+	}
+
+	jumpElse := g.emit(JPC, 0, 0, "")
+
+	if err := g.genStatement(&node.Children[1]); err != nil {
+		return err
+	}
+
+	if len(node.Children) <= 2 {
+		g.patch(jumpElse)
+		return nil
+	}
+
+	jumpEnd := g.emit(JMP, 0, 0, "")
+	g.patch(jumpElse)
+
+	if err := g.genStatement(&node.Children[2]); err != nil {
+		return err
+	}
+
+	g.patch(jumpEnd)
+	return nil
+}
+
+func (g *Generator) genWhile(node *dt.DecoratedSyntaxTree) error {
+	loop := len(g.program.Code)
+
+	if err := g.genValue(&node.Children[0]); err != nil {
+		return err
+	}
+
+	exit := g.emit(JPC, 0, 0, "")
+
+	if err := g.genStatement(&node.Children[1]); err != nil {
+		return err
+	}
+
+	g.emit(JMP, 0, loop, "")
+	g.patch(exit)
+	return nil
+}
+
+func (g *Generator) genRepeat(node *dt.DecoratedSyntaxTree) error {
+	loop := len(g.program.Code)
+
+	if err := g.genStatement(&node.Children[0]); err != nil {
+		return err
+	}
+
+	if err := g.genValue(&node.Children[1]); err != nil {
+		return err
+	}
+
+	g.emit(JPC, 0, loop, "")
+	return nil
+}
+
+// genCase emits the arms first and the table SWT searches after them. The
+// table ends with a jump to the else branch, or past the statement when there
+// is none.
+func (g *Generator) genCase(node *dt.DecoratedSyntaxTree) error {
+	if err := g.genValue(&node.Children[0]); err != nil {
+		return err
+	}
+
+	swt := g.emit(SWT, 0, 0, "")
+
+	var exits []int
+	var entries []int
+	elseEntry := -1
+
+	for i := 1; i < len(node.Children); i++ {
+		child := &node.Children[i]
+
+		if child.Property == dt.DST_ELSE {
+			elseEntry = len(g.program.Code)
+		} else {
+			entries = append(entries, len(g.program.Code))
+			child = &child.Children[len(child.Children)-1]
+		}
+
+		if err := g.genStatement(child); err != nil {
+			return err
+		}
+		exits = append(exits, g.emit(JMP, 0, 0, ""))
+	}
+
+	g.patch(swt)
+
+	arm := 0
+	for _, child := range node.Children[1:] {
+		if child.SelfType != dt.DST_CASE_ARM {
+			continue
+		}
+
+		for _, label := range child.Children[:len(child.Children)-1] {
+			low, high := label.Data, label.Data
+			if label.SelfType == dt.DST_CASE_RANGE {
+				low, high = label.Children[0].Data, label.Children[1].Data
+			}
+
+			g.emit(CAS, low, high, "")
+			g.emit(JMP, 0, entries[arm], "")
+		}
+		arm++
+	}
+
+	if elseEntry >= 0 {
+		g.emit(JMP, 0, elseEntry, "else")
+	} else {
+		exits = append(exits, g.emit(JMP, 0, 0, ""))
+	}
+
+	for _, exit := range exits {
+		g.patch(exit)
+	}
+	return nil
+}
+
+// genFor leaves the counter address and both bounds on the stack for the
+// whole loop. F1x/F2x pop them once the loop is done.
+func (g *Generator) genFor(node *dt.DecoratedSyntaxTree) error {
+	enter, next := F1U, F2U
+	if node.Children[2].Property == dt.DST_DOWNTO {
+		enter, next = F1D, F2D
+	}
+
+	if err := g.genAddress(&node.Children[0]); err != nil {
+		return err
+	}
+
+	if err := g.genValue(&node.Children[1]); err != nil {
+		return err
+	}
+
+	if err := g.genValue(&node.Children[2]); err != nil {
+		return err
+	}
+
+	exit := g.emit(enter, 0, 0, "")
+	body := len(g.program.Code)
+
+	if err := g.genStatement(&node.Children[3]); err != nil {
+		return err
+	}
+
+	g.emit(next, 0, body, "")
+	g.patch(exit)
+	return nil
+}
+
+// genCall pushes a mark stack, the arguments laid out exactly like the
+// callee's parameter area, and then calls the block.
+func (g *Generator) genCall(node *dt.DecoratedSyntaxTree) error {
+	entry := g.tab[node.Data]
+
+	blockIndex, ok := g.blocks[node.Data]
+	if !ok {
+		return fmt.Errorf("subprogram '%s' has no body", entry.Identifier)
+	}
+	block := g.btab[entry.Data]
+
+	g.emit(MKS, 0, blockIndex, entry.Identifier)
+
+	for i := range node.Children {
+		arg := &node.Children[i]
+		param := block.Start + i
+
+		if param > block.ParamEnd {
+			return fmt.Errorf("too many arguments for '%s'", entry.Identifier)
+		}
+
+		if err := g.genArgument(arg, param); err != nil {
+			return err
+		}
+	}
+
+	g.emit(CAL, 0, block.ParamSize, entry.Identifier)
+	return nil
+}
+
+func (g *Generator) genArgument(arg *dt.DecoratedSyntaxTree, param int) error {
+	entry := g.tab[param]
+	size := g.slotSize(param)
+
+	if !entry.Normal {
+		if err := g.genAddress(arg); err != nil {
+			return err
+		}
+		g.pad(size - 1)
+		return nil
+	}
+
+	typ, _ := g.resolveType(entry.Type, entry.Reference)
+
+	if isBlockType(typ) {
+		if idx, ok := g.stringConstant(arg); ok {
+			g.emit(LDS, size, idx, "")
+			return nil
+		}
+
+		if err := g.genAddress(arg); err != nil {
+			return err
+		}
+
+		g.emit(LDB, 0, size, "")
+		return nil
+	}
+
+	if err := g.genValue(arg); err != nil {
+		return err
+	}
+
+	if err := g.convert(arg, typ); err != nil {
+		return err
+	}
+
+	g.pad(size - 1)
+	return nil
+}
+
+func (g *Generator) pad(cells int) {
+	if cells > 0 {
+		g.emit(INT, 0, cells, "")
+	}
+}
+
+// genBuiltin emits the code of a builtin call. write handles each argument
+// by its type; the functions leave their result in place of the argument.
+func (g *Generator) genBuiltin(node *dt.DecoratedSyntaxTree) error {
+	b := dt.Builtin(node.Data)
+
+	switch b {
+	case dt.BUILTIN_WRITE, dt.BUILTIN_WRITELN:
+		for i := range node.Children {
+			if err := g.genWrite(&node.Children[i]); err != nil {
+				return err
+			}
+		}
+
+		if b == dt.BUILTIN_WRITELN {
+			g.emit(WRL, 0, 0, "")
+		}
+		return nil
+
+	case dt.BUILTIN_READ, dt.BUILTIN_READLN:
+		for i := range node.Children {
+			arg := &node.Children[i]
+
+			typ, _, err := g.typeOf(arg)
+			if err != nil {
+				return err
+			}
+
+			if err := g.genAddress(arg); err != nil {
+				return err
+			}
+			g.emit(RED, int(typ), 0, "")
+		}
+
+		if b == dt.BUILTIN_READLN {
+			g.emit(RDL, 0, 0, "")
+		}
+		return nil
+	}
+
+	if len(node.Children) != 1 {
+		return fmt.Errorf("'%s' expects 1 argument", b)
+	}
+
+	arg := &node.Children[0]
+
+	typ, _, err := g.typeOf(arg)
+	if err != nil {
+		return err
+	}
+
+	if err := g.genValue(arg); err != nil {
+		return err
+	}
+
+	realArg := 0
+	if typ == dt.TAB_ENTRY_REAL {
+		realArg = 1
+	}
+
+	g.emit(SFN, int(b), realArg, b.String())
+	return nil
+}
+
+func (g *Generator) genWrite(arg *dt.DecoratedSyntaxTree) error {
+	if idx, ok := g.stringConstant(arg); ok {
+		g.emit(WRS, 0, idx, "")
+		return nil
+	}
+
+	typ, ref, err := g.typeOf(arg)
+	if err != nil {
+		return err
+	}
+
+	if typ == dt.TAB_ENTRY_ARRAY && g.isCharArray(ref) {
+		if err := g.genAddress(arg); err != nil {
+			return err
+		}
+		g.emit(WRB, 0, g.atab[ref].TotalSize, "")
+		return nil
+	}
+
+	if isBlockType(typ) {
+		return fmt.Errorf("cannot write a value of type %s", typ)
+	}
+
+	if err := g.genValue(arg); err != nil {
+		return err
+	}
+	g.emit(WRW, int(typ), 0, "")
+	return nil
+}
+
+// stringConstant reports the strtab index of a string literal or of a
+// constant declared with one.
+func (g *Generator) stringConstant(node *dt.DecoratedSyntaxTree) (int, bool) {
+	switch node.SelfType {
+	case dt.DST_STR_LITERAL:
+		return node.Data, true
+	case dt.DST_CONST:
+		entry := g.tab[node.Data]
+		if typ, ref := g.resolveType(entry.Type, entry.Reference); typ == dt.TAB_ENTRY_ARRAY && ref == 0 {
+			return entry.Data, true
+		}
+	}
+	return 0, false
+}
+
+// isString reports whether a value of the type can be compared as a string.
+func (g *Generator) isString(typ dt.TabEntryType, ref int) bool {
+	return typ == dt.TAB_ENTRY_ARRAY && g.isCharArray(ref)
+}
+
+func (g *Generator) isCharArray(atabIndex int) bool {
+	entry := g.atab[atabIndex]
+	typ, _ := g.resolveType(entry.ElementType, entry.ElementReference)
+	return typ == dt.TAB_ENTRY_CHAR
+}
+
+// frameOffset is the cell offset of a variable inside its frame. The return
+// slot of a function is the first cell of the mark stack.
+func (g *Generator) frameOffset(tabIndex int) int {
+	if g.tab[tabIndex].Object == dt.TAB_ENTRY_RETURN {
+		return 0
+	}
+	return FrameHeader + g.tab[tabIndex].Data
+}
@@ -1,16 +1,24 @@
 package common
 
 import (
+	"io"
 	"os"
+	"slices"
 	"unicode/utf8"
 )
 
+// readChunk is how much a streaming RuneReader asks its source for at once.
+const readChunk = 4096
+
 type RuneReader struct {
 	buf      []byte
+	base     int // offset of buf[0] in the input
 	off      int
 	line     int
 	col      int
 	filePath string
+	src      io.Reader // nil once the whole input is in buf
+	err      error
 }
 
 func NewRuneReaderFromFile(path string) (*RuneReader, error) {
@@ -20,11 +28,71 @@ func NewRuneReaderFromFile(path string) (*RuneReader, error) {
 		return nil, err
 	}
 
-	return &RuneReader{buf: b, line: 1, col: 1, filePath: path}, nil
+	return NewRuneReader(path, b), nil
+}
+
+// NewRuneReader reads source that is already in memory. path is only used
+// to name the source.
+func NewRuneReader(path string, b []byte) *RuneReader {
+	return &RuneReader{buf: b, line: 1, col: 1, filePath: path}
+}
+
+func NewRuneReaderFromString(path string, s string) *RuneReader {
+	return NewRuneReader(path, []byte(s))
+}
+
+// NewRuneReaderFromReader reads src in chunks as runes are needed. Input
+// before the offset passed to Release is dropped, so memory stays bounded
+// by the longest token rather than the whole source.
+func NewRuneReaderFromReader(path string, src io.Reader) *RuneReader {
+	return &RuneReader{line: 1, col: 1, filePath: path, src: src}
+}
+
+// fill reads from the source until n bytes past the current offset are
+// buffered or the source is exhausted.
+func (r *RuneReader) fill(n int) {
+	for r.src != nil && r.off+n > r.base+len(r.buf) {
+		if len(r.buf) == cap(r.buf) {
+			r.buf = slices.Grow(r.buf, readChunk)
+		}
+
+		m, err := r.src.Read(r.buf[len(r.buf):cap(r.buf)])
+		r.buf = r.buf[:len(r.buf)+m]
+
+		if err != nil {
+			if err != io.EOF {
+				r.err = err
+			}
+			r.src = nil
+		}
+	}
+}
+
+// Err is the error the source failed with, if any. The reader reports EOF
+// once it happens.
+func (r *RuneReader) Err() error {
+	return r.err
+}
+
+// Release tells a streaming reader that input before off is no longer
+// needed. Slice, Seek and Restore must not go back past it afterwards.
+func (r *RuneReader) Release(off int) {
+	drop := off - r.base
+	if r.src == nil || drop < readChunk || drop < len(r.buf)/2 {
+		return
+	}
+
+	n := copy(r.buf, r.buf[drop:])
+	r.buf = r.buf[:n]
+	r.base = off
 }
 
 func (r *RuneReader) EOF() bool {
-	return r.off >= len(r.buf)
+	if r.off < r.base+len(r.buf) {
+		return false
+	}
+	r.fill(1)
+	return r.off >= r.base+len(r.buf)
 }
 
 func (r *RuneReader) Offset() int {
@@ -40,19 +108,19 @@ func (r *RuneReader) FilePath() string {
 }
 
 func (r *RuneReader) Slice(start, end int) string {
-	if start < 0 {
-		start = 0
+	if start < r.base {
+		start = r.base
 	}
 
-	if end > len(r.buf) {
-		end = len(r.buf)
+	if end > r.base+len(r.buf) {
+		end = r.base + len(r.buf)
 	}
 
 	if start > end {
 		start, end = end, start
 	}
 
-	return string(r.buf[start:end])
+	return string(r.buf[start-r.base : end-r.base])
 }
 
 type Snapshot struct{ off, line, col int } // Buat nyimpen posisi baca saat ini
@@ -61,6 +129,16 @@ func (r *RuneReader) Snapshot() Snapshot {
 	return Snapshot{r.off, r.line, r.col}
 }
 
+// Pos is the line and column the snapshot was taken at.
+func (s Snapshot) Pos() (int, int) {
+	return s.line, s.col
+}
+
+// Offset is the byte offset the snapshot was taken at.
+func (s Snapshot) Offset() int {
+	return s.off
+}
+
 func (r *RuneReader) Restore(s Snapshot) {
 	r.off, r.line, r.col = s.off, s.line, s.col
 }
@@ -74,7 +152,10 @@ func (r *RuneReader) Peek() rune {
 		return 0
 	}
 
-	ch, _ := utf8.DecodeRune(r.buf[r.off:])
+	if r.src != nil {
+		r.fill(utf8.UTFMax)
+	}
+	ch, _ := utf8.DecodeRune(r.buf[r.off-r.base:])
 	return ch
 }
 
@@ -83,12 +164,15 @@ func (r *RuneReader) Read() (rune, bool) {
 		return 0, false
 	}
 
-	ch, w := utf8.DecodeRune(r.buf[r.off:])
+	if r.src != nil {
+		r.fill(utf8.UTFMax)
+	}
+	ch, w := utf8.DecodeRune(r.buf[r.off-r.base:])
 	r.off += w
 
 	switch ch {
 	case '\r':
-		if !r.EOF() && r.buf[r.off] == '\n' {
+		if !r.EOF() && r.buf[r.off-r.base] == '\n' {
 			r.off++
 		}
 
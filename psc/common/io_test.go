@@ -0,0 +1,136 @@
+package common
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type step struct {
+	r         rune
+	line, col int
+	off       int
+}
+
+func readAll(r *RuneReader) []step {
+	var steps []step
+	for {
+		ch, ok := r.Read()
+		if !ok {
+			return steps
+		}
+		line, col := r.Pos()
+		steps = append(steps, step{ch, line, col, r.Offset()})
+	}
+}
+
+func TestStreamingReadsInChunks(t *testing.T) {
+	src := "program Ünï;\r\nmulai\n  writeln('λ → x');\nselesai.\n" + strings.Repeat("{ pad }\n", 1200)
+	want := readAll(NewRuneReaderFromString("a.pas", src))
+
+	readers := map[string]io.Reader{
+		"one byte": iotest.OneByteReader(strings.NewReader(src)),
+		"half":     iotest.HalfReader(strings.NewReader(src)),
+		"data+EOF": iotest.DataErrReader(strings.NewReader(src)),
+	}
+
+	for name, src := range readers {
+		r := NewRuneReaderFromReader("a.pas", src)
+		got := readAll(r)
+
+		if len(got) != len(want) {
+			t.Errorf("%s: read %d runes, want %d", name, len(got), len(want))
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: rune %d is %+v, want %+v", name, i, got[i], want[i])
+				break
+			}
+		}
+		if r.Err() != nil {
+			t.Errorf("%s: Err() = %v, want nil", name, r.Err())
+		}
+	}
+}
+
+func TestStreamingSliceAndRestore(t *testing.T) {
+	r := NewRuneReaderFromReader("a.pas", iotest.OneByteReader(strings.NewReader("abc λdef")))
+
+	start := r.Snapshot()
+	for range 5 {
+		r.Read()
+	}
+	if got := r.Slice(start.off, r.Offset()); got != "abc λ" {
+		t.Errorf("Slice = %q, want %q", got, "abc λ")
+	}
+
+	r.Restore(start)
+	if ch, _ := r.Read(); ch != 'a' {
+		t.Errorf("Read after Restore = %q, want 'a'", ch)
+	}
+}
+
+func TestReleaseBoundsBuffer(t *testing.T) {
+	src := strings.Repeat("x := x + 1;\n", 20000)
+	r := NewRuneReaderFromReader("a.pas", strings.NewReader(src))
+
+	most := 0
+	for !r.EOF() {
+		r.Release(r.Offset())
+		r.Read()
+		most = max(most, cap(r.buf))
+	}
+
+	if r.Offset() != len(src) {
+		t.Fatalf("read %d bytes, want %d", r.Offset(), len(src))
+	}
+	if most > 4*readChunk {
+		t.Errorf("buffer grew to %d bytes reading %d, want at most %d", most, len(src), 4*readChunk)
+	}
+}
+
+func TestReleaseKeepsUnreleasedInput(t *testing.T) {
+	src := strings.Repeat("a", 3*readChunk) + "tail"
+	r := NewRuneReaderFromReader("a.pas", strings.NewReader(src))
+
+	for r.Offset() < 3*readChunk {
+		r.Read()
+	}
+	mark := r.Offset()
+	r.Release(mark)
+
+	for !r.EOF() {
+		r.Read()
+	}
+	if got := r.Slice(mark, r.Offset()); got != "tail" {
+		t.Errorf("Slice after Release = %q, want %q", got, "tail")
+	}
+}
+
+func TestFailingReader(t *testing.T) {
+	fail := errors.New("disk on fire")
+	src := io.MultiReader(strings.NewReader("mulai"), iotest.ErrReader(fail))
+	r := NewRuneReaderFromReader("a.pas", src)
+
+	var sb strings.Builder
+	for {
+		ch, ok := r.Read()
+		if !ok {
+			break
+		}
+		sb.WriteRune(ch)
+	}
+
+	if sb.String() != "mulai" {
+		t.Errorf("read %q before the failure, want %q", sb.String(), "mulai")
+	}
+	if !r.EOF() {
+		t.Error("EOF() = false after the failure")
+	}
+	if !errors.Is(r.Err(), fail) {
+		t.Errorf("Err() = %v, want %v", r.Err(), fail)
+	}
+}
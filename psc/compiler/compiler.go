@@ -0,0 +1,335 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/codegen"
+	iox "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/common"
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/lexer"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/parser"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/semantic"
+)
+
+type Stage int
+
+const (
+	STAGE_LEX Stage = iota + 1
+	STAGE_PARSE
+	STAGE_CHECK
+	STAGE_CODEGEN
+)
+
+func (s Stage) String() string {
+	names := [...]string{"", "lex", "parse", "check", "codegen"}
+	if int(s) <= 0 || int(s) >= len(names) {
+		return "unknown"
+	}
+	return names[s]
+}
+
+type Options struct {
+	// Lang selects the keyword set. Nil means dt.LANGUAGE_INDO.
+	Lang *dt.LanguageProfile
+	// Rules is the DFA configuration. Empty means Lang.Rules.
+	Rules string
+	// Path names the source in diagnostics.
+	Path string
+	// StopAfter is the last stage to run. Zero means STAGE_CHECK.
+	StopAfter Stage
+	// Tracer, when set, sees every step of the lexer.
+	Tracer lexer.Tracer
+	// Defines are the symbols {$IFDEF} sees as defined from the start.
+	Defines []string
+	// UnitPath lists the directories searched for the units a program
+	// uses, after the directory of the program itself.
+	UnitPath []string
+}
+
+// Result holds whatever the pipeline produced before it stopped. A stage
+// only runs when the previous one reported no diagnostics, except that a
+// partial parse tree is kept next to its syntax errors.
+type Result struct {
+	Lang *dt.LanguageProfile
+
+	// Tokens includes comments. It is only kept when StopAfter is
+	// STAGE_LEX; later stages stream the tokens from the lexer to the
+	// parser, which is given them with the comments attached as trivia.
+	Tokens []dt.Token
+	Tree   *dt.ParseTree
+	// Units are the parse trees of the units the program uses, directly
+	// or through other units, in the order they were found.
+	Units   []*dt.ParseTree
+	DST     *dt.DecoratedSyntaxTree
+	Tab     dt.Tab
+	Atab    dt.Atab
+	Btab    dt.Btab
+	StrTab  dt.StrTab
+	Program *codegen.Program
+
+	LexErrors      []error
+	ParseErrors    []*parser.ParseError
+	SemanticErrors []*semantic.SemanticError
+}
+
+// Compile runs src through the pipeline. Diagnostics in a file included
+// with {$I}, or in a unit the program uses, name that file. The returned
+// error is reserved for problems outside the program itself, such as
+// unreadable rules or a construct the code generator does not support;
+// mistakes in the program end up in the Result.
+func Compile(src []byte, opts Options) (*Result, error) {
+	return compile(iox.NewRuneReader(opts.Path, src), opts)
+}
+
+// CompileReader is Compile for source read from src as the lexer needs it,
+// such as standard input. Neither the source nor its tokens are held in
+// memory as a whole.
+func CompileReader(src io.Reader, opts Options) (*Result, error) {
+	return compile(iox.NewRuneReaderFromReader(opts.Path, src), opts)
+}
+
+func compile(r *iox.RuneReader, opts Options) (*Result, error) {
+	if opts.Lang == nil {
+		opts.Lang = dt.LANGUAGE_INDO
+	}
+
+	if opts.Rules == "" {
+		opts.Rules = opts.Lang.Rules
+	}
+
+	if opts.StopAfter == 0 {
+		opts.StopAfter = STAGE_CHECK
+	}
+
+	d, err := lexer.LoadJSON(opts.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Lang: opts.Lang}
+
+	if opts.StopAfter == STAGE_LEX {
+		lx := lexer.New(d, r)
+		lx.SetTracer(opts.Tracer)
+
+		pp := lexer.NewPreprocessor(d, opts.Defines)
+		pp.SetTracer(opts.Tracer)
+
+		res.Tokens, res.LexErrors = pp.ScanAll(lx)
+		return res, nil
+	}
+
+	res.Tree, res.LexErrors, res.ParseErrors = parse(d, opts, r)
+	if len(res.LexErrors) > 0 {
+		return res, nil
+	}
+	for _, e := range res.ParseErrors {
+		if e.Got != nil && e.Got.File != opts.Path {
+			e.File = e.Got.File
+		}
+	}
+	if opts.StopAfter == STAGE_PARSE || len(res.ParseErrors) > 0 {
+		return res, nil
+	}
+
+	loadUnits(d, opts, res)
+	if len(res.LexErrors) > 0 || len(res.ParseErrors) > 0 {
+		return res, nil
+	}
+
+	an := semantic.New(res.Tree, opts.Lang)
+	for _, unit := range res.Units {
+		an.AddUnit(unit)
+	}
+
+	res.Tab, res.Atab, res.Btab, res.StrTab, res.DST, res.SemanticErrors = an.Analyze()
+	for _, e := range res.SemanticErrors {
+		if e.Token != nil && e.Token.File != opts.Path {
+			e.File = e.Token.File
+		}
+	}
+	if opts.StopAfter == STAGE_CHECK || len(res.SemanticErrors) > 0 {
+		return res, nil
+	}
+
+	res.Program, err = codegen.New(res.Tab, res.Atab, res.Btab, res.StrTab, res.DST).Generate()
+	if err != nil {
+		return res, fmt.Errorf("codegen: %w", err)
+	}
+
+	return res, nil
+}
+
+// parse lexes and parses the source r reads, pulling each token from the
+// lexer as the parser needs it. Like the stages it joins, it gives no parse
+// tree or syntax errors when there are lexical errors.
+func parse(d *lexer.DFA, opts Options, r *iox.RuneReader) (*dt.ParseTree, []error, []*parser.ParseError) {
+	lx := lexer.New(d, r)
+	lx.SetTracer(opts.Tracer)
+
+	pp := lexer.NewPreprocessor(d, opts.Defines)
+	pp.SetTracer(opts.Tracer)
+
+	var lexErrors []error
+	tokens := func(yield func(dt.Token) bool) {
+		for tok, err := range lexer.WithTrivia(pp.All(lx)) {
+			if err != nil {
+				lexErrors = append(lexErrors, err)
+				continue
+			}
+			if !yield(tok) {
+				return
+			}
+		}
+	}
+
+	tree, parseErrors := parser.NewFromSeq(tokens, opts.Lang).Parse()
+	if len(lexErrors) > 0 {
+		return nil, lexErrors, nil
+	}
+	return tree, nil, parseErrors
+}
+
+// loadUnits parses the units named in the uses clauses of res.Tree, and
+// then in those of the units found, each once. The unit NAME is read from
+// NAME.pas, or from its lower case spelling, in the first directory that
+// has it. A unit that is not found is left for the analyzer to report
+// where it is used.
+func loadUnits(d *lexer.DFA, opts Options, res *Result) {
+	dirs := append([]string{filepath.Dir(opts.Path)}, opts.UnitPath...)
+	seen := make(map[string]bool)
+	queue := res.Tree.Uses()
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		key := dt.NormalizeIdentifier(name.Lexeme)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		path, ok := findUnit(name.Lexeme, dirs)
+		if !ok {
+			continue
+		}
+
+		r, err := iox.NewRuneReaderFromFile(path)
+		if err != nil {
+			res.LexErrors = append(res.LexErrors, err)
+			continue
+		}
+
+		tree, lexErrors, parseErrors := parse(d, Options{Lang: opts.Lang, Defines: opts.Defines}, r)
+		for _, err := range lexErrors {
+			if lexErr, ok := err.(*lexer.LexError); ok && lexErr.File == "" {
+				lexErr.File = path
+			}
+		}
+		res.LexErrors = append(res.LexErrors, lexErrors...)
+		if len(lexErrors) > 0 {
+			continue
+		}
+
+		for _, e := range parseErrors {
+			e.File = path
+			if e.Got != nil && e.Got.File != "" {
+				e.File = e.Got.File
+			}
+		}
+		res.ParseErrors = append(res.ParseErrors, parseErrors...)
+		if len(parseErrors) > 0 {
+			continue
+		}
+
+		res.Units = append(res.Units, tree)
+		queue = append(queue, tree.Uses()...)
+	}
+}
+
+func findUnit(name string, dirs []string) (string, bool) {
+	for _, dir := range dirs {
+		for _, file := range []string{name + ".pas", strings.ToLower(name) + ".pas"} {
+			path := filepath.Join(dir, file)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Defines collects -D flags into Options.Defines. A flag may name several
+// symbols, separated by commas.
+type Defines []string
+
+func (d *Defines) String() string {
+	return strings.Join(*d, ",")
+}
+
+func (d *Defines) Set(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			*d = append(*d, name)
+		}
+	}
+	return nil
+}
+
+// UnitPath collects --unit-path flags into Options.UnitPath. A flag may
+// name several directories, separated as in PATH.
+type UnitPath []string
+
+func (u *UnitPath) String() string {
+	return strings.Join(*u, string(filepath.ListSeparator))
+}
+
+func (u *UnitPath) Set(value string) error {
+	for _, dir := range filepath.SplitList(value) {
+		if dir != "" {
+			*u = append(*u, dir)
+		}
+	}
+	return nil
+}
+
+// CompileFile compiles the file at path, or standard input, streamed
+// through CompileReader, when path is "-".
+func CompileFile(path string, opts Options) (*Result, error) {
+	if path == "-" {
+		opts.Path = "<stdin>"
+		return CompileReader(os.Stdin, opts)
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Path = path
+	return Compile(src, opts)
+}
+
+func (r *Result) HasErrors() bool {
+	return len(r.LexErrors) > 0 || len(r.ParseErrors) > 0 || len(r.SemanticErrors) > 0
+}
+
+// Diagnostics returns every lexical, syntax and semantic error, in that order.
+func (r *Result) Diagnostics() []error {
+	diags := make([]error, 0, len(r.LexErrors)+len(r.ParseErrors)+len(r.SemanticErrors))
+
+	diags = append(diags, r.LexErrors...)
+	for _, e := range r.ParseErrors {
+		diags = append(diags, e)
+	}
+	for _, e := range r.SemanticErrors {
+		diags = append(diags, e)
+	}
+
+	return diags
+}
@@ -0,0 +1,60 @@
+package compiler
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+const rules = "../../config/tokenizer_m3.json"
+
+func TestCompileReaderMatchesCompile(t *testing.T) {
+	src, err := os.ReadFile("../../test/milestone-3/input-4-indo.pas")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	opts := Options{Rules: rules, Path: "input.pas", StopAfter: STAGE_CODEGEN}
+
+	want, err := Compile(src, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CompileReader(iotest.OneByteReader(strings.NewReader(string(src))), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.HasErrors() || want.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %v, %v", got.Diagnostics(), want.Diagnostics())
+	}
+	if got.DST.String() != want.DST.String() {
+		t.Error("streamed source gives a different DST")
+	}
+	if got.Tab.String() != want.Tab.String() {
+		t.Error("streamed source gives a different tab")
+	}
+	if len(got.Program.Code) != len(want.Program.Code) {
+		t.Errorf("streamed source gives %d instructions, want %d", len(got.Program.Code), len(want.Program.Code))
+	}
+}
+
+func TestCompileReaderReportsReadError(t *testing.T) {
+	fail := errors.New("connection reset")
+	src := io.MultiReader(strings.NewReader("program P;\nmulai\n"), iotest.ErrReader(fail))
+
+	res, err := CompileReader(src, Options{Rules: rules, Path: "<stdin>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.LexErrors) != 1 || !errors.Is(res.LexErrors[0], fail) {
+		t.Fatalf("LexErrors = %v, want only %v", res.LexErrors, fail)
+	}
+	if res.Tree != nil || len(res.ParseErrors) > 0 {
+		t.Error("parsing went on after the source failed to read")
+	}
+}
@@ -0,0 +1,63 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/codegen"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/interp"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/vm"
+)
+
+type Engine string
+
+const (
+	ENGINE_INTERP Engine = "interp"
+	ENGINE_VM     Engine = "vm"
+)
+
+type RunOptions struct {
+	// Engine defaults to ENGINE_INTERP.
+	Engine Engine
+	// StackSize is the VM stack size in cells. Zero means vm.DefaultStackSize.
+	StackSize int
+	// In feeds read and readln. Nil means an empty input.
+	In io.Reader
+}
+
+// Run executes a checked program, writing its output to out. The P-code is
+// generated on demand when the pipeline stopped before STAGE_CODEGEN.
+func (r *Result) Run(out io.Writer, opts RunOptions) error {
+	if r.HasErrors() || r.DST == nil {
+		return errors.New("program has not been checked successfully")
+	}
+
+	switch opts.Engine {
+	case "", ENGINE_INTERP:
+		it := interp.New(r.Tab, r.Atab, r.Btab, r.StrTab, r.DST, out)
+		if opts.In != nil {
+			it.SetInput(opts.In)
+		}
+		return it.Run()
+	case ENGINE_VM:
+		if r.Program == nil {
+			program, err := codegen.New(r.Tab, r.Atab, r.Btab, r.StrTab, r.DST).Generate()
+			if err != nil {
+				return fmt.Errorf("codegen: %w", err)
+			}
+			r.Program = program
+		}
+
+		machine := vm.New(r.Program, out)
+		if opts.StackSize > 0 {
+			machine.SetStackSize(opts.StackSize)
+		}
+		if opts.In != nil {
+			machine.SetInput(opts.In)
+		}
+		return machine.Run()
+	default:
+		return fmt.Errorf("unknown engine %q", opts.Engine)
+	}
+}
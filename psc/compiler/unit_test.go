@@ -0,0 +1,167 @@
+package compiler
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// compileWithUnits writes units into a directory of their own, passed as
+// the unit path, and compiles src against them.
+func compileWithUnits(t *testing.T, src string, units map[string]string) *Result {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, unit := range units {
+		if err := os.WriteFile(filepath.Join(dir, name+".pas"), []byte(unit), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := Compile([]byte(src), Options{
+		Rules:     rules,
+		Path:      filepath.Join(t.TempDir(), "main.pas"),
+		StopAfter: STAGE_CODEGEN,
+		UnitPath:  []string{dir},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+const unitC = `unit C;
+antarmuka
+variabel hitung: integer;
+fungsi Kali(n: integer): integer;
+implementasi
+fungsi Kali(n: integer): integer;
+mulai
+  Kali := n * 2;
+selesai;
+mulai
+  hitung := hitung + 1;
+selesai.
+`
+
+func TestDiamondImport(t *testing.T) {
+	res := compileWithUnits(t, `program P;
+gunakan A, B, C;
+mulai
+  writeln(A.Empat(1), ' ', B.Enam(1), ' ', C.hitung);
+selesai.
+`, map[string]string{
+		"A": `unit A;
+antarmuka
+gunakan C;
+fungsi Empat(n: integer): integer;
+implementasi
+fungsi Empat(n: integer): integer;
+mulai
+  Empat := C.Kali(n) * 2;
+selesai;
+selesai.
+`,
+		"B": `unit B;
+antarmuka
+gunakan C;
+fungsi Enam(n: integer): integer;
+implementasi
+fungsi Enam(n: integer): integer;
+mulai
+  Enam := C.Kali(n) * 3;
+selesai;
+selesai.
+`,
+		"C": unitC,
+	})
+
+	if res.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %v", res.Diagnostics())
+	}
+	if len(res.Units) != 3 {
+		t.Errorf("loaded %d units, want A, B and C once each", len(res.Units))
+	}
+
+	units := 0
+	for _, e := range res.Tab {
+		if e.Object == dt.TAB_ENTRY_UNIT && strings.EqualFold(e.Identifier, "C") {
+			units++
+		}
+	}
+	if units != 1 {
+		t.Errorf("C analyzed %d times, want once", units)
+	}
+
+	var out bytes.Buffer
+	if err := res.Run(&out, RunOptions{Engine: ENGINE_INTERP}); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "4 6 1\n" {
+		t.Errorf("got %q, want C initialized once", got)
+	}
+}
+
+func TestQualifiedAccess(t *testing.T) {
+	res := compileWithUnits(t, `program P;
+gunakan C;
+variabel hitung: integer;
+mulai
+  hitung := 5;
+  writeln(hitung, ' ', C.hitung, ' ', C.Kali(hitung));
+selesai.
+`, map[string]string{"C": unitC})
+
+	if res.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %v", res.Diagnostics())
+	}
+
+	var out bytes.Buffer
+	if err := res.Run(&out, RunOptions{Engine: ENGINE_INTERP}); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "5 1 10\n" {
+		t.Errorf("got %q, want the program's hitung and then C's", got)
+	}
+}
+
+func TestImplementationOnlySymbolRejected(t *testing.T) {
+	res := compileWithUnits(t, `program P;
+gunakan D;
+mulai
+  writeln(D.rahasia);
+selesai.
+`, map[string]string{"D": `unit D;
+antarmuka
+implementasi
+variabel rahasia: integer;
+selesai.
+`})
+
+	if len(res.SemanticErrors) != 1 {
+		t.Fatalf("got %v, want one semantic error", res.Diagnostics())
+	}
+	if err := res.SemanticErrors[0]; !strings.Contains(err.Message, "unit 'D' does not export 'rahasia'") || err.Line != 4 {
+		t.Errorf("got %v, want rahasia reported as not exported at line 4", err)
+	}
+}
+
+func TestMissingUnit(t *testing.T) {
+	res := compileWithUnits(t, `program P;
+gunakan Hilang;
+mulai
+  writeln(1);
+selesai.
+`, nil)
+
+	if len(res.SemanticErrors) != 1 {
+		t.Fatalf("got %v, want one semantic error", res.Diagnostics())
+	}
+	if err := res.SemanticErrors[0]; !strings.Contains(err.Message, "unit 'Hilang' not found") || err.Line != 2 {
+		t.Errorf("got %v, want Hilang reported as not found at line 2", err)
+	}
+}
@@ -0,0 +1,62 @@
+package datatype
+
+// Builtin is a predeclared subprogram. It has no symbol table entry; a call
+// to one is decorated as DST_BUILTIN_CALL with the Builtin in Data, and a
+// user declaration with the same name hides it.
+type Builtin int
+
+const (
+	BUILTIN_WRITE Builtin = iota
+	BUILTIN_WRITELN
+	BUILTIN_READ
+	BUILTIN_READLN
+	BUILTIN_ABS
+	BUILTIN_SQR
+	BUILTIN_SQRT
+	BUILTIN_ODD
+	BUILTIN_ORD
+	BUILTIN_CHR
+	BUILTIN_SUCC
+	BUILTIN_PRED
+	BUILTIN_TRUNC
+	BUILTIN_ROUND
+	BUILTIN_SIN
+	BUILTIN_COS
+	BUILTIN_EXP
+	BUILTIN_LN
+)
+
+var builtinNames = [...]string{
+	"write", "writeln", "read", "readln",
+	"abs", "sqr", "sqrt", "odd", "ord", "chr", "succ", "pred",
+	"trunc", "round", "sin", "cos", "exp", "ln",
+}
+
+func (b Builtin) String() string {
+	if int(b) < 0 || int(b) >= len(builtinNames) {
+		return "unknown"
+	}
+	return builtinNames[b]
+}
+
+// IsProcedure reports whether b is called as a statement rather than used as
+// a value.
+func (b Builtin) IsProcedure() bool {
+	switch b {
+	case BUILTIN_WRITE, BUILTIN_WRITELN, BUILTIN_READ, BUILTIN_READLN:
+		return true
+	}
+	return false
+}
+
+// LookupBuiltin finds the builtin named name, in any case. Names are the
+// same in every language profile.
+func LookupBuiltin(name string) (Builtin, bool) {
+	name = NormalizeIdentifier(name)
+	for i, n := range builtinNames {
+		if n == name {
+			return Builtin(i), true
+		}
+	}
+	return 0, false
+}
@@ -17,6 +17,8 @@ const (
 	DST_THEN
 	DST_ELSE
 	DST_FROM
+	DST_SELECTOR
+	DST_LABEL
 )
 
 func (p DSTProperty) String() string {
@@ -35,6 +37,8 @@ func (p DSTProperty) String() string {
 		"then",
 		"else",
 		"from",
+		"selector",
+		"label",
 	}
 	if int(p) < 0 || int(p) >= len(names) {
 		return "unknown"
@@ -71,10 +75,15 @@ const (
 	DST_RECORD_FIELD
 	DST_PROCEDURE_CALL
 	DST_FUNCTION_CALL
+	DST_BUILTIN_CALL
 	DST_BLOCK
 	DST_IF_BLOCK
 	DST_FOR_BLOCK
 	DST_WHILE_BLOCK
+	DST_CASE_BLOCK
+	DST_CASE_ARM
+	DST_CASE_RANGE
+	DST_REPEAT_BLOCK
 	DST_CONST
 	DST_TYPE
 	DST_VARIABLE
@@ -85,6 +94,7 @@ const (
 	DST_VARIABLE_DECLARATIONS
 	DST_SUBPROGRAM_DECLARATIONS
 	DST_PROGRAM
+	DST_UNIT
 )
 
 func (t DSTNodeType) String() string {
@@ -115,10 +125,15 @@ func (t DSTNodeType) String() string {
 		"record-field",
 		"procedure-call",
 		"function-call",
+		"builtin-call",
 		"block",
 		"if-block",
 		"for-block",
 		"while-block",
+		"case-block",
+		"case-arm",
+		"case-range",
+		"repeat-block",
 		"const",
 		"type",
 		"variable",
@@ -129,6 +144,7 @@ func (t DSTNodeType) String() string {
 		"var-decls",
 		"subprogram-decls",
 		"program",
+		"unit",
 	}
 	if int(t) < 0 || int(t) >= len(names) {
 		return "unknown"
@@ -140,5 +156,7 @@ type DecoratedSyntaxTree struct {
 	Property DSTProperty
 	SelfType DSTNodeType
 	Data     int
+	Line     int  // source line of a statement, 0 when unknown
+	Span     Span // source range the node was analyzed from
 	Children []DecoratedSyntaxTree
 }
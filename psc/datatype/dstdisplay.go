@@ -25,12 +25,15 @@ func formatDataWithSymbols(nodeType DSTNodeType, data int, tab *Tab, atab *Atab,
 	switch nodeType {
 	case DST_CHAR_LITERAL:
 		// Display as character
+		if r := rune(data); r < ' ' || r == 0x7f {
+			return fmt.Sprintf(": #%d", data)
+		}
 		return fmt.Sprintf(": '%c'", rune(data))
 
 	case DST_STR_LITERAL:
 		// Display string from strtab
 		if strtab != nil && data >= 0 && data < len(*strtab) {
-			return fmt.Sprintf(": \"%s\"", (*strtab)[data].String)
+			return fmt.Sprintf(": \"%s\"", printable((*strtab)[data].String))
 		}
 		return fmt.Sprintf(" (strtab[%d])", data)
 
@@ -64,6 +67,9 @@ func formatDataWithSymbols(nodeType DSTNodeType, data int, tab *Tab, atab *Atab,
 		}
 		return fmt.Sprintf(" (tab[%d])", data)
 
+	case DST_BUILTIN_CALL:
+		return fmt.Sprintf(": %s", Builtin(data))
+
 	case DST_ARRAY_ELEMENT, DST_RECORD_FIELD:
 		// Display identifier name
 		if data >= 0 && data < len(*tab) {
@@ -71,7 +77,7 @@ func formatDataWithSymbols(nodeType DSTNodeType, data int, tab *Tab, atab *Atab,
 		}
 		return fmt.Sprintf(" (tab[%d])", data)
 
-	case DST_FUNCTION, DST_PROCEDURE, DST_PROGRAM:
+	case DST_FUNCTION, DST_PROCEDURE, DST_PROGRAM, DST_UNIT:
 		// Display identifier name
 		if data >= 0 && data < len(*tab) {
 			return fmt.Sprintf(": %s (tab[%d])", (*tab)[data].Identifier, data)
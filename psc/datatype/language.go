@@ -0,0 +1,198 @@
+package datatype
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Keyword is a language-independent keyword. A LanguageProfile decides which
+// lexeme spells it.
+type Keyword int
+
+const (
+	KW_PROGRAM Keyword = iota
+	KW_CONST
+	KW_TYPE
+	KW_VAR
+	KW_RECORD
+	KW_ARRAY
+	KW_OF
+	KW_PROCEDURE
+	KW_FUNCTION
+	KW_BEGIN
+	KW_END
+	KW_IF
+	KW_THEN
+	KW_ELSE
+	KW_WHILE
+	KW_DO
+	KW_FOR
+	KW_TO
+	KW_DOWNTO
+	KW_INTEGER
+	KW_REAL
+	KW_BOOLEAN
+	KW_CHAR
+	KW_DIV
+	KW_MOD
+	KW_AND
+	KW_OR
+	KW_NOT
+	KW_TRUE
+	KW_FALSE
+	KW_CASE
+	KW_REPEAT
+	KW_UNTIL
+	KW_UNIT
+	KW_INTERFACE
+	KW_IMPLEMENTATION
+	KW_USES
+)
+
+func (k Keyword) String() string {
+	names := [...]string{
+		"PROGRAM", "CONST", "TYPE", "VAR", "RECORD", "ARRAY", "OF", "PROCEDURE", "FUNCTION",
+		"BEGIN", "END", "IF", "THEN", "ELSE", "WHILE", "DO", "FOR", "TO", "DOWNTO",
+		"INTEGER", "REAL", "BOOLEAN", "CHAR", "DIV", "MOD", "AND", "OR", "NOT", "TRUE", "FALSE",
+		"CASE", "REPEAT", "UNTIL", "UNIT", "INTERFACE", "IMPLEMENTATION", "USES",
+	}
+	if int(k) < 0 || int(k) >= len(names) {
+		return "UNKNOWN"
+	}
+	return names[k]
+}
+
+type LanguageProfile struct {
+	Name string
+	// Rules is the DFA configuration that recognizes this profile's keywords.
+	Rules    string
+	Keywords map[Keyword]string
+}
+
+// Lexeme returns the spelling of k in this profile.
+func (l *LanguageProfile) Lexeme(k Keyword) string {
+	return l.Keywords[k]
+}
+
+// Is reports whether lexeme spells k, in any case.
+func (l *LanguageProfile) Is(k Keyword, lexeme string) bool {
+	return l.Keywords[k] == NormalizeIdentifier(lexeme)
+}
+
+func (l *LanguageProfile) Keyword(lexeme string) (Keyword, bool) {
+	lexeme = NormalizeIdentifier(lexeme)
+	for k, v := range l.Keywords {
+		if v == lexeme {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+var LANGUAGE_INDO = &LanguageProfile{
+	Name:  "indo",
+	Rules: "config/tokenizer_m3.json",
+	Keywords: map[Keyword]string{
+		KW_PROGRAM:        "program",
+		KW_CONST:          "konstanta",
+		KW_TYPE:           "tipe",
+		KW_VAR:            "variabel",
+		KW_RECORD:         "rekaman",
+		KW_ARRAY:          "larik",
+		KW_OF:             "dari",
+		KW_PROCEDURE:      "prosedur",
+		KW_FUNCTION:       "fungsi",
+		KW_BEGIN:          "mulai",
+		KW_END:            "selesai",
+		KW_IF:             "jika",
+		KW_THEN:           "maka",
+		KW_ELSE:           "selain_itu",
+		KW_WHILE:          "selama",
+		KW_DO:             "lakukan",
+		KW_FOR:            "untuk",
+		KW_TO:             "ke",
+		KW_DOWNTO:         "turun_ke",
+		KW_INTEGER:        "integer",
+		KW_REAL:           "real",
+		KW_BOOLEAN:        "boolean",
+		KW_CHAR:           "char",
+		KW_DIV:            "bagi",
+		KW_MOD:            "mod",
+		KW_AND:            "dan",
+		KW_OR:             "atau",
+		KW_NOT:            "tidak",
+		KW_TRUE:           "true",
+		KW_FALSE:          "false",
+		KW_CASE:           "kasus",
+		KW_REPEAT:         "ulangi",
+		KW_UNTIL:          "sampai",
+		KW_UNIT:           "unit",
+		KW_INTERFACE:      "antarmuka",
+		KW_IMPLEMENTATION: "implementasi",
+		KW_USES:           "gunakan",
+	},
+}
+
+var LANGUAGE_EN = &LanguageProfile{
+	Name:  "en",
+	Rules: "config/tokenizer.json",
+	Keywords: map[Keyword]string{
+		KW_PROGRAM:        "program",
+		KW_CONST:          "const",
+		KW_TYPE:           "type",
+		KW_VAR:            "var",
+		KW_RECORD:         "record",
+		KW_ARRAY:          "array",
+		KW_OF:             "of",
+		KW_PROCEDURE:      "procedure",
+		KW_FUNCTION:       "function",
+		KW_BEGIN:          "begin",
+		KW_END:            "end",
+		KW_IF:             "if",
+		KW_THEN:           "then",
+		KW_ELSE:           "else",
+		KW_WHILE:          "while",
+		KW_DO:             "do",
+		KW_FOR:            "for",
+		KW_TO:             "to",
+		KW_DOWNTO:         "downto",
+		KW_INTEGER:        "integer",
+		KW_REAL:           "real",
+		KW_BOOLEAN:        "boolean",
+		KW_CHAR:           "char",
+		KW_DIV:            "div",
+		KW_MOD:            "mod",
+		KW_AND:            "and",
+		KW_OR:             "or",
+		KW_NOT:            "not",
+		KW_TRUE:           "true",
+		KW_FALSE:          "false",
+		KW_CASE:           "case",
+		KW_REPEAT:         "repeat",
+		KW_UNTIL:          "until",
+		KW_UNIT:           "unit",
+		KW_INTERFACE:      "interface",
+		KW_IMPLEMENTATION: "implementation",
+		KW_USES:           "uses",
+	},
+}
+
+var LanguageProfiles = map[string]*LanguageProfile{
+	LANGUAGE_INDO.Name: LANGUAGE_INDO,
+	LANGUAGE_EN.Name:   LANGUAGE_EN,
+}
+
+func LookupLanguageProfile(name string) (*LanguageProfile, error) {
+	if profile, ok := LanguageProfiles[name]; ok {
+		return profile, nil
+	}
+
+	names := make([]string, 0, len(LanguageProfiles))
+	for n := range LanguageProfiles {
+		names = append(names, n)
+	}
+	slices.Sort(names)
+
+	return nil, fmt.Errorf("unknown language %q (available: %s)", name, strings.Join(names, ", "))
+}
@@ -31,6 +31,9 @@ const (
 	IF_STATEMENT_NODE
 	WHILE_STATEMENT_NODE
 	FOR_STATEMENT_NODE
+	CASE_STATEMENT_NODE
+	CASE_ELEMENT_NODE
+	REPEAT_STATEMENT_NODE
 	SUBPROGRAM_CALL_NODE
 	PARAMETER_LIST_NODE
 	EXPRESSION_NODE
@@ -44,6 +47,13 @@ const (
 	STATIC_ACCESS_NODE
 	ARRAY_ACCESS_NODE
 	RECORD_TYPE_NODE
+	UNIT_NODE
+	UNIT_HEADER_NODE
+	USES_CLAUSE_NODE
+	INTERFACE_PART_NODE
+	IMPLEMENTATION_PART_NODE
+	PROCEDURE_HEADING_NODE
+	FUNCTION_HEADING_NODE
 	TOKEN_NODE
 )
 
@@ -51,6 +61,7 @@ type ParseTree struct {
 	RootType   NodeType
 	TokenValue *Token
 	Children   []ParseTree
+	Span       Span // range covered by the node's tokens, zero when it has none
 }
 
 func (t NodeType) String() string {
@@ -78,6 +89,9 @@ func (t NodeType) String() string {
 		"<if-statement>",
 		"<while-statement>",
 		"<for-statement>",
+		"<case-statement>",
+		"<case-element>",
+		"<repeat-statement>",
 		"<procedure/function-call>",
 		"<parameter-list>",
 		"<expression>",
@@ -91,6 +105,13 @@ func (t NodeType) String() string {
 		"<static-access>",
 		"<array-access>",
 		"<record-type>",
+		"<unit>",
+		"<unit-header>",
+		"<uses-clause>",
+		"<interface-part>",
+		"<implementation-part>",
+		"<procedure-heading>",
+		"<function-heading>",
 		"<token>",
 	}
 
@@ -101,6 +122,88 @@ func (t NodeType) String() string {
 	return names[t]
 }
 
+// FirstToken returns the leftmost token under t, or nil if t holds none.
+func (t *ParseTree) FirstToken() *Token {
+	if t.TokenValue != nil {
+		return t.TokenValue
+	}
+
+	for i := range t.Children {
+		if token := t.Children[i].FirstToken(); token != nil {
+			return token
+		}
+	}
+
+	return nil
+}
+
+// Uses returns the unit names in the uses clause of a program or of a unit
+// interface, in the order they are written.
+func (t *ParseTree) Uses() []*Token {
+	for i := range t.Children {
+		child := &t.Children[i]
+
+		switch child.RootType {
+		case INTERFACE_PART_NODE:
+			return child.Uses()
+		case USES_CLAUSE_NODE:
+			var names []*Token
+			for _, name := range child.Children[1].Children {
+				if name.TokenValue.Type == IDENTIFIER {
+					names = append(names, name.TokenValue)
+				}
+			}
+			return names
+		}
+	}
+
+	return nil
+}
+
+// ComputeSpans sets the span of t and of every node under it from the
+// tokens they hold, and returns the span of t. A node without tokens, such
+// as an empty declaration part, gets an empty span where the node after it
+// starts.
+func (t *ParseTree) ComputeSpans() Span {
+	span := t.computeSpans()
+	if !span.IsZero() {
+		t.placeEmpty(span.End)
+	}
+	return span
+}
+
+func (t *ParseTree) computeSpans() Span {
+	var span Span
+	if t.TokenValue != nil {
+		span = t.TokenValue.Span()
+	}
+
+	for i := range t.Children {
+		span = span.Join(t.Children[i].computeSpans())
+	}
+
+	t.Span = span
+	return span
+}
+
+// placeEmpty gives the empty nodes under t the position the next non-empty
+// node starts at, or at if there is none.
+func (t *ParseTree) placeEmpty(at Pos) {
+	if t.Span.IsZero() {
+		t.Span = Span{Start: at, End: at}
+	}
+
+	for i := len(t.Children) - 1; i >= 0; i-- {
+		child := &t.Children[i]
+		if !child.Span.IsZero() {
+			at = child.Span.Start
+			child.placeEmpty(child.Span.End)
+			continue
+		}
+		child.placeEmpty(at)
+	}
+}
+
 func (t ParseTree) String() string {
 	var sb strings.Builder
 	t.writeString(&sb, "", true, true)
@@ -0,0 +1,47 @@
+package datatype
+
+import "fmt"
+
+// Pos is a point in the source. Offset counts bytes from the start of the
+// file; Line and Col are 1-based, with Col counted in runes.
+type Pos struct {
+	Offset int
+	Line   int
+	Col    int
+}
+
+// Span is the source range from Start up to, not including, End. The zero
+// Span means the position is unknown.
+type Span struct {
+	Start Pos
+	End   Pos
+}
+
+func (s Span) IsZero() bool {
+	return s.Start.Line == 0
+}
+
+// Join returns the smallest span covering s and o. A zero span is ignored.
+func (s Span) Join(o Span) Span {
+	if s.IsZero() {
+		return o
+	}
+	if o.IsZero() {
+		return s
+	}
+
+	if o.Start.Offset < s.Start.Offset {
+		s.Start = o.Start
+	}
+	if o.End.Offset > s.End.Offset {
+		s.End = o.End
+	}
+	return s
+}
+
+func (s Span) String() string {
+	if s.Start.Line == s.End.Line {
+		return fmt.Sprintf("%d:%d-%d", s.Start.Line, s.Start.Col, s.End.Col)
+	}
+	return fmt.Sprintf("%d:%d-%d:%d", s.Start.Line, s.Start.Col, s.End.Line, s.End.Col)
+}
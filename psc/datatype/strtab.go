@@ -1,6 +1,9 @@
 package datatype
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type StrTabEntry struct {
 	Length    int
@@ -20,6 +23,20 @@ func (t StrTab) FindString(value string) (int, *StrTabEntry) {
 	return -1, nil
 }
 
+// printable spells the control characters in s as #nn, as they are written
+// in source, so that a value stays on one line of a dump.
+func printable(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			fmt.Fprintf(&sb, "#%d", r)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
 func (t StrTab) String() string {
 	if t == nil || len(t) == 0 {
 		return "<empty string table>"
@@ -38,7 +55,7 @@ func (t StrTab) String() string {
 	out += "---- ----- --------------------------------\n"
 
 	for i, e := range t {
-		s := trunc(e.String, maxStringWidth)
+		s := trunc(printable(e.String), maxStringWidth)
 
 		out += fmt.Sprintf(
 			"%-4d %-5d %-30s\n",
@@ -2,6 +2,7 @@ package datatype
 
 import (
 	"fmt"
+	"strings"
 )
 
 type TabEntryObject int
@@ -16,6 +17,7 @@ const (
 	TAB_ENTRY_PARAM
 	TAB_ENTRY_FIELD
 	TAB_ENTRY_RETURN
+	TAB_ENTRY_UNIT
 )
 
 func (o TabEntryObject) String() string {
@@ -29,6 +31,7 @@ func (o TabEntryObject) String() string {
 		"parameter",
 		"field",
 		"return",
+		"unit",
 	}
 
 	if int(o) < 0 || int(o) > len(names) {
@@ -83,8 +86,17 @@ type TabEntry struct {
 
 type Tab []TabEntry
 
+// NormalizeIdentifier is the form identifiers and keywords are compared in.
+// The language is case-insensitive, so it is the lower case spelling.
+func NormalizeIdentifier(id string) string {
+	return strings.ToLower(id)
+}
+
+// FindIdentifier walks the chain from start for an entry spelled id, in any
+// case.
 func (t *Tab) FindIdentifier(id string, start int) (int, *TabEntry) {
 	current := start
+	key := NormalizeIdentifier(id)
 
 	if current == -1 || len(*t) == 0 {
 		return -1, nil
@@ -95,7 +107,7 @@ func (t *Tab) FindIdentifier(id string, start int) (int, *TabEntry) {
 			return -1, nil
 		}
 
-		if (*t)[current].Identifier == id {
+		if NormalizeIdentifier((*t)[current].Identifier) == key {
 			return current, &(*t)[current]
 		}
 		current = (*t)[current].Link
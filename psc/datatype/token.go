@@ -22,13 +22,35 @@ const (
 	RBRACKET
 	RANGE_OPERATOR
 	COMMENT
+	EOF // only produced by the parser once the token buffer runs out
 )
 
 type Token struct {
 	Type   TokenType
-	Lexeme string // substring aslinya
+	Lexeme string // substring aslinya, dengan kapitalisasi yang ditulis
+	Key    string // Lexeme untuk lookup: huruf kecil, kecuali literal dan komentar
 	Line   int    // posisi awal token (untuk error/report)
 	Col    int
+	Offset int    // byte offset awal token
+	File   string // path file asal token, berbeda dari file utama bila lewat {$I}
+
+	// Posisi tepat setelah karakter terakhir token.
+	EndOffset int
+	EndLine   int
+	EndCol    int
+
+	// Trivia before the token, and comments after it on the same line.
+	// Only tokens that went through lexer.AttachTrivia have any.
+	Leading  []Trivia
+	Trailing []Trivia
+}
+
+// Span is the source range the token was read from.
+func (t *Token) Span() Span {
+	return Span{
+		Start: Pos{Offset: t.Offset, Line: t.Line, Col: t.Col},
+		End:   Pos{Offset: t.EndOffset, Line: t.EndLine, Col: t.EndCol},
+	}
 }
 
 func (t TokenType) String() string {
@@ -36,7 +58,7 @@ func (t TokenType) String() string {
 		"KEYWORD", "IDENTIFIER", "ARITHMETIC_OPERATOR", "RELATIONAL_OPERATOR", "LOGICAL_OPERATOR",
 		"ASSIGN_OPERATOR", "NUMBER", "CHAR_LITERAL", "STRING_LITERAL", "SEMICOLON", "COMMA", "COLON",
 		"DOT", "LPARENTHESIS", "RPARENTHESIS", "LBRACKET", "RBRACKET", "RANGE_OPERATOR",
-		"COMMENT",
+		"COMMENT", "EOF",
 	}
 	if int(t) < 0 || int(t) >= len(names) {
 		return "UNKNOWN"
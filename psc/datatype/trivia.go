@@ -0,0 +1,27 @@
+package datatype
+
+type TriviaKind int
+
+const (
+	TRIVIA_COMMENT TriviaKind = iota
+	TRIVIA_BLANK_LINES
+)
+
+func (k TriviaKind) String() string {
+	names := [...]string{"COMMENT", "BLANK_LINES"}
+	if int(k) < 0 || int(k) >= len(names) {
+		return "UNKNOWN"
+	}
+	return names[k]
+}
+
+// Trivia is source the parser skips but a formatter or a documentation tool
+// still wants: a comment, or a run of blank lines.
+type Trivia struct {
+	Kind TriviaKind
+	Text string // the comment, delimiters included
+	// Lines is the number of blank lines, for TRIVIA_BLANK_LINES.
+	Lines int
+	// Span is where the comment is. It is zero for blank lines.
+	Span Span
+}
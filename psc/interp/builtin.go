@@ -0,0 +1,217 @@
+package interp
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"unicode"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+func (i *Interpreter) callBuiltin(b dt.Builtin, args []dt.DecoratedSyntaxTree) (Value, error) {
+	switch b {
+	case dt.BUILTIN_WRITE, dt.BUILTIN_WRITELN:
+		for j := range args {
+			v, err := i.eval(&args[j])
+			if err != nil {
+				return nil, err
+			}
+
+			s, err := formatValue(v)
+			if err != nil {
+				return nil, newRuntimeError("%v", err)
+			}
+
+			if _, err := i.out.WriteString(s); err != nil {
+				return nil, err
+			}
+		}
+
+		if b == dt.BUILTIN_WRITELN {
+			if err := i.out.WriteByte('\n'); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+
+	case dt.BUILTIN_READ, dt.BUILTIN_READLN:
+		// Prompts written so far should be visible before blocking on input.
+		if err := i.out.Flush(); err != nil {
+			return nil, err
+		}
+
+		for j := range args {
+			loc, err := i.location(&args[j])
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := i.readValue(*loc)
+			if err != nil {
+				return nil, err
+			}
+			*loc = v
+		}
+
+		if b == dt.BUILTIN_READLN {
+			if err := i.skipLine(); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	if len(args) != 1 {
+		return nil, newRuntimeError("'%s' expects 1 argument", b)
+	}
+
+	v, err := i.eval(&args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	switch b {
+	case dt.BUILTIN_ABS:
+		switch v := v.(type) {
+		case int:
+			return max(v, -v), nil
+		case float64:
+			return math.Abs(v), nil
+		}
+	case dt.BUILTIN_SQR:
+		switch v := v.(type) {
+		case int:
+			return v * v, nil
+		case float64:
+			return v * v, nil
+		}
+	case dt.BUILTIN_ODD:
+		if n, ok := v.(int); ok {
+			return n%2 != 0, nil
+		}
+	case dt.BUILTIN_ORD:
+		return ordinal(v)
+	case dt.BUILTIN_CHR:
+		if n, ok := v.(int); ok {
+			return rune(n), nil
+		}
+	case dt.BUILTIN_SUCC, dt.BUILTIN_PRED:
+		n, err := ordinal(v)
+		if err != nil {
+			return nil, err
+		}
+		if b == dt.BUILTIN_SUCC {
+			return fromOrdinal(n+1, v), nil
+		}
+		return fromOrdinal(n-1, v), nil
+	default:
+		x, ok := toReal(v)
+		if !ok {
+			break
+		}
+		return realBuiltin(b, x)
+	}
+
+	return nil, newRuntimeError("'%s' cannot take a value of type %T", b, v)
+}
+
+// realBuiltin evaluates a builtin whose argument the analyzer has already
+// converted to real.
+func realBuiltin(b dt.Builtin, x float64) (Value, error) {
+	switch b {
+	case dt.BUILTIN_SQRT:
+		if x < 0 {
+			return nil, newRuntimeError("sqrt of negative number %g", x)
+		}
+		return math.Sqrt(x), nil
+	case dt.BUILTIN_LN:
+		if x <= 0 {
+			return nil, newRuntimeError("ln of non-positive number %g", x)
+		}
+		return math.Log(x), nil
+	case dt.BUILTIN_SIN:
+		return math.Sin(x), nil
+	case dt.BUILTIN_COS:
+		return math.Cos(x), nil
+	case dt.BUILTIN_EXP:
+		return math.Exp(x), nil
+	case dt.BUILTIN_TRUNC:
+		return int(math.Trunc(x)), nil
+	case dt.BUILTIN_ROUND:
+		return int(math.Round(x)), nil
+	}
+	return nil, newRuntimeError("unknown builtin %s", b)
+}
+
+// readValue reads the next value of the same type as like from the input. A
+// char is the next character as is; numbers skip leading white space.
+func (i *Interpreter) readValue(like Value) (Value, error) {
+	if _, ok := like.(rune); ok {
+		r, _, err := i.in.ReadRune()
+		if err == io.EOF {
+			return nil, newRuntimeError("unexpected end of input")
+		}
+		return r, err
+	}
+
+	word, err := i.readWord()
+	if err != nil {
+		return nil, err
+	}
+
+	switch like.(type) {
+	case int:
+		n, err := strconv.Atoi(word)
+		if err != nil {
+			return nil, newRuntimeError("invalid integer input %q", word)
+		}
+		return n, nil
+	case float64:
+		x, err := strconv.ParseFloat(word, 64)
+		if err != nil {
+			return nil, newRuntimeError("invalid real input %q", word)
+		}
+		return x, nil
+	}
+
+	return nil, newRuntimeError("cannot read a value of type %T", like)
+}
+
+func (i *Interpreter) readWord() (string, error) {
+	var word []rune
+
+	for {
+		r, _, err := i.in.ReadRune()
+		if err == io.EOF {
+			if len(word) == 0 {
+				return "", newRuntimeError("unexpected end of input")
+			}
+			return string(word), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if unicode.IsSpace(r) {
+			if len(word) == 0 {
+				continue
+			}
+			return string(word), i.in.UnreadRune()
+		}
+
+		word = append(word, r)
+	}
+}
+
+func (i *Interpreter) skipLine() error {
+	for {
+		r, _, err := i.in.ReadRune()
+		if err == io.EOF || r == '\n' {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
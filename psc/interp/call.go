@@ -0,0 +1,91 @@
+package interp
+
+import (
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// call runs a procedure or function call node. Parameters are laid out as
+// described by the BtabEntry of the callee: tab[Start..ParamEnd] are the
+// formal parameters and tab[ReturnEnd] is the return slot of a function.
+func (i *Interpreter) call(node *dt.DecoratedSyntaxTree) (Value, error) {
+	if node.SelfType == dt.DST_BUILTIN_CALL {
+		return i.callBuiltin(dt.Builtin(node.Data), node.Children)
+	}
+
+	entry := i.tab[node.Data]
+
+	decl, ok := i.subprograms[node.Data]
+	if !ok {
+		return nil, newRuntimeError("subprogram '%s' has no body", entry.Identifier)
+	}
+
+	block := i.btab[entry.Data]
+
+	callee := &frame{
+		level: entry.Level + 1,
+		slots: make(map[int]*Value),
+	}
+
+	callee.static = i.current
+	for callee.static != nil && callee.static.level > entry.Level {
+		callee.static = callee.static.static
+	}
+
+	for j, arg := range node.Children {
+		param := block.Start + j
+		if param > block.ParamEnd {
+			return nil, newRuntimeError("too many arguments for '%s'", entry.Identifier)
+		}
+
+		if !i.tab[param].Normal {
+			loc, err := i.location(&arg)
+			if err != nil {
+				return nil, err
+			}
+			callee.slots[param] = loc
+			continue
+		}
+
+		v, err := i.eval(&arg)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := v.(int); ok {
+			if typ, _ := i.resolveType(i.tab[param].Type, i.tab[param].Reference); typ == dt.TAB_ENTRY_REAL {
+				v = float64(v.(int))
+			}
+		}
+
+		v = copyValue(v)
+		callee.slots[param] = &v
+	}
+
+	if entry.Object == dt.TAB_ENTRY_FUNC {
+		v := i.zeroValue(entry.Type, entry.Reference)
+		callee.slots[block.ReturnEnd] = &v
+	}
+
+	if i.depth >= i.maxDepth {
+		return nil, newRuntimeError("stack overflow calling '%s'", entry.Identifier)
+	}
+
+	caller := i.current
+	i.current = callee
+	i.depth++
+
+	err := i.runBlock(decl)
+
+	i.depth--
+	i.current = caller
+
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Object == dt.TAB_ENTRY_FUNC {
+		return *callee.slots[block.ReturnEnd], nil
+	}
+
+	return nil, nil
+}
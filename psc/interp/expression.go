@@ -0,0 +1,305 @@
+package interp
+
+import (
+	"math"
+	"strings"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+func (i *Interpreter) location(node *dt.DecoratedSyntaxTree) (*Value, error) {
+	switch node.SelfType {
+	case dt.DST_VARIABLE:
+		return i.lookup(node.Data)
+
+	case dt.DST_RECORD_FIELD:
+		if len(node.Children) == 0 {
+			return nil, newRuntimeError("field '%s' accessed without a record", i.tab[node.Data].Identifier)
+		}
+
+		base, err := i.location(&node.Children[0])
+		if err != nil {
+			return nil, err
+		}
+
+		rec, ok := (*base).(*Record)
+		if !ok {
+			return nil, newRuntimeError("cannot access field '%s' of a non record value", i.tab[node.Data].Identifier)
+		}
+
+		field, ok := rec.Fields[node.Data]
+		if !ok {
+			return nil, newRuntimeError("record has no field '%s'", i.tab[node.Data].Identifier)
+		}
+
+		return field, nil
+
+	case dt.DST_ARRAY_ELEMENT:
+		if len(node.Children) != 2 {
+			return nil, newRuntimeError("malformed array access")
+		}
+
+		base, err := i.location(&node.Children[0])
+		if err != nil {
+			return nil, err
+		}
+
+		index, err := i.eval(&node.Children[1])
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := ordinal(index)
+		if err != nil {
+			return nil, err
+		}
+
+		arr, ok := (*base).(*Array)
+		if !ok {
+			return nil, newRuntimeError("cannot index a non array value")
+		}
+
+		if n < arr.Low || n-arr.Low >= len(arr.Elements) {
+			return nil, newRuntimeError("index %d out of bounds [%d..%d]", n, arr.Low, arr.Low+len(arr.Elements)-1)
+		}
+
+		return &arr.Elements[n-arr.Low], nil
+
+	default:
+		return nil, newRuntimeError("%s is not assignable", node.SelfType)
+	}
+}
+
+func (i *Interpreter) eval(node *dt.DecoratedSyntaxTree) (Value, error) {
+	switch node.SelfType {
+	case dt.DST_INT_LITERAL:
+		return node.Data, nil
+	case dt.DST_REAL_LITERAL:
+		return math.Float64frombits(uint64(node.Data)), nil
+	case dt.DST_BOOL_LITERAL:
+		return node.Data != 0, nil
+	case dt.DST_CHAR_LITERAL:
+		return rune(node.Data), nil
+	case dt.DST_STR_LITERAL:
+		return stringValue(i.strtab[node.Data].String), nil
+
+	case dt.DST_CONST:
+		return i.constValue(node.Data), nil
+
+	case dt.DST_VARIABLE, dt.DST_RECORD_FIELD, dt.DST_ARRAY_ELEMENT:
+		loc, err := i.location(node)
+		if err != nil {
+			return nil, err
+		}
+		return *loc, nil
+
+	case dt.DST_FUNCTION_CALL, dt.DST_BUILTIN_CALL:
+		return i.call(node)
+
+	case dt.DST_CAST_OPERATOR:
+		v, err := i.eval(&node.Children[0])
+		if err != nil {
+			return nil, err
+		}
+		if n, ok := v.(int); ok && dt.TabEntryType(node.Data) == dt.TAB_ENTRY_REAL {
+			return float64(n), nil
+		}
+		return v, nil
+
+	case dt.DST_NEG_OPERATOR:
+		v, err := i.eval(&node.Children[0])
+		if err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case int:
+			return -v, nil
+		case float64:
+			return -v, nil
+		}
+		return nil, newRuntimeError("cannot negate value of type %T", v)
+
+	case dt.DST_NOT_OPERATOR:
+		v, err := i.eval(&node.Children[0])
+		if err != nil {
+			return nil, err
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return nil, newRuntimeError("cannot apply not to value of type %T", v)
+		}
+		return !b, nil
+
+	case dt.DST_ADD_OPERATOR, dt.DST_SUB_OPERATOR, dt.DST_MUL_OPERATOR,
+		dt.DST_DIV_OPERATOR, dt.DST_MOD_OPERATOR, dt.DST_AND_OPERATOR, dt.DST_OR_OPERATOR,
+		dt.DST_EQ_OPERATOR, dt.DST_NE_OPERATOR, dt.DST_GT_OPERATOR,
+		dt.DST_LT_OPERATOR, dt.DST_GE_OPERATOR, dt.DST_LE_OPERATOR:
+		lhs, err := i.eval(&node.Children[0])
+		if err != nil {
+			return nil, err
+		}
+
+		rhs, err := i.eval(&node.Children[1])
+		if err != nil {
+			return nil, err
+		}
+
+		return binary(node.SelfType, lhs, rhs)
+
+	default:
+		return nil, newRuntimeError("cannot evaluate %s", node.SelfType)
+	}
+}
+
+func (i *Interpreter) constValue(tabIndex int) Value {
+	entry := i.tab[tabIndex]
+	typ, ref := i.resolveType(entry.Type, entry.Reference)
+
+	switch typ {
+	case dt.TAB_ENTRY_REAL:
+		return math.Float64frombits(uint64(entry.Data))
+	case dt.TAB_ENTRY_BOOLEAN:
+		return entry.Data != 0
+	case dt.TAB_ENTRY_CHAR:
+		return rune(entry.Data)
+	case dt.TAB_ENTRY_ARRAY:
+		if ref == 0 {
+			return stringValue(i.strtab[entry.Data].String)
+		}
+	}
+
+	return entry.Data
+}
+
+func binary(op dt.DSTNodeType, lhs Value, rhs Value) (Value, error) {
+	switch op {
+	case dt.DST_AND_OPERATOR, dt.DST_OR_OPERATOR:
+		l, ok1 := lhs.(bool)
+		r, ok2 := rhs.(bool)
+		if !ok1 || !ok2 {
+			return nil, newRuntimeError("%s expects boolean operands", op)
+		}
+		if op == dt.DST_AND_OPERATOR {
+			return l && r, nil
+		}
+		return l || r, nil
+
+	case dt.DST_EQ_OPERATOR, dt.DST_NE_OPERATOR, dt.DST_GT_OPERATOR,
+		dt.DST_LT_OPERATOR, dt.DST_GE_OPERATOR, dt.DST_LE_OPERATOR:
+		cmp, err := compare(lhs, rhs)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case dt.DST_EQ_OPERATOR:
+			return cmp == 0, nil
+		case dt.DST_NE_OPERATOR:
+			return cmp != 0, nil
+		case dt.DST_GT_OPERATOR:
+			return cmp > 0, nil
+		case dt.DST_LT_OPERATOR:
+			return cmp < 0, nil
+		case dt.DST_GE_OPERATOR:
+			return cmp >= 0, nil
+		default:
+			return cmp <= 0, nil
+		}
+	}
+
+	l, lok := lhs.(int)
+	r, rok := rhs.(int)
+
+	if lok && rok {
+		switch op {
+		case dt.DST_ADD_OPERATOR:
+			return l + r, nil
+		case dt.DST_SUB_OPERATOR:
+			return l - r, nil
+		case dt.DST_MUL_OPERATOR:
+			return l * r, nil
+		case dt.DST_DIV_OPERATOR:
+			if r == 0 {
+				return nil, newRuntimeError("division by zero")
+			}
+			return l / r, nil
+		case dt.DST_MOD_OPERATOR:
+			if r == 0 {
+				return nil, newRuntimeError("modulo by zero")
+			}
+			return l % r, nil
+		}
+	}
+
+	lf, ok1 := toReal(lhs)
+	rf, ok2 := toReal(rhs)
+	if !ok1 || !ok2 {
+		return nil, newRuntimeError("%s cannot be applied to %T and %T", op, lhs, rhs)
+	}
+
+	switch op {
+	case dt.DST_ADD_OPERATOR:
+		return lf + rf, nil
+	case dt.DST_SUB_OPERATOR:
+		return lf - rf, nil
+	case dt.DST_MUL_OPERATOR:
+		return lf * rf, nil
+	case dt.DST_DIV_OPERATOR:
+		if rf == 0 {
+			return nil, newRuntimeError("division by zero")
+		}
+		return lf / rf, nil
+	default:
+		return nil, newRuntimeError("%s cannot be applied to %T and %T", op, lhs, rhs)
+	}
+}
+
+func toReal(v Value) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func compare(lhs Value, rhs Value) (int, error) {
+	if isCharArray(lhs) && isCharArray(rhs) {
+		return strings.Compare(charArrayString(lhs.(*Array)), charArrayString(rhs.(*Array))), nil
+	}
+
+	if l, ok := lhs.(int); ok {
+		if r, ok := rhs.(int); ok {
+			switch {
+			case l < r:
+				return -1, nil
+			case l > r:
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+	}
+
+	if lf, ok := toReal(lhs); ok {
+		if rf, ok := toReal(rhs); ok {
+			switch {
+			case lf < rf:
+				return -1, nil
+			case lf > rf:
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+	}
+
+	l, err1 := ordinal(lhs)
+	r, err2 := ordinal(rhs)
+	if err1 != nil || err2 != nil {
+		return 0, newRuntimeError("cannot compare %T and %T", lhs, rhs)
+	}
+
+	return l - r, nil
+}
@@ -0,0 +1,141 @@
+package interp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+type RuntimeError struct {
+	Message string
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("Runtime error: %s", e.Message)
+}
+
+func newRuntimeError(format string, args ...any) error {
+	return &RuntimeError{Message: fmt.Sprintf(format, args...)}
+}
+
+// frame is the activation record of the program or of one subprogram call.
+// static points to the frame of the lexically enclosing block, so a variable
+// declared at Level n is found by following static until level == n.
+type frame struct {
+	level  int
+	static *frame
+	slots  map[int]*Value
+}
+
+type Interpreter struct {
+	tab    dt.Tab
+	atab   dt.Atab
+	btab   dt.Btab
+	strtab dt.StrTab
+	dst    *dt.DecoratedSyntaxTree
+
+	subprograms map[int]*dt.DecoratedSyntaxTree
+	current     *frame
+	depth       int
+	maxDepth    int
+
+	in  *bufio.Reader
+	out *bufio.Writer
+}
+
+func New(tab dt.Tab, atab dt.Atab, btab dt.Btab, strtab dt.StrTab, dst *dt.DecoratedSyntaxTree, out io.Writer) *Interpreter {
+	return &Interpreter{
+		tab:         tab,
+		atab:        atab,
+		btab:        btab,
+		strtab:      strtab,
+		dst:         dst,
+		subprograms: make(map[int]*dt.DecoratedSyntaxTree),
+		maxDepth:    10000,
+		in:          bufio.NewReader(strings.NewReader("")),
+		out:         bufio.NewWriter(out),
+	}
+}
+
+// SetInput sets where read and readln take their input from. Without it the
+// program sees an empty input.
+func (i *Interpreter) SetInput(in io.Reader) {
+	i.in = bufio.NewReader(in)
+}
+
+func (i *Interpreter) Run() error {
+	if i.dst == nil || i.dst.SelfType != dt.DST_PROGRAM {
+		return errors.New("expected program")
+	}
+
+	i.collectSubprograms(i.dst)
+
+	i.current = &frame{level: 0, slots: make(map[int]*Value)}
+
+	err := i.runBlock(i.dst)
+
+	if flushErr := i.out.Flush(); err == nil {
+		err = flushErr
+	}
+
+	return err
+}
+
+func (i *Interpreter) collectSubprograms(node *dt.DecoratedSyntaxTree) {
+	for j := range node.Children {
+		child := &node.Children[j]
+
+		switch child.SelfType {
+		case dt.DST_FUNCTION, dt.DST_PROCEDURE:
+			i.subprograms[child.Data] = child
+			i.collectSubprograms(child)
+		}
+	}
+}
+
+// runBlock allocates the local variables declared directly under node and
+// executes its statement part, which is always the last child.
+func (i *Interpreter) runBlock(node *dt.DecoratedSyntaxTree) error {
+	for _, child := range node.Children {
+		if child.SelfType != dt.DST_VARIABLE_DECLARATIONS {
+			continue
+		}
+
+		for _, decl := range child.Children {
+			if decl.Property != dt.DST_DECLARE {
+				continue
+			}
+
+			entry := i.tab[decl.Data]
+			v := i.zeroValue(entry.Type, entry.Reference)
+			i.current.slots[decl.Data] = &v
+		}
+	}
+
+	if len(node.Children) == 0 {
+		return nil
+	}
+
+	return i.execStatement(&node.Children[len(node.Children)-1])
+}
+
+func (i *Interpreter) lookup(tabIndex int) (*Value, error) {
+	level := i.tab[tabIndex].Level
+
+	f := i.current
+	for f != nil && f.level > level {
+		f = f.static
+	}
+
+	if f != nil {
+		if slot, ok := f.slots[tabIndex]; ok {
+			return slot, nil
+		}
+	}
+
+	return nil, newRuntimeError("variable '%s' is not allocated", i.tab[tabIndex].Identifier)
+}
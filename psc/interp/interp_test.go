@@ -0,0 +1,175 @@
+package interp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/compiler"
+)
+
+const rules = "../../config/tokenizer_m3.json"
+
+// run analyzes src and runs it on the interpreter, returning what it wrote
+// and the error that stopped it.
+func run(t *testing.T, src string) (string, error) {
+	t.Helper()
+
+	res, err := compiler.Compile([]byte(src), compiler.Options{Rules: rules, Path: "test.pas"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %v", res.Diagnostics())
+	}
+
+	var out strings.Builder
+	err = res.Run(&out, compiler.RunOptions{Engine: compiler.ENGINE_INTERP})
+	return out.String(), err
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+		err  string // part of the runtime error, empty when the program ends normally
+	}{
+		{
+			name: "array parameters",
+			src: `program AP;
+variabel xs: larik[1..3] dari integer;
+prosedur Isi(variabel a: larik[1..3] dari integer; n: integer);
+mulai
+  a[1] := n;
+  a[2] := n * 2;
+  a[3] := a[1] + a[2]
+selesai;
+fungsi Jumlah(a: larik[1..3] dari integer): integer;
+mulai
+  a[1] := 0;
+  Jumlah := a[1] + a[2] + a[3]
+selesai;
+mulai
+  Isi(xs, 4);
+  jika (Jumlah(xs) = 20) dan (xs[1] = 4) maka
+    write('ok', '..')
+  selain_itu
+    write('salah', '..')
+selesai.
+`,
+			want: "ok..",
+		},
+		{
+			name: "recursion",
+			src: `program R;
+fungsi Faktorial(n: integer): integer;
+mulai
+  jika n <= 1 maka
+    Faktorial := 1
+  selain_itu
+    Faktorial := n * Faktorial(n - 1)
+selesai;
+mulai
+  jika Faktorial(5) = 120 maka
+    write('ok', '..')
+  selain_itu
+    write('salah', '..')
+selesai.
+`,
+			want: "ok..",
+		},
+		{
+			name: "var parameters",
+			src: `program V;
+variabel x, y: integer;
+prosedur Tukar(variabel a, b: integer);
+variabel c: integer;
+mulai
+  c := a;
+  a := b;
+  b := c
+selesai;
+mulai
+  x := 1;
+  y := 2;
+  Tukar(x, y);
+  jika (x = 2) dan (y = 1) maka
+    write('ok', '..')
+  selain_itu
+    write('salah', '..')
+selesai.
+`,
+			want: "ok..",
+		},
+		{
+			name: "nested call to the enclosing procedure",
+			src: `program Disp;
+variabel n: integer;
+prosedur P(a, b: integer);
+  prosedur Q;
+    prosedur R(k: integer);
+    mulai
+      jika k > 0 maka P(k - 1, b);
+    selesai;
+  mulai
+    R(a);
+  selesai;
+mulai
+  n := n + b;
+  Q;
+selesai;
+mulai
+  n := 0;
+  P(2, 5);
+  jika n = 15 maka
+    write('ok', '..')
+  selain_itu
+    write('salah', '..')
+selesai.
+`,
+			want: "ok..",
+		},
+		{
+			name: "leading sign",
+			src: `program M;
+variabel a: integer;
+mulai
+  a := -5 - 1;
+  jika a = -6 maka
+    write('ok', '..')
+  selain_itu
+    write('salah', '..')
+selesai.
+`,
+			want: "ok..",
+		},
+		{
+			name: "division by zero",
+			src: `program Z;
+variabel a, b: integer;
+mulai
+  write('mulai', '..');
+  b := 0;
+  a := 1 bagi b;
+  write('salah', '..')
+selesai.
+`,
+			want: "mulai..",
+			err:  "division by zero",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := run(t, tt.src)
+
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: got error %v, want one saying %q", tt.name, err, tt.err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: wrote %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package interp
+
+import (
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+func (i *Interpreter) execStatement(node *dt.DecoratedSyntaxTree) error {
+	switch node.SelfType {
+	case dt.DST_BLOCK:
+		for j := range node.Children {
+			if err := i.execStatement(&node.Children[j]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case dt.DST_ASSIGNMENT_OPERATOR:
+		return i.execAssignment(node)
+	case dt.DST_IF_BLOCK:
+		return i.execIf(node)
+	case dt.DST_WHILE_BLOCK:
+		return i.execWhile(node)
+	case dt.DST_REPEAT_BLOCK:
+		return i.execRepeat(node)
+	case dt.DST_FOR_BLOCK:
+		return i.execFor(node)
+	case dt.DST_CASE_BLOCK:
+		return i.execCase(node)
+	case dt.DST_PROCEDURE_CALL, dt.DST_FUNCTION_CALL, dt.DST_BUILTIN_CALL:
+		_, err := i.call(node)
+		return err
+	default:
+		return newRuntimeError("cannot execute %s", node.SelfType)
+	}
+}
+
+func (i *Interpreter) execAssignment(node *dt.DecoratedSyntaxTree) error {
+	loc, err := i.location(&node.Children[0])
+	if err != nil {
+		return err
+	}
+
+	v, err := i.eval(&node.Children[1])
+	if err != nil {
+		return err
+	}
+
+	assign(loc, v)
+	return nil
+}
+
+func (i *Interpreter) evalCondition(node *dt.DecoratedSyntaxTree) (bool, error) {
+	v, err := i.eval(node)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := v.(bool)
+	if !ok {
+		return false, newRuntimeError("condition is not boolean")
+	}
+
+	return b, nil
+}
+
+func (i *Interpreter) execIf(node *dt.DecoratedSyntaxTree) error {
+	cond, err := i.evalCondition(&node.Children[0])
+	if err != nil {
+		return err
+	}
+
+	if cond {
+		return i.execStatement(&node.Children[1])
+	}
+
+	if len(node.Children) > 2 {
+		return i.execStatement(&node.Children[2])
+	}
+
+	return nil
+}
+
+func (i *Interpreter) execWhile(node *dt.DecoratedSyntaxTree) error {
+	for {
+		cond, err := i.evalCondition(&node.Children[0])
+		if err != nil {
+			return err
+		}
+
+		if !cond {
+			return nil
+		}
+
+		if err := i.execStatement(&node.Children[1]); err != nil {
+			return err
+		}
+	}
+}
+
+func (i *Interpreter) execRepeat(node *dt.DecoratedSyntaxTree) error {
+	for {
+		if err := i.execStatement(&node.Children[0]); err != nil {
+			return err
+		}
+
+		cond, err := i.evalCondition(&node.Children[1])
+		if err != nil {
+			return err
+		}
+
+		if cond {
+			return nil
+		}
+	}
+}
+
+// execFor evaluates both bounds once before the first iteration, as in
+// Pascal, and works on any ordinal counter.
+func (i *Interpreter) execFor(node *dt.DecoratedSyntaxTree) error {
+	counter, err := i.location(&node.Children[0])
+	if err != nil {
+		return err
+	}
+
+	initial, err := i.eval(&node.Children[1])
+	if err != nil {
+		return err
+	}
+
+	final, err := i.eval(&node.Children[2])
+	if err != nil {
+		return err
+	}
+
+	from, err := ordinal(initial)
+	if err != nil {
+		return err
+	}
+
+	to, err := ordinal(final)
+	if err != nil {
+		return err
+	}
+
+	step := 1
+	if node.Children[2].Property == dt.DST_DOWNTO {
+		step = -1
+	}
+
+	for n := from; (step > 0 && n <= to) || (step < 0 && n >= to); n += step {
+		*counter = fromOrdinal(n, initial)
+
+		if err := i.execStatement(&node.Children[3]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// execCase runs the first arm with a label matching the selector, else the
+// else branch if there is one. Labels are literals, so they are compared as
+// ordinals without evaluating them.
+func (i *Interpreter) execCase(node *dt.DecoratedSyntaxTree) error {
+	v, err := i.eval(&node.Children[0])
+	if err != nil {
+		return err
+	}
+
+	n, err := ordinal(v)
+	if err != nil {
+		return err
+	}
+
+	for j := 1; j < len(node.Children); j++ {
+		child := &node.Children[j]
+
+		if child.Property == dt.DST_ELSE {
+			return i.execStatement(child)
+		}
+
+		last := len(child.Children) - 1
+		for _, label := range child.Children[:last] {
+			low, high := label.Data, label.Data
+			if label.SelfType == dt.DST_CASE_RANGE {
+				low, high = label.Children[0].Data, label.Children[1].Data
+			}
+
+			if low <= n && n <= high {
+				return i.execStatement(&child.Children[last])
+			}
+		}
+	}
+
+	return nil
+}
+
+func ordinal(v Value) (int, error) {
+	switch v := v.(type) {
+	case int:
+		return v, nil
+	case rune:
+		return int(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		return 0, newRuntimeError("value of type %T is not ordinal", v)
+	}
+}
+
+func fromOrdinal(n int, like Value) Value {
+	switch like.(type) {
+	case rune:
+		return rune(n)
+	case bool:
+		return n != 0
+	default:
+		return n
+	}
+}
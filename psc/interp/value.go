@@ -0,0 +1,164 @@
+package interp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// Value is a runtime value. It holds one of int, float64, bool, rune,
+// *Array or *Record.
+type Value any
+
+type Array struct {
+	Low      int
+	Elements []Value
+}
+
+// Record fields are keyed by the tab index of the field entry.
+type Record struct {
+	Fields map[int]*Value
+}
+
+func (i *Interpreter) resolveType(typ dt.TabEntryType, ref int) (dt.TabEntryType, int) {
+	for typ == dt.TAB_ENTRY_ALIAS {
+		typ, ref = i.tab[ref].Type, i.tab[ref].Reference
+	}
+	return typ, ref
+}
+
+func (i *Interpreter) zeroValue(typ dt.TabEntryType, ref int) Value {
+	typ, ref = i.resolveType(typ, ref)
+
+	switch typ {
+	case dt.TAB_ENTRY_INTEGER:
+		return 0
+	case dt.TAB_ENTRY_REAL:
+		return 0.0
+	case dt.TAB_ENTRY_BOOLEAN:
+		return false
+	case dt.TAB_ENTRY_CHAR:
+		return rune(0)
+	case dt.TAB_ENTRY_ARRAY:
+		entry := i.atab[ref]
+		arr := &Array{
+			Low:      entry.LowBound,
+			Elements: make([]Value, max(entry.HighBound-entry.LowBound+1, 0)),
+		}
+		for j := range arr.Elements {
+			arr.Elements[j] = i.zeroValue(entry.ElementType, entry.ElementReference)
+		}
+		return arr
+	case dt.TAB_ENTRY_RECORD:
+		entry := i.btab[ref]
+		rec := &Record{Fields: make(map[int]*Value)}
+		for j := entry.Start; j <= entry.End && j < len(i.tab); j++ {
+			if i.tab[j].Object != dt.TAB_ENTRY_FIELD {
+				continue
+			}
+			v := i.zeroValue(i.tab[j].Type, i.tab[j].Reference)
+			rec.Fields[j] = &v
+		}
+		return rec
+	default:
+		return nil
+	}
+}
+
+func copyValue(v Value) Value {
+	switch v := v.(type) {
+	case *Array:
+		arr := &Array{Low: v.Low, Elements: make([]Value, len(v.Elements))}
+		for j, e := range v.Elements {
+			arr.Elements[j] = copyValue(e)
+		}
+		return arr
+	case *Record:
+		rec := &Record{Fields: make(map[int]*Value, len(v.Fields))}
+		for k, f := range v.Fields {
+			c := copyValue(*f)
+			rec.Fields[k] = &c
+		}
+		return rec
+	default:
+		return v
+	}
+}
+
+// assign stores v into loc with Pascal value semantics. Arrays keep the shape
+// of the target, so a short string literal fills a string variable and the
+// remaining characters are cleared.
+func assign(loc *Value, v Value) {
+	dst, ok := (*loc).(*Array)
+	src, ok2 := v.(*Array)
+
+	if !ok || !ok2 {
+		*loc = copyValue(v)
+		return
+	}
+
+	for j := range dst.Elements {
+		if j < len(src.Elements) {
+			assign(&dst.Elements[j], src.Elements[j])
+		} else if _, isChar := dst.Elements[j].(rune); isChar {
+			dst.Elements[j] = rune(0)
+		}
+	}
+}
+
+func stringValue(s string) *Array {
+	arr := &Array{Low: 0}
+	for _, r := range s {
+		arr.Elements = append(arr.Elements, r)
+	}
+	return arr
+}
+
+// isCharArray reports whether v can be printed and compared as a string.
+func isCharArray(v Value) bool {
+	arr, ok := v.(*Array)
+	if !ok {
+		return false
+	}
+	for _, e := range arr.Elements {
+		if _, ok := e.(rune); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func charArrayString(arr *Array) string {
+	var sb strings.Builder
+	for _, e := range arr.Elements {
+		r := e.(rune)
+		if r == 0 {
+			break
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func formatValue(v Value) (string, error) {
+	switch v := v.(type) {
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	case rune:
+		return string(v), nil
+	case *Array:
+		if isCharArray(v) {
+			return charArrayString(v), nil
+		}
+	}
+	return "", fmt.Errorf("cannot write value of type %T", v)
+}
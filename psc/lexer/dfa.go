@@ -1,11 +1,30 @@
 package lexer
 
+import "sort"
+
 type State int
+
+// Span sends every rune from Lo to Hi, inclusive, to To.
+type Span struct {
+	Lo rune
+	Hi rune
+	To State
+}
+
 type DFA struct {
+	// States is the state count the JSON declares, 0 when unknown.
+	States int
 	Start  State
 	Finals map[State]string
 	Trans  map[State]map[rune]State
-	curr   State
+	// Spans holds the range and class transitions of each state, sorted by
+	// Lo and disjoint. A rune in Trans takes precedence over them.
+	Spans map[State][]Span
+	// Default is the "otherwise" transition, taken when neither Trans nor
+	// Spans has the rune.
+	Default map[State]State
+	curr    State
+	tab     *table
 }
 
 func (d *DFA) Reset() {
@@ -17,19 +36,45 @@ func (d *DFA) State() State {
 }
 
 func (d *DFA) IsFinal(s State) (string, bool) {
+	if t := d.tab; t != nil && s >= 0 && int(s) < t.states {
+		return t.label[s], t.final[s]
+	}
 	lab, ok := d.Finals[s]
 	return lab, ok
 }
 
 func (d *DFA) Step(r rune) (State, bool) { // Buat ngecek next statenya apa
-	next, ok := d.Trans[d.curr][r]
-	return next, ok
+	return d.next(d.curr, r)
 }
 
 func (d *DFA) Advance(r rune) bool { // Pindah State
-	if next, ok := d.Trans[d.curr][r]; ok {
+	if next, ok := d.next(d.curr, r); ok {
 		d.curr = next
 		return true
 	}
 	return false
 }
+
+func (d *DFA) next(s State, r rune) (State, bool) {
+	if t := d.tab; t != nil && r >= 0 && r < asciiLimit && s >= 0 && int(s) < t.states {
+		next := t.next[int(s)*t.classes+int(t.class[r])]
+		return State(next), next >= 0
+	}
+	return d.slowNext(s, r)
+}
+
+func (d *DFA) slowNext(s State, r rune) (State, bool) {
+	if next, ok := d.Trans[s][r]; ok {
+		return next, true
+	}
+
+	if spans := d.Spans[s]; len(spans) > 0 {
+		i := sort.Search(len(spans), func(i int) bool { return spans[i].Hi >= r })
+		if i < len(spans) && spans[i].Lo <= r {
+			return spans[i].To, true
+		}
+	}
+
+	next, ok := d.Default[s]
+	return next, ok
+}
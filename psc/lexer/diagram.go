@@ -0,0 +1,245 @@
+package lexer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type DiagramOptions struct {
+	// From, when set, limits the diagram to the states reachable from it.
+	From *State
+	// Name is the graph name. Empty means "dfa".
+	Name string
+}
+
+// diagramEdge is every transition from one state to another, with its
+// runes merged into one label.
+type diagramEdge struct {
+	from  State
+	to    State
+	label string
+}
+
+// WriteDOT writes d as a Graphviz digraph. Parallel transitions share one
+// edge labeled with their class, and final states are drawn as filled double
+// circles showing their output.
+func WriteDOT(d *DFA, w io.Writer, opts DiagramOptions) error {
+	states, edges := diagram(d, opts)
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote(diagramName(opts)))
+	fmt.Fprintln(bw, "    rankdir=LR;")
+	fmt.Fprintln(bw, "    node [shape=circle];")
+
+	if slices.Contains(states, d.Start) {
+		fmt.Fprintln(bw, "    __start [shape=point];")
+		fmt.Fprintf(bw, "    __start -> %d;\n", d.Start)
+	}
+
+	for _, s := range states {
+		if lab, ok := d.Finals[s]; ok {
+			fmt.Fprintf(bw, "    %d [shape=doublecircle, style=filled, fillcolor=lightblue, label=%s];\n", s, dotQuote(fmt.Sprintf("%d\n%s", s, lab)))
+		}
+	}
+
+	for _, e := range edges {
+		fmt.Fprintf(bw, "    %d -> %d [label=%s];\n", e.from, e.to, dotQuote(e.label))
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteMermaid writes d as a Mermaid flowchart with the same grouping as
+// WriteDOT.
+func WriteMermaid(d *DFA, w io.Writer, opts DiagramOptions) error {
+	states, edges := diagram(d, opts)
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "---\ntitle: %s\n---\n", diagramName(opts))
+	fmt.Fprintln(bw, "flowchart LR")
+
+	if slices.Contains(states, d.Start) {
+		fmt.Fprintln(bw, "    start(( )) --> s"+strconv.Itoa(int(d.Start)))
+	}
+
+	for _, s := range states {
+		if lab, ok := d.Finals[s]; ok {
+			fmt.Fprintf(bw, "    s%d(((\"%d<br/>%s\")))\n", s, s, mermaidEscape(lab))
+		} else {
+			fmt.Fprintf(bw, "    s%d((\"%d\"))\n", s, s)
+		}
+	}
+
+	for _, e := range edges {
+		fmt.Fprintf(bw, "    s%d -->|\"%s\"| s%d\n", e.from, mermaidEscape(e.label), e.to)
+	}
+
+	finals := []string{}
+	for _, s := range states {
+		if _, ok := d.Finals[s]; ok {
+			finals = append(finals, "s"+strconv.Itoa(int(s)))
+		}
+	}
+	if len(finals) > 0 {
+		fmt.Fprintln(bw, "    classDef final fill:#add8e6")
+		fmt.Fprintf(bw, "    class %s final\n", strings.Join(finals, ","))
+	}
+
+	return bw.Flush()
+}
+
+func diagramName(opts DiagramOptions) string {
+	if opts.Name == "" {
+		return "dfa"
+	}
+	return opts.Name
+}
+
+// diagram lists the states to draw and their grouped edges, in order.
+func diagram(d *DFA, opts DiagramOptions) ([]State, []diagramEdge) {
+	var states []State
+	if opts.From != nil {
+		for s := range d.reachableFrom(*opts.From) {
+			states = append(states, s)
+		}
+		slices.Sort(states)
+	} else {
+		states = d.StateIDs()
+	}
+
+	var edges []diagramEdge
+	for _, from := range states {
+		byTarget := map[State][]Span{}
+		for r, to := range d.Trans[from] {
+			byTarget[to] = append(byTarget[to], Span{Lo: r, Hi: r, To: to})
+		}
+		for _, span := range d.Spans[from] {
+			byTarget[span.To] = append(byTarget[span.To], span)
+		}
+
+		targets := make([]State, 0, len(byTarget))
+		for to := range byTarget {
+			targets = append(targets, to)
+		}
+		slices.Sort(targets)
+
+		for _, to := range targets {
+			spans, _ := mergeSpans(byTarget[to])
+			edges = append(edges, diagramEdge{from, to, classLabel(spans)})
+		}
+
+		if to, ok := d.Default[from]; ok {
+			edges = append(edges, diagramEdge{from, to, otherwise})
+		}
+	}
+
+	return states, edges
+}
+
+// classLabel names a merged set of runes the way the JSON format would:
+// a named class, "any-except:..." for a set missing a few runes, or a list
+// of runes and ranges.
+func classLabel(spans []Span) string {
+	for _, name := range []string{"letter", "digit", "alnum", "upper", "lower", "any"} {
+		if spansEqual(spans, namedClasses[name]) {
+			return name
+		}
+	}
+
+	// A set starting at 0 and ending at the top of the byte range or of
+	// Unicode is shown by what it leaves out.
+	if len(spans) > 0 && spans[0].Lo == 0 {
+		top := spans[len(spans)-1].Hi
+		if top == 0xFF || top == unicode.MaxRune {
+			var missing []rune
+			for i := 1; i < len(spans) && len(missing) <= 4; i++ {
+				for r := spans[i-1].Hi + 1; r < spans[i].Lo && len(missing) <= 4; r++ {
+					missing = append(missing, r)
+				}
+			}
+			if len(missing) == 0 {
+				return "any"
+			}
+			if len(missing) <= 4 {
+				var sb strings.Builder
+				for _, r := range missing {
+					sb.WriteString(runeLabel(r))
+				}
+				return "any-except:" + sb.String()
+			}
+		}
+	}
+
+	parts := make([]string, 0, len(spans))
+	for _, s := range spans {
+		switch s.Hi - s.Lo {
+		case 0:
+			parts = append(parts, runeLabel(s.Lo))
+		case 1:
+			parts = append(parts, runeLabel(s.Lo), runeLabel(s.Hi))
+		default:
+			parts = append(parts, runeLabel(s.Lo)+"-"+runeLabel(s.Hi))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func spansEqual(a, b []Span) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Lo != b[i].Lo || a[i].Hi != b[i].Hi {
+			return false
+		}
+	}
+	return true
+}
+
+func runeLabel(r rune) string {
+	if r == ' ' {
+		return "' '"
+	}
+	if unicode.IsPrint(r) {
+		return string(r)
+	}
+	q := strconv.QuoteRune(r)
+	return q[1 : len(q)-1]
+}
+
+// reachableFrom marks the states reachable from start, start included.
+func (d *DFA) reachableFrom(start State) map[State]bool {
+	seen := map[State]bool{start: true}
+	queue := []State{start}
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+
+		for _, t := range d.successors(s) {
+			if !seen[t] {
+				seen[t] = true
+				queue = append(queue, t)
+			}
+		}
+	}
+	return seen
+}
+
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func mermaidEscape(s string) string {
+	r := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "#", "#35;")
+	return r.Replace(s)
+}
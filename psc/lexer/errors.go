@@ -0,0 +1,144 @@
+package lexer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	iox "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/common"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+type LexErrorKind int
+
+const (
+	LEX_INVALID_CHAR LexErrorKind = iota
+	LEX_UNTERMINATED_STRING
+	LEX_UNTERMINATED_COMMENT
+	LEX_MALFORMED_NUMBER
+	LEX_BAD_DIRECTIVE
+)
+
+func (k LexErrorKind) String() string {
+	names := [...]string{"invalid character", "unterminated string", "unterminated comment", "malformed number", "bad directive"}
+	if int(k) < 0 || int(k) >= len(names) {
+		return "lexical error"
+	}
+	return names[k]
+}
+
+// LexError is a stretch of source no token matches. Lexing resumes after
+// Span, so one mistake gives one error.
+type LexError struct {
+	Kind LexErrorKind
+	Span datatype.Span
+	Text string // the source covered by Span
+	Fix  string // what would likely make it lex
+	// File names the included file the error is in. It is empty for the
+	// file being compiled.
+	File string
+}
+
+func (e *LexError) Error() string {
+	where := fmt.Sprintf("%d:%d", e.Span.Start.Line, e.Span.Start.Col)
+	if e.File != "" {
+		where = e.File + ":" + where
+	}
+
+	msg := fmt.Sprintf("%s %q at %s", e.Kind, excerpt(e.Text), where)
+	if e.Fix != "" {
+		msg += "; " + e.Fix
+	}
+	return msg
+}
+
+// excerpt shortens text that runs to the end of a line or of the file to
+// something that fits in a message.
+func excerpt(text string) string {
+	const limit = 24
+
+	if i := strings.IndexAny(text, "\r\n"); i >= 0 {
+		text = text[:i] + "..."
+	}
+	if r := []rune(text); len(r) > limit {
+		text = string(r[:limit]) + "..."
+	}
+	return text
+}
+
+// newLexError builds the error for the source read since start.
+func (lx *Lexer) newLexError(kind LexErrorKind, start iox.Snapshot, fix string) *LexError {
+	line, col := start.Pos()
+	endLine, endCol := lx.r.Pos()
+	return &LexError{
+		Kind: kind,
+		Span: datatype.Span{
+			Start: datatype.Pos{Offset: start.Offset(), Line: line, Col: col},
+			End:   datatype.Pos{Offset: lx.r.Offset(), Line: endLine, Col: endCol},
+		},
+		Text: lx.r.Slice(start.Offset(), lx.r.Offset()),
+		Fix:  fix,
+	}
+}
+
+// recover explains why no token starts at start and skips past the
+// mistake. An opening quote or brace that was never closed swallows what
+// it would have, so nothing inside is lexed as code; any other run of runes
+// no token can start with is reported as one invalid character error.
+func (lx *Lexer) recover(start iox.Snapshot) *LexError {
+	lx.r.Restore(start)
+	first, _ := lx.r.Read()
+
+	switch first {
+	case '\'':
+		for !lx.r.EOF() && lx.r.Peek() != '\n' && lx.r.Peek() != '\r' {
+			lx.r.Read()
+		}
+		return lx.newLexError(LEX_UNTERMINATED_STRING, start, "close it with ' before the end of the line")
+	case '{':
+		return lx.unterminatedComment(start, "}")
+	}
+
+	for !lx.r.EOF() {
+		ch := lx.r.Peek()
+		lx.d.Reset()
+		if isWS(ch) || lx.d.Advance(ch) {
+			break
+		}
+		lx.r.Read()
+	}
+	lx.d.Reset()
+
+	return lx.newLexError(LEX_INVALID_CHAR, start, "remove it, or put it inside a string or a comment")
+}
+
+func (lx *Lexer) unterminatedComment(start iox.Snapshot, closer string) *LexError {
+	lx.r.Restore(start)
+	for !lx.r.EOF() {
+		lx.r.Read()
+	}
+	return lx.newLexError(LEX_UNTERMINATED_COMMENT, start, "close it with "+closer)
+}
+
+func isNameRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// checkNumber reports a number that runs straight into letters, such as
+// 12abc or an exponent without digits like 1e, and skips all of it.
+func (lx *Lexer) checkNumber(start iox.Snapshot) *LexError {
+	if lx.r.EOF() || !isNameRune(lx.r.Peek()) {
+		return nil
+	}
+
+	exponent := lx.r.Peek() == 'e' || lx.r.Peek() == 'E'
+	for !lx.r.EOF() && isNameRune(lx.r.Peek()) {
+		lx.r.Read()
+	}
+
+	fix := "separate the number from the name after it"
+	if exponent {
+		fix = "give the exponent digits, as in 1e5, or separate the number from the name after it"
+	}
+	return lx.newLexError(LEX_MALFORMED_NUMBER, start, fix)
+}
@@ -0,0 +1,335 @@
+package gen
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/lexer"
+)
+
+// DFA is a minimized automaton over rune ranges. State 0 is the start state
+// and the others are numbered in breadth-first order from it, so compiling
+// the same rules always gives the same automaton.
+type DFA struct {
+	// Accept is the token class of each state, "" when it is not final.
+	Accept []string
+	// Edges lists the transitions of each state, sorted and disjoint.
+	Edges [][]Edge
+}
+
+type Edge struct {
+	Lo rune
+	Hi rune
+	To int
+}
+
+// Compile builds the lexer automaton for rules: a Thompson NFA for their
+// union, subset construction over the ranges the rules mention, and
+// minimization.
+func Compile(rules []Rule) (*DFA, error) {
+	n := &nfa{}
+	start := n.add()
+
+	for i, rule := range rules {
+		tree, err := parseRegex(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("line %d (%s): %w", rule.Line, rule.Name, err)
+		}
+
+		s, e := n.build(tree)
+		n.epsilon(start, s)
+		n.states[e].accept = i
+	}
+
+	classes := alphabet(n)
+	accept, trans := determinize(n, start, rules, classes)
+	accept, trans = minimize(accept, trans)
+
+	return canonical(accept, trans, classes), nil
+}
+
+// alphabet splits the runes the NFA reads into classes that no edge tells
+// apart. Class i covers bounds[i] up to bounds[i+1]-1.
+func alphabet(n *nfa) []rune {
+	seen := map[rune]bool{}
+	for _, s := range n.states {
+		for _, e := range s.edges {
+			for _, r := range e.set {
+				seen[r.lo] = true
+				seen[r.hi+1] = true
+			}
+		}
+	}
+
+	bounds := make([]rune, 0, len(seen))
+	for r := range seen {
+		bounds = append(bounds, r)
+	}
+	slices.Sort(bounds)
+	return bounds
+}
+
+// determinize runs the subset construction. trans[s][c] is the successor of
+// s on class c, or -1.
+func determinize(n *nfa, start int, rules []Rule, bounds []rune) ([]string, [][]int) {
+	classCount := max(len(bounds)-1, 0)
+
+	var accept []string
+	var trans [][]int
+	var sets [][]int
+	index := map[string]int{}
+
+	key := func(set []int) string {
+		var sb strings.Builder
+		for _, s := range set {
+			sb.WriteString(strconv.Itoa(s))
+			sb.WriteByte(',')
+		}
+		return sb.String()
+	}
+
+	addState := func(set []int) int {
+		k := key(set)
+		if i, ok := index[k]; ok {
+			return i
+		}
+
+		best := -1
+		for _, s := range set {
+			r := n.states[s].accept
+			if r < 0 {
+				continue
+			}
+			if best < 0 || rules[r].Priority > rules[best].Priority || (rules[r].Priority == rules[best].Priority && r < best) {
+				best = r
+			}
+		}
+
+		label := ""
+		if best >= 0 {
+			label = rules[best].Name
+		}
+
+		index[k] = len(sets)
+		sets = append(sets, set)
+		accept = append(accept, label)
+		return len(sets) - 1
+	}
+
+	addState(n.closure([]int{start}))
+
+	for i := 0; i < len(sets); i++ {
+		moves := make([][]int, classCount)
+
+		for _, s := range sets[i] {
+			for _, e := range n.states[s].edges {
+				for _, r := range e.set {
+					first, _ := slices.BinarySearch(bounds, r.lo)
+					last, _ := slices.BinarySearch(bounds, r.hi+1)
+					for c := first; c < last; c++ {
+						moves[c] = append(moves[c], e.to)
+					}
+				}
+			}
+		}
+
+		row := make([]int, classCount)
+		for c, targets := range moves {
+			if len(targets) == 0 {
+				row[c] = -1
+				continue
+			}
+			row[c] = addState(n.closure(targets))
+		}
+		trans = append(trans, row)
+	}
+
+	return accept, trans
+}
+
+// minimize drops states that cannot reach a final state and merges
+// equivalent ones by refining the partition by token class until it is
+// stable. State 0 stays the start state.
+func minimize(accept []string, trans [][]int) ([]string, [][]int) {
+	live := make([]bool, len(accept))
+	for changed := true; changed; {
+		changed = false
+		for s := range accept {
+			if live[s] {
+				continue
+			}
+			if accept[s] != "" || slices.ContainsFunc(trans[s], func(t int) bool { return t >= 0 && live[t] }) {
+				live[s] = true
+				changed = true
+			}
+		}
+	}
+
+	block := make([]int, len(accept))
+	labels := map[string]int{}
+	for s, label := range accept {
+		if _, ok := labels[label]; !ok {
+			labels[label] = len(labels)
+		}
+		block[s] = labels[label]
+	}
+
+	target := func(t int) int {
+		if t < 0 || !live[t] {
+			return -1
+		}
+		return block[t]
+	}
+
+	for {
+		signatures := map[string]int{}
+		next := make([]int, len(accept))
+
+		for s := range accept {
+			var sb strings.Builder
+			sb.WriteString(strconv.Itoa(block[s]))
+			for _, t := range trans[s] {
+				sb.WriteByte(',')
+				sb.WriteString(strconv.Itoa(target(t)))
+			}
+
+			k := sb.String()
+			if _, ok := signatures[k]; !ok {
+				signatures[k] = len(signatures)
+			}
+			next[s] = signatures[k]
+		}
+
+		stable := len(signatures) == len(labels)
+		block = next
+		if stable {
+			break
+		}
+		labels = make(map[string]int, len(signatures))
+		for k, v := range signatures {
+			labels[k] = v
+		}
+	}
+
+	count := 0
+	for _, b := range block {
+		count = max(count, b+1)
+	}
+
+	// Renumber so the start state's block comes first.
+	order := make([]int, count)
+	for i := range order {
+		order[i] = -1
+	}
+	order[block[0]] = 0
+	nextID := 1
+	for _, b := range block {
+		if order[b] < 0 {
+			order[b] = nextID
+			nextID++
+		}
+	}
+
+	minAccept := make([]string, count)
+	minTrans := make([][]int, count)
+	for s := range accept {
+		id := order[block[s]]
+		if minTrans[id] != nil {
+			continue
+		}
+		minAccept[id] = accept[s]
+		row := make([]int, len(trans[s]))
+		for c, t := range trans[s] {
+			row[c] = -1
+			if t >= 0 && live[t] {
+				row[c] = order[block[t]]
+			}
+		}
+		minTrans[id] = row
+	}
+
+	return minAccept, minTrans
+}
+
+// canonical numbers the live states breadth-first from the start and turns
+// class transitions back into rune ranges.
+func canonical(accept []string, trans [][]int, bounds []rune) *DFA {
+	id := map[int]int{0: 0}
+	queue := []int{0}
+	d := &DFA{}
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+
+		var edges []Edge
+		for c, t := range trans[s] {
+			if t < 0 {
+				continue
+			}
+
+			to, ok := id[t]
+			if !ok {
+				to = len(id)
+				id[t] = to
+				queue = append(queue, t)
+			}
+
+			lo, hi := bounds[c], bounds[c+1]-1
+			if n := len(edges); n > 0 && edges[n-1].To == to && edges[n-1].Hi+1 == lo {
+				edges[n-1].Hi = hi
+				continue
+			}
+			edges = append(edges, Edge{Lo: lo, Hi: hi, To: to})
+		}
+
+		d.Accept = append(d.Accept, accept[s])
+		d.Edges = append(d.Edges, edges)
+	}
+
+	return d
+}
+
+// Lexer converts d to the representation the lexer runs. Single runes go
+// to Trans and wider edges to Spans.
+func (d *DFA) Lexer() *lexer.DFA {
+	out := &lexer.DFA{
+		Start:   0,
+		Finals:  make(map[lexer.State]string),
+		Trans:   make(map[lexer.State]map[rune]lexer.State),
+		Spans:   make(map[lexer.State][]lexer.Span),
+		Default: make(map[lexer.State]lexer.State),
+	}
+
+	for s, label := range d.Accept {
+		if label != "" {
+			out.Finals[lexer.State(s)] = label
+		}
+	}
+
+	for s, edges := range d.Edges {
+		from := lexer.State(s)
+
+		for _, e := range edges {
+			if e.Lo != e.Hi {
+				out.Spans[from] = append(out.Spans[from], lexer.Span{Lo: e.Lo, Hi: e.Hi, To: lexer.State(e.To)})
+				continue
+			}
+
+			if out.Trans[from] == nil {
+				out.Trans[from] = make(map[rune]lexer.State)
+			}
+			out.Trans[from][e.Lo] = lexer.State(e.To)
+		}
+	}
+
+	out.Compile()
+	out.Reset()
+	return out
+}
+
+func sortInts(s []int) {
+	slices.Sort(s)
+}
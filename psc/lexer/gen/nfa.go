@@ -0,0 +1,107 @@
+package gen
+
+// nfa is a Thompson automaton. Every state has either charset edges or
+// epsilon edges, and accept holds the index of the rule a state finishes,
+// or -1.
+type nfa struct {
+	states []nfaState
+}
+
+type nfaEdge struct {
+	set charset
+	to  int
+}
+
+type nfaState struct {
+	edges  []nfaEdge
+	eps    []int
+	accept int
+}
+
+func (n *nfa) add() int {
+	n.states = append(n.states, nfaState{accept: -1})
+	return len(n.states) - 1
+}
+
+func (n *nfa) epsilon(from, to int) {
+	n.states[from].eps = append(n.states[from].eps, to)
+}
+
+// build adds the fragment for x and returns its entry and exit states.
+func (n *nfa) build(x *node) (int, int) {
+	switch x.kind {
+	case nodeSet:
+		start, end := n.add(), n.add()
+		n.states[start].edges = append(n.states[start].edges, nfaEdge{set: x.set, to: end})
+		return start, end
+
+	case nodeConcat:
+		start, end := n.build(x.children[0])
+		for _, child := range x.children[1:] {
+			s, e := n.build(child)
+			n.epsilon(end, s)
+			end = e
+		}
+		return start, end
+
+	case nodeAlternate:
+		start, end := n.add(), n.add()
+		for _, child := range x.children {
+			s, e := n.build(child)
+			n.epsilon(start, s)
+			n.epsilon(e, end)
+		}
+		return start, end
+
+	case nodeStar, nodePlus, nodeOptional:
+		start, end := n.add(), n.add()
+		s, e := n.build(x.children[0])
+		n.epsilon(start, s)
+		n.epsilon(e, end)
+		if x.kind != nodePlus {
+			n.epsilon(start, end)
+		}
+		if x.kind != nodeOptional {
+			n.epsilon(e, s)
+		}
+		return start, end
+
+	default:
+		start, end := n.add(), n.add()
+		n.epsilon(start, end)
+		return start, end
+	}
+}
+
+// closure extends set, given as a sorted list of states, with everything
+// reachable through epsilon edges. The result is sorted.
+func (n *nfa) closure(set []int) []int {
+	seen := make(map[int]bool, len(set))
+	stack := make([]int, 0, len(set))
+
+	for _, s := range set {
+		if !seen[s] {
+			seen[s] = true
+			stack = append(stack, s)
+		}
+	}
+
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, t := range n.states[s].eps {
+			if !seen[t] {
+				seen[t] = true
+				stack = append(stack, t)
+			}
+		}
+	}
+
+	out := make([]int, 0, len(seen))
+	for s := range seen {
+		out = append(out, s)
+	}
+	sortInts(out)
+	return out
+}
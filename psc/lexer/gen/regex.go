@@ -0,0 +1,352 @@
+package gen
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"unicode"
+)
+
+// MaxRune bounds the alphabet. '.' and negated classes are taken over
+// 0..MaxRune, the range the JSON configs have always spelled out.
+const MaxRune rune = 0xFF
+
+type runeRange struct {
+	lo rune
+	hi rune
+}
+
+// charset is a sorted list of disjoint, non-adjacent ranges.
+type charset []runeRange
+
+func newCharset(ranges ...runeRange) charset {
+	c := slices.Clone(ranges)
+	slices.SortFunc(c, func(a, b runeRange) int { return int(a.lo - b.lo) })
+
+	merged := charset{}
+	for _, r := range c {
+		if n := len(merged); n > 0 && r.lo <= merged[n-1].hi+1 {
+			merged[n-1].hi = max(merged[n-1].hi, r.hi)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func (c charset) negate() charset {
+	out := charset{}
+	next := rune(0)
+	for _, r := range c {
+		if r.lo > next {
+			out = append(out, runeRange{next, r.lo - 1})
+		}
+		next = r.hi + 1
+	}
+	if next <= MaxRune {
+		out = append(out, runeRange{next, MaxRune})
+	}
+	return out
+}
+
+// foldCase adds the other case of every letter in c.
+func (c charset) foldCase() charset {
+	ranges := slices.Clone(c)
+	for _, r := range c {
+		for ch := r.lo; ch <= r.hi; ch++ {
+			for f := unicode.SimpleFold(ch); f != ch; f = unicode.SimpleFold(f) {
+				if f <= MaxRune {
+					ranges = append(ranges, runeRange{f, f})
+				}
+			}
+		}
+	}
+	return newCharset(ranges...)
+}
+
+type nodeKind int
+
+const (
+	nodeEmpty nodeKind = iota
+	nodeSet
+	nodeConcat
+	nodeAlternate
+	nodeStar
+	nodePlus
+	nodeOptional
+)
+
+type node struct {
+	kind     nodeKind
+	set      charset
+	children []*node
+}
+
+type regexParser struct {
+	src  []rune
+	pos  int
+	fold bool
+}
+
+// parseRegex parses the subset of regular expressions rule files use:
+// literals, escapes (\n \t \r \f \0 \xHH \d \w \s and escaped punctuation),
+// '.', bracket classes with ranges and '^', groups, '|', '*', '+' and '?'.
+// A leading (?i) makes every letter match both cases.
+func parseRegex(pattern string) (*node, error) {
+	p := &regexParser{src: []rune(pattern)}
+
+	if len(p.src) >= 4 && string(p.src[:4]) == "(?i)" {
+		p.fold = true
+		p.pos = 4
+	}
+
+	n, err := p.parseAlternate()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	return n, nil
+}
+
+func (p *regexParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("regex at %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *regexParser) peek() (rune, bool) {
+	if p.pos < len(p.src) {
+		return p.src[p.pos], true
+	}
+	return 0, false
+}
+
+func (p *regexParser) parseAlternate() (*node, error) {
+	first, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+
+	alternatives := []*node{first}
+	for {
+		if r, ok := p.peek(); !ok || r != '|' {
+			break
+		}
+		p.pos++
+
+		next, err := p.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, next)
+	}
+
+	if len(alternatives) == 1 {
+		return first, nil
+	}
+	return &node{kind: nodeAlternate, children: alternatives}, nil
+}
+
+func (p *regexParser) parseConcat() (*node, error) {
+	var items []*node
+
+	for {
+		r, ok := p.peek()
+		if !ok || r == '|' || r == ')' {
+			break
+		}
+
+		item, err := p.parseRepeat()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	switch len(items) {
+	case 0:
+		return &node{kind: nodeEmpty}, nil
+	case 1:
+		return items[0], nil
+	default:
+		return &node{kind: nodeConcat, children: items}, nil
+	}
+}
+
+func (p *regexParser) parseRepeat() (*node, error) {
+	atom, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		r, ok := p.peek()
+		if !ok {
+			return atom, nil
+		}
+
+		switch r {
+		case '*':
+			atom = &node{kind: nodeStar, children: []*node{atom}}
+		case '+':
+			atom = &node{kind: nodePlus, children: []*node{atom}}
+		case '?':
+			atom = &node{kind: nodeOptional, children: []*node{atom}}
+		default:
+			return atom, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *regexParser) parseAtom() (*node, error) {
+	r, _ := p.peek()
+	p.pos++
+
+	var set charset
+
+	switch r {
+	case '(':
+		inner, err := p.parseAlternate()
+		if err != nil {
+			return nil, err
+		}
+		if r, ok := p.peek(); !ok || r != ')' {
+			return nil, p.errorf("missing ')'")
+		}
+		p.pos++
+		return inner, nil
+	case '*', '+', '?':
+		p.pos--
+		return nil, p.errorf("%q has nothing to repeat", r)
+	case '[':
+		var err error
+		if set, err = p.parseClass(); err != nil {
+			return nil, err
+		}
+	case '.':
+		set = charset{{0, MaxRune}}
+	case '\\':
+		var err error
+		if set, err = p.parseEscape(); err != nil {
+			return nil, err
+		}
+	default:
+		set = charset{{r, r}}
+	}
+
+	if p.fold {
+		set = set.foldCase()
+	}
+	return &node{kind: nodeSet, set: set}, nil
+}
+
+func (p *regexParser) parseClass() (charset, error) {
+	negated := false
+	if r, ok := p.peek(); ok && r == '^' {
+		negated = true
+		p.pos++
+	}
+
+	var ranges []runeRange
+	first := true
+
+	for {
+		r, ok := p.peek()
+		if !ok {
+			return nil, p.errorf("missing ']'")
+		}
+		if r == ']' && !first {
+			p.pos++
+			break
+		}
+		first = false
+
+		lo, err := p.classRune()
+		if err != nil {
+			return nil, err
+		}
+		if lo == nil {
+			continue
+		}
+
+		if r, ok := p.peek(); ok && r == '-' && p.pos+1 < len(p.src) && p.src[p.pos+1] != ']' {
+			p.pos++
+			hi, err := p.classRune()
+			if err != nil {
+				return nil, err
+			}
+			if hi == nil || len(lo) != 1 || len(hi) != 1 || lo[0].lo != lo[0].hi || hi[0].lo != hi[0].hi {
+				return nil, p.errorf("invalid range in class")
+			}
+			if hi[0].lo < lo[0].lo {
+				return nil, p.errorf("range %q-%q is reversed", lo[0].lo, hi[0].lo)
+			}
+			ranges = append(ranges, runeRange{lo[0].lo, hi[0].lo})
+			continue
+		}
+
+		ranges = append(ranges, lo...)
+	}
+
+	set := newCharset(ranges...)
+	if negated {
+		set = set.negate()
+	}
+	return set, nil
+}
+
+// classRune reads one member of a bracket class, which is a single rune or
+// an escape that may stand for several.
+func (p *regexParser) classRune() (charset, error) {
+	r := p.src[p.pos]
+	p.pos++
+
+	if r == '\\' {
+		return p.parseEscape()
+	}
+	return charset{{r, r}}, nil
+}
+
+func (p *regexParser) parseEscape() (charset, error) {
+	r, ok := p.peek()
+	if !ok {
+		return nil, p.errorf("trailing '\\'")
+	}
+	p.pos++
+
+	switch r {
+	case 'n':
+		return charset{{'\n', '\n'}}, nil
+	case 't':
+		return charset{{'\t', '\t'}}, nil
+	case 'r':
+		return charset{{'\r', '\r'}}, nil
+	case 'f':
+		return charset{{'\f', '\f'}}, nil
+	case '0':
+		return charset{{0, 0}}, nil
+	case 'd':
+		return charset{{'0', '9'}}, nil
+	case 'w':
+		return newCharset(runeRange{'0', '9'}, runeRange{'A', 'Z'}, runeRange{'_', '_'}, runeRange{'a', 'z'}), nil
+	case 's':
+		return newCharset(runeRange{0, 0}, runeRange{'\t', '\n'}, runeRange{'\f', '\r'}, runeRange{' ', ' '}), nil
+	case 'x':
+		if p.pos+2 > len(p.src) {
+			return nil, p.errorf("\\x needs two hex digits")
+		}
+		v, err := strconv.ParseUint(string(p.src[p.pos:p.pos+2]), 16, 8)
+		if err != nil {
+			return nil, p.errorf("\\x needs two hex digits")
+		}
+		p.pos += 2
+		return charset{{rune(v), rune(v)}}, nil
+	}
+
+	if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return nil, p.errorf("unknown escape \\%c", r)
+	}
+	return charset{{r, r}}, nil
+}
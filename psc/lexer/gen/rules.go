@@ -0,0 +1,81 @@
+package gen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Rule maps the lexemes matching Pattern to the token class Name. When
+// several rules match the same longest lexeme, the one with the highest
+// Priority wins, and among equal priorities the one listed first.
+type Rule struct {
+	Name     string
+	Priority int
+	Pattern  string
+	Line     int
+}
+
+// ParseRules reads a rule file. Each non-blank line that does not start with
+// '#' holds a token class, a priority and a regular expression, separated by
+// white space; the expression runs to the end of the line.
+func ParseRules(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		name, rest := cutField(text)
+		priority, pattern := cutField(rest)
+
+		if name == "" || pattern == "" {
+			return nil, fmt.Errorf("line %d: expected <class> <priority> <regex>", line)
+		}
+
+		p, err := strconv.Atoi(priority)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: priority %q is not an integer", line, priority)
+		}
+
+		rules = append(rules, Rule{Name: name, Priority: p, Pattern: pattern, Line: line})
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no rules")
+	}
+	return rules, nil
+}
+
+func LoadRules(path string) ([]Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules, err := ParseRules(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// cutField splits s at its first run of blanks.
+func cutField(s string) (string, string) {
+	i := strings.IndexAny(s, " \t")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimLeft(s[i:], " \t")
+}
@@ -1,7 +1,8 @@
 package lexer
 
 import (
-	"fmt"
+	"io"
+	"iter"
 	"strings"
 
 	iox "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/common"
@@ -9,23 +10,67 @@ import (
 )
 
 type Lexer struct {
-	d *DFA
-	r *iox.RuneReader
+	d      *DFA
+	r      *iox.RuneReader
+	tr     Tracer
+	failed bool // the reader's error has been returned
 }
 
 func New(d *DFA, r *iox.RuneReader) *Lexer {
 	return &Lexer{d: d, r: r}
 }
 
+// SetTracer makes ScanAll report every step to t. Nil turns tracing off.
+func (lx *Lexer) SetTracer(t Tracer) {
+	lx.tr = t
+}
+
 func isWS(r rune) bool {
 	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' || r == 0
 }
 
+// ScanAll lexes the rest of the input. Unrecognized runes are skipped and
+// reported, so every token is returned along with every error.
 func (lx *Lexer) ScanAll() ([]datatype.Token, []error) {
 	var toks []datatype.Token
 	var errs []error
 
-	for !lx.r.EOF() {
+	for tok, err := range lx.All() {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		toks = append(toks, tok)
+	}
+
+	return toks, errs
+}
+
+// All yields the tokens Next returns until the input ends. An unrecognized
+// rune is yielded as an error and lexing goes on; a failing reader is
+// yielded once and ends the sequence.
+func (lx *Lexer) All() iter.Seq2[datatype.Token, error] {
+	return func(yield func(datatype.Token, error) bool) {
+		for {
+			tok, err := lx.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(tok, err) {
+				return
+			}
+			if err != nil && err == lx.r.Err() {
+				return
+			}
+		}
+	}
+}
+
+// Next returns the next token. At the end of the input it returns io.EOF,
+// or the reader's error if reading failed. Source no token matches is
+// returned as a *LexError; the following call continues after it.
+func (lx *Lexer) Next() (datatype.Token, error) {
+	for {
 		for {
 			ch := lx.r.Peek()
 			if lx.r.EOF() || !isWS(ch) {
@@ -33,111 +78,159 @@ func (lx *Lexer) ScanAll() ([]datatype.Token, []error) {
 			}
 			lx.r.Read()
 		}
+
+		if lx.r.EOF() {
+			if err := lx.r.Err(); err != nil && !lx.failed {
+				lx.failed = true
+				return datatype.Token{}, err
+			}
+			return datatype.Token{}, io.EOF
+		}
+
 		startOff := lx.r.Offset()
 		startLine, startCol := lx.r.Pos()
 		startSnap := lx.r.Snapshot()
 
+		lx.r.Release(startOff)
 		lx.d.Reset()
 
+		if lx.tr != nil {
+			lx.tr.Start(lx.d.State(), startLine, startCol)
+		}
+
 		lastOkOff := -1
 		lastOkLabel := ""
 		lastSnap := startSnap
+		atEOF := false
+
+		for {
+			if lx.r.EOF() {
+				if lx.tr != nil {
+					lx.tr.Stuck(lx.d.State(), 0, true)
+				}
+				atEOF = true
+				break
+			}
 
-		for !lx.r.EOF() {
 			ch := lx.r.Peek()
+			from := lx.d.State()
 			if !lx.d.Advance(ch) {
+				if lx.tr != nil {
+					lx.tr.Stuck(from, ch, false)
+				}
 				break
 			}
 			lx.r.Read()
 
+			if lx.tr != nil {
+				lx.tr.Step(from, lx.d.State(), ch)
+			}
+
 			if lab, ok := lx.d.IsFinal(lx.d.State()); ok {
 				lastOkOff = lx.r.Offset()
 				lastOkLabel = lab
 				lastSnap = lx.r.Snapshot()
+
+				if lx.tr != nil {
+					line, col := lx.r.Pos()
+					lx.tr.Accept(lx.d.State(), lab, line, col)
+				}
 			}
 		}
 
 		if lastOkOff != -1 {
+			if lx.tr != nil && lx.r.Offset() != lastOkOff {
+				line, col := lx.r.Pos()
+				toLine, toCol := lastSnap.Pos()
+				lx.tr.Backtrack(line, col, toLine, toCol)
+			}
 			lx.r.Restore(lastSnap)
 			lex := lx.r.Slice(startOff, lastOkOff)
 
 			tt := mapLabel(lastOkLabel)
-			// if tt == datatype.IDENTIFIER {
-			// 	if _, ok := datatype.Keywords[strings.ToLower(lex)]; ok {
-			// 		tt = datatype.KEYWORD
-			// 	}
-			// }
+
+			// "(" lexes on its own, so a "(*" comment that is never
+			// closed only shows as the DFA running out of input.
+			var err *LexError
+			switch {
+			case atEOF && tt != datatype.COMMENT && lx.r.Slice(startOff, startOff+2) == "(*":
+				err = lx.unterminatedComment(startSnap, "*)")
+			case tt == datatype.NUMBER:
+				err = lx.checkNumber(startSnap)
+			}
+			if err != nil {
+				if lx.tr != nil {
+					lx.tr.Error(err)
+				}
+				return datatype.Token{}, err
+			}
 
 			lex = strings.Trim(lex, " \t\r\n\f")
 
+			key := lex
 			switch tt {
 			case datatype.STRING_LITERAL, datatype.CHAR_LITERAL, datatype.COMMENT:
 			default:
-				lex = strings.ToLower(lex)
+				key = datatype.NormalizeIdentifier(lex)
 			}
 
 			if lex != "" || tt == datatype.STRING_LITERAL || tt == datatype.CHAR_LITERAL || tt == datatype.COMMENT {
-				toks = append(toks, datatype.Token{
-					Type:   tt,
-					Lexeme: lex,
-					Line:   startLine,
-					Col:    startCol,
-				})
+				endLine, endCol := lx.r.Pos()
+				tok := datatype.Token{
+					Type:      tt,
+					Lexeme:    lex,
+					Key:       key,
+					File:      lx.r.FilePath(),
+					Line:      startLine,
+					Col:       startCol,
+					Offset:    startOff,
+					EndOffset: lastOkOff,
+					EndLine:   endLine,
+					EndCol:    endCol,
+				}
+
+				if lx.tr != nil {
+					lx.tr.Token(tok)
+				}
+				return tok, nil
 			}
 			continue
 		}
 
-		if ch, ok := lx.r.Read(); ok {
-			if !isWS(ch) {
-				errs = append(errs, fmt.Errorf("unrecognized %q at %d:%d", ch, startLine, startCol))
-			}
+		err := lx.recover(startSnap)
+		if lx.tr != nil {
+			lx.tr.Error(err)
 		}
+		return datatype.Token{}, err
 	}
+}
 
-	return toks, errs
+// labels maps the outputs a DFA may use to token types.
+var labels = map[string]datatype.TokenType{
+	"KEYWORD":             datatype.KEYWORD,
+	"IDENTIFIER":          datatype.IDENTIFIER,
+	"NUMBER":              datatype.NUMBER,
+	"CHAR_LITERAL":        datatype.CHAR_LITERAL,
+	"STRING_LITERAL":      datatype.STRING_LITERAL,
+	"ARITHMETIC_OPERATOR": datatype.ARITHMETIC_OPERATOR,
+	"RELATIONAL_OPERATOR": datatype.RELATIONAL_OPERATOR,
+	"LOGICAL_OPERATOR":    datatype.LOGICAL_OPERATOR,
+	"ASSIGN_OPERATOR":     datatype.ASSIGN_OPERATOR,
+	"RANGE_OPERATOR":      datatype.RANGE_OPERATOR,
+	"SEMICOLON":           datatype.SEMICOLON,
+	"COMMA":               datatype.COMMA,
+	"COLON":               datatype.COLON,
+	"DOT":                 datatype.DOT,
+	"LPARENTHESIS":        datatype.LPARENTHESIS,
+	"RPARENTHESIS":        datatype.RPARENTHESIS,
+	"LBRACKET":            datatype.LBRACKET,
+	"RBRACKET":            datatype.RBRACKET,
+	"COMMENT":             datatype.COMMENT,
 }
 
 func mapLabel(label string) datatype.TokenType {
-	switch label {
-	case "KEYWORD":
-		return datatype.KEYWORD
-	case "IDENTIFIER":
-		return datatype.IDENTIFIER
-	case "NUMBER":
-		return datatype.NUMBER
-	case "CHAR_LITERAL":
-		return datatype.CHAR_LITERAL
-	case "STRING_LITERAL":
-		return datatype.STRING_LITERAL
-	case "ARITHMETIC_OPERATOR":
-		return datatype.ARITHMETIC_OPERATOR
-	case "RELATIONAL_OPERATOR":
-		return datatype.RELATIONAL_OPERATOR
-	case "LOGICAL_OPERATOR":
-		return datatype.LOGICAL_OPERATOR
-	case "ASSIGN_OPERATOR":
-		return datatype.ASSIGN_OPERATOR
-	case "RANGE_OPERATOR":
-		return datatype.RANGE_OPERATOR
-	case "SEMICOLON":
-		return datatype.SEMICOLON
-	case "COMMA":
-		return datatype.COMMA
-	case "COLON":
-		return datatype.COLON
-	case "DOT":
-		return datatype.DOT
-	case "LPARENTHESIS":
-		return datatype.LPARENTHESIS
-	case "RPARENTHESIS":
-		return datatype.RPARENTHESIS
-	case "LBRACKET":
-		return datatype.LBRACKET
-	case "RBRACKET":
-		return datatype.RBRACKET
-	case "COMMENT":
-		return datatype.COMMENT
-	default:
-		return datatype.IDENTIFIER
+	if tt, ok := labels[label]; ok {
+		return tt
 	}
+	return datatype.IDENTIFIER
 }
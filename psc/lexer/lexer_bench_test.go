@@ -0,0 +1,119 @@
+package lexer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	iox "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/common"
+)
+
+const benchRules = "../../config/tokenizer_m3.json"
+
+func loadBenchSources(b *testing.B) [][]byte {
+	b.Helper()
+
+	paths, err := filepath.Glob("../../test/*/*.pas")
+	if err != nil || len(paths) == 0 {
+		b.Skip("no test programs found")
+	}
+
+	var srcs [][]byte
+	for _, p := range paths {
+		src, err := os.ReadFile(p)
+		if err != nil {
+			b.Fatal(err)
+		}
+		srcs = append(srcs, src)
+	}
+	return srcs
+}
+
+// benchScan lexes every source once per iteration, with the dense table
+// and with the map lookups it replaces. The tokens are dropped as they
+// come, so growing a slice to keep them does not swamp the lookups.
+func benchScan(b *testing.B, srcs [][]byte) {
+	d, err := LoadJSON(benchRules)
+	if err != nil {
+		b.Skip(err)
+	}
+
+	size := 0
+	for _, src := range srcs {
+		size += len(src)
+	}
+
+	run := func(b *testing.B, d *DFA) {
+		b.SetBytes(int64(size))
+		for i := 0; i < b.N; i++ {
+			for _, src := range srcs {
+				for range New(d, iox.NewRuneReader("bench.pas", src)).All() {
+				}
+			}
+		}
+	}
+
+	b.Run("dense", func(b *testing.B) {
+		run(b, d)
+	})
+
+	b.Run("map", func(b *testing.B) {
+		plain := *d
+		plain.tab = nil
+		run(b, &plain)
+	})
+}
+
+// BenchmarkAdvance feeds the runes of the test programs to DFA.Advance
+// alone, restarting from the start state whenever a rune has no
+// transition, so the lookups are measured without the cost of building
+// tokens.
+func BenchmarkAdvance(b *testing.B) {
+	d, err := LoadJSON(benchRules)
+	if err != nil {
+		b.Skip(err)
+	}
+
+	var runes []rune
+	for _, src := range loadBenchSources(b) {
+		runes = append(runes, []rune(string(src))...)
+	}
+
+	run := func(b *testing.B, d *DFA) {
+		b.SetBytes(int64(len(runes)))
+		for i := 0; i < b.N; i++ {
+			d.Reset()
+			for _, r := range runes {
+				if !d.Advance(r) {
+					d.Reset()
+				}
+			}
+		}
+	}
+
+	b.Run("dense", func(b *testing.B) {
+		run(b, d)
+	})
+
+	b.Run("map", func(b *testing.B) {
+		plain := *d
+		plain.tab = nil
+		run(b, &plain)
+	})
+}
+
+func BenchmarkScanTestPrograms(b *testing.B) {
+	benchScan(b, loadBenchSources(b))
+}
+
+func BenchmarkScanLargeSource(b *testing.B) {
+	var buf bytes.Buffer
+	for buf.Len() < 4<<20 {
+		for _, src := range loadBenchSources(b) {
+			buf.Write(src)
+			buf.WriteByte('\n')
+		}
+	}
+	benchScan(b, [][]byte{buf.Bytes()})
+}
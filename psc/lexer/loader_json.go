@@ -3,13 +3,44 @@ package lexer
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strings"
+	"unicode"
 )
 
 type rawTransition struct {
-	From  int    `json:"from"`
-	Input string `json:"input"`
-	To    int    `json:"to"`
+	From  int      `json:"from"`
+	Input rawInput `json:"input"`
+	To    int      `json:"to"`
+}
+
+// rawInput is the input of a transition: one string or a list of them.
+// Each string is a single rune, a range such as "a-z", a named class
+// ("letter", "digit", "any-except:'", ...) or "otherwise".
+type rawInput []string
+
+func (in *rawInput) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*in = rawInput{one}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return fmt.Errorf("input harus string atau list string: %s", b)
+	}
+	*in = list
+	return nil
+}
+
+func (in rawInput) MarshalJSON() ([]byte, error) {
+	if len(in) == 1 {
+		return json.Marshal(in[0])
+	}
+	return json.Marshal([]string(in))
 }
 
 type rawFinal struct {
@@ -24,6 +55,18 @@ type rawSpec struct {
 	Transitions []rawTransition `json:"transitions"`
 }
 
+const otherwise = "otherwise"
+
+var namedClasses = map[string][]Span{
+	"letter":     {{Lo: 'A', Hi: 'Z'}, {Lo: 'a', Hi: 'z'}},
+	"upper":      {{Lo: 'A', Hi: 'Z'}},
+	"lower":      {{Lo: 'a', Hi: 'z'}},
+	"digit":      {{Lo: '0', Hi: '9'}},
+	"alnum":      {{Lo: '0', Hi: '9'}, {Lo: 'A', Hi: 'Z'}, {Lo: 'a', Hi: 'z'}},
+	"whitespace": {{Lo: 0, Hi: 0}, {Lo: '\t', Hi: '\n'}, {Lo: '\f', Hi: '\r'}, {Lo: ' ', Hi: ' '}},
+	"any":        {{Lo: 0, Hi: unicode.MaxRune}},
+}
+
 func LoadJSON(path string) (*DFA, error) {
 	b, err := os.ReadFile(path)
 	if err != nil {
@@ -36,9 +79,12 @@ func LoadJSON(path string) (*DFA, error) {
 	}
 
 	d := &DFA{
-		Start:  State(spec.Start),
-		Finals: make(map[State]string),
-		Trans:  make(map[State]map[rune]State),
+		States:  spec.States,
+		Start:   State(spec.Start),
+		Finals:  make(map[State]string),
+		Trans:   make(map[State]map[rune]State),
+		Spans:   make(map[State][]Span),
+		Default: make(map[State]State),
 	}
 
 	for _, f := range spec.FinalArray {
@@ -46,16 +92,186 @@ func LoadJSON(path string) (*DFA, error) {
 	}
 
 	for _, t := range spec.Transitions {
-		runes := []rune(t.Input)
-		if len(runes) != 1 {
-			return nil, fmt.Errorf("input '%s' harus 1 rune", t.Input)
+		from, to := State(t.From), State(t.To)
+
+		for _, input := range t.Input {
+			if runes := []rune(input); len(runes) == 1 {
+				if d.Trans[from] == nil {
+					d.Trans[from] = make(map[rune]State)
+				}
+				d.Trans[from][runes[0]] = to
+				continue
+			}
+
+			if input == otherwise {
+				if prev, ok := d.Default[from]; ok && prev != to {
+					return nil, fmt.Errorf("state %d punya dua transisi otherwise", t.From)
+				}
+				d.Default[from] = to
+				continue
+			}
+
+			spans, err := parseClass(input)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range spans {
+				s.To = to
+				d.Spans[from] = append(d.Spans[from], s)
+			}
 		}
-		if d.Trans[State(t.From)] == nil {
-			d.Trans[State(t.From)] = make(map[rune]State)
+	}
+
+	for s, spans := range d.Spans {
+		merged, err := mergeSpans(spans)
+		if err != nil {
+			return nil, fmt.Errorf("state %d: %w", s, err)
 		}
-		d.Trans[State(t.From)][runes[0]] = State(t.To)
+		d.Spans[s] = merged
 	}
 
+	d.Compile()
 	d.Reset()
 	return d, nil
 }
+
+// parseClass reads a range or a named class.
+func parseClass(input string) ([]Span, error) {
+	if spans, ok := namedClasses[input]; ok {
+		return spans, nil
+	}
+
+	if except, ok := strings.CutPrefix(input, "any-except:"); ok {
+		if except == "" {
+			return nil, fmt.Errorf("input '%s' tidak menyebut rune yang dikecualikan", input)
+		}
+
+		excluded := []rune(except)
+		slices.Sort(excluded)
+
+		var spans []Span
+		lo := rune(0)
+		for _, r := range excluded {
+			if r > lo {
+				spans = append(spans, Span{Lo: lo, Hi: r - 1})
+			}
+			lo = max(lo, r+1)
+		}
+		if lo <= unicode.MaxRune {
+			spans = append(spans, Span{Lo: lo, Hi: unicode.MaxRune})
+		}
+		return spans, nil
+	}
+
+	if runes := []rune(input); len(runes) == 3 && runes[1] == '-' {
+		if runes[2] < runes[0] {
+			return nil, fmt.Errorf("range '%s' terbalik", input)
+		}
+		return []Span{{Lo: runes[0], Hi: runes[2]}}, nil
+	}
+
+	return nil, fmt.Errorf("input '%s' bukan rune, range, atau kelas yang dikenal", input)
+}
+
+// mergeSpans sorts spans and joins overlapping or adjacent ones with the
+// same target. Overlapping spans with different targets are an error.
+func mergeSpans(spans []Span) ([]Span, error) {
+	slices.SortFunc(spans, func(a, b Span) int { return int(a.Lo - b.Lo) })
+
+	var out []Span
+	for _, s := range spans {
+		n := len(out)
+		if n == 0 || s.Lo > out[n-1].Hi+1 {
+			out = append(out, s)
+			continue
+		}
+
+		if s.To != out[n-1].To {
+			if s.Lo <= out[n-1].Hi {
+				return nil, fmt.Errorf("rune %q punya dua tujuan (%d dan %d)", s.Lo, out[n-1].To, s.To)
+			}
+			out = append(out, s)
+			continue
+		}
+		out[n-1].Hi = max(out[n-1].Hi, s.Hi)
+	}
+	return out, nil
+}
+
+// WriteJSON writes d in the format LoadJSON reads. Inputs that lead from
+// one state to the same target are written as one transition, with runs of
+// runes as ranges. Finals are ordered by state and transitions by source
+// state then first input, so equal automata give equal files.
+func WriteJSON(w io.Writer, d *DFA) error {
+	spec := rawSpec{Start: int(d.Start)}
+	states := max(d.States, int(d.Start)+1)
+
+	for s, out := range d.Finals {
+		spec.FinalArray = append(spec.FinalArray, rawFinal{State: int(s), Output: out})
+		states = max(states, int(s)+1)
+	}
+	slices.SortFunc(spec.FinalArray, func(a, b rawFinal) int { return a.State - b.State })
+
+	type edge struct {
+		from State
+		to   State
+	}
+	edges := map[edge][]Span{}
+
+	for from, row := range d.Trans {
+		for r, to := range row {
+			e := edge{from, to}
+			edges[e] = append(edges[e], Span{Lo: r, Hi: r, To: to})
+		}
+	}
+	for from, spans := range d.Spans {
+		for _, s := range spans {
+			e := edge{from, s.To}
+			edges[e] = append(edges[e], s)
+		}
+	}
+
+	type group struct {
+		from  State
+		first rune
+		t     rawTransition
+	}
+	var groups []group
+
+	for e, spans := range edges {
+		merged, _ := mergeSpans(spans)
+
+		var input rawInput
+		for _, s := range merged {
+			if s.Lo == s.Hi {
+				input = append(input, string(s.Lo))
+			} else {
+				input = append(input, string(s.Lo)+"-"+string(s.Hi))
+			}
+		}
+
+		groups = append(groups, group{e.from, merged[0].Lo, rawTransition{From: int(e.from), Input: input, To: int(e.to)}})
+		states = max(states, int(e.from)+1, int(e.to)+1)
+	}
+	for from, to := range d.Default {
+		groups = append(groups, group{from, unicode.MaxRune + 1, rawTransition{From: int(from), Input: rawInput{otherwise}, To: int(to)}})
+		states = max(states, int(from)+1, int(to)+1)
+	}
+
+	slices.SortFunc(groups, func(a, b group) int {
+		if a.from != b.from {
+			return int(a.from - b.from)
+		}
+		return int(a.first - b.first)
+	})
+
+	for _, g := range groups {
+		spec.Transitions = append(spec.Transitions, g.t)
+	}
+	spec.States = states
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	return enc.Encode(spec)
+}
@@ -0,0 +1,251 @@
+package lexer
+
+import (
+	"slices"
+	"unicode"
+)
+
+// Minimize returns the smallest DFA that gives every input the same output
+// as d. Unreachable and dead states are dropped, and equivalent states are
+// merged with Hopcroft's partition refinement. States are numbered
+// breadth-first from the start, which becomes state 0.
+func Minimize(d *DFA) *DFA {
+	bounds := d.alphabet()
+	classes := len(bounds) - 1
+
+	// Number the reachable, live states; index n is the sink every
+	// missing transition goes to.
+	reachable := d.reachable()
+	live := d.live(reachable)
+
+	var states []State
+	index := map[State]int{}
+	for _, s := range d.StateIDs() {
+		if live[s] || s == d.Start {
+			index[s] = len(states)
+			states = append(states, s)
+		}
+	}
+
+	n := len(states)
+	sink := n
+	next := make([][]int, n+1)
+	for i, s := range states {
+		row := make([]int, classes)
+		for c := range row {
+			row[c] = sink
+			if t, ok := d.slowNext(s, bounds[c]); ok {
+				if j, ok := index[t]; ok {
+					row[c] = j
+				}
+			}
+		}
+		next[i] = row
+	}
+	next[sink] = slices.Repeat([]int{sink}, classes)
+
+	block := hopcroft(next, func(i int) string {
+		if i == sink {
+			return ""
+		}
+		return d.Finals[states[i]]
+	}, classes)
+
+	// Rebuild from one member of each block, breadth-first from the start.
+	member := map[int]int{}
+	for i := n; i >= 0; i-- {
+		member[block[i]] = i
+	}
+
+	out := &DFA{
+		Start:   0,
+		Finals:  make(map[State]string),
+		Trans:   make(map[State]map[rune]State),
+		Spans:   make(map[State][]Span),
+		Default: make(map[State]State),
+	}
+
+	id := map[int]State{block[index[d.Start]]: 0}
+	queue := []int{block[index[d.Start]]}
+
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+
+		from := id[b]
+		rep := member[b]
+		if lab, ok := d.Finals[states[rep]]; ok {
+			out.Finals[from] = lab
+		}
+
+		var spans []Span
+		for c, t := range next[rep] {
+			tb := block[t]
+			if tb == block[sink] {
+				continue
+			}
+
+			to, ok := id[tb]
+			if !ok {
+				to = State(len(id))
+				id[tb] = to
+				queue = append(queue, tb)
+			}
+
+			lo, hi := bounds[c], bounds[c+1]-1
+			if k := len(spans); k > 0 && spans[k-1].To == to && spans[k-1].Hi+1 == lo {
+				spans[k-1].Hi = hi
+				continue
+			}
+			spans = append(spans, Span{Lo: lo, Hi: hi, To: to})
+		}
+
+		for _, span := range spans {
+			if span.Lo != span.Hi {
+				out.Spans[from] = append(out.Spans[from], span)
+				continue
+			}
+			if out.Trans[from] == nil {
+				out.Trans[from] = make(map[rune]State)
+			}
+			out.Trans[from][span.Lo] = span.To
+		}
+	}
+
+	out.States = len(id)
+	out.Compile()
+	out.Reset()
+	return out
+}
+
+// alphabet splits all runes into intervals on which every state of d moves
+// the same way. Interval c covers bounds[c] up to bounds[c+1]-1.
+func (d *DFA) alphabet() []rune {
+	seen := map[rune]bool{0: true, unicode.MaxRune + 1: true}
+	for _, row := range d.Trans {
+		for r := range row {
+			seen[r] = true
+			seen[r+1] = true
+		}
+	}
+	for _, spans := range d.Spans {
+		for _, span := range spans {
+			seen[span.Lo] = true
+			seen[span.Hi+1] = true
+		}
+	}
+
+	bounds := make([]rune, 0, len(seen))
+	for r := range seen {
+		if r >= 0 && r <= unicode.MaxRune+1 {
+			bounds = append(bounds, r)
+		}
+	}
+	slices.Sort(bounds)
+	return bounds
+}
+
+// hopcroft partitions the states of a complete automaton so that two states
+// share a block exactly when they are equivalent. The initial partition
+// groups states by label.
+func hopcroft(next [][]int, label func(int) string, classes int) []int {
+	n := len(next)
+
+	// prev[c][t] lists the states that move to t on class c.
+	prev := make([][][]int, classes)
+	for c := range prev {
+		prev[c] = make([][]int, n)
+	}
+	for s, row := range next {
+		for c, t := range row {
+			prev[c][t] = append(prev[c][t], s)
+		}
+	}
+
+	block := make([]int, n)
+	var blocks [][]int
+	byLabel := map[string]int{}
+	for s := 0; s < n; s++ {
+		lab := label(s)
+		b, ok := byLabel[lab]
+		if !ok {
+			b = len(blocks)
+			byLabel[lab] = b
+			blocks = append(blocks, nil)
+		}
+		block[s] = b
+		blocks[b] = append(blocks[b], s)
+	}
+
+	work := make([]int, len(blocks))
+	pending := make([]bool, len(blocks))
+	for b := range blocks {
+		work[b] = b
+		pending[b] = true
+	}
+
+	for len(work) > 0 {
+		a := work[len(work)-1]
+		work = work[:len(work)-1]
+		pending[a] = false
+
+		splitter := slices.Clone(blocks[a])
+
+		for c := 0; c < classes; c++ {
+			// Collect the states moving into the splitter, by block.
+			hit := map[int][]int{}
+			for _, t := range splitter {
+				for _, s := range prev[c][t] {
+					hit[block[s]] = append(hit[block[s]], s)
+				}
+			}
+
+			touched := make([]int, 0, len(hit))
+			for b := range hit {
+				touched = append(touched, b)
+			}
+			slices.Sort(touched)
+
+			for _, b := range touched {
+				in := hit[b]
+				if len(in) == len(blocks[b]) {
+					continue
+				}
+
+				inSet := make(map[int]bool, len(in))
+				for _, s := range in {
+					inSet[s] = true
+				}
+
+				var out []int
+				for _, s := range blocks[b] {
+					if !inSet[s] {
+						out = append(out, s)
+					}
+				}
+
+				nb := len(blocks)
+				blocks[b] = out
+				blocks = append(blocks, in)
+				for _, s := range in {
+					block[s] = nb
+				}
+
+				switch {
+				case pending[b]:
+					work = append(work, nb)
+					pending = append(pending, true)
+				case len(in) <= len(out):
+					work = append(work, nb)
+					pending = append(pending, true)
+				default:
+					work = append(work, b)
+					pending[b] = true
+					pending = append(pending, false)
+				}
+			}
+		}
+	}
+
+	return block
+}
@@ -0,0 +1,235 @@
+package lexer
+
+import (
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	iox "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/common"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// Preprocessor applies the compiler directives in a token stream.
+// {$I path} or {$INCLUDE path} splices in the tokens of another file, read
+// relative to the file that includes it. {$DEFINE X} and {$UNDEF X} set
+// symbols, and {$IFDEF X}, {$IFNDEF X}, {$ELSE} and {$ENDIF} drop the
+// tokens, comments and lexical errors of the branch not taken. The
+// directives themselves stay in the stream as comments, and ones it does
+// not know, such as {$R+}, are left alone.
+type Preprocessor struct {
+	d       *DFA
+	defines map[string]bool
+	tr      Tracer
+	// files are the files being read, outermost first, to catch an
+	// include cycle.
+	files []string
+	// readFile reads an included file; tests serve them from memory.
+	readFile func(string) ([]byte, error)
+}
+
+// condition is an open {$IFDEF}. A branch is taken when its own test holds
+// and the enclosing one is taken too.
+type condition struct {
+	outer   bool
+	taken   bool
+	hasElse bool
+	at      datatype.Token
+}
+
+func NewPreprocessor(d *DFA, defines []string) *Preprocessor {
+	p := &Preprocessor{d: d, defines: make(map[string]bool), readFile: os.ReadFile}
+	for _, name := range defines {
+		p.defines[datatype.NormalizeIdentifier(name)] = true
+	}
+	return p
+}
+
+// SetTracer makes the lexers of included files report to t as well.
+func (p *Preprocessor) SetTracer(t Tracer) {
+	p.tr = t
+}
+
+// All yields what lx.All would, with the directives applied.
+func (p *Preprocessor) All(lx *Lexer) iter.Seq2[datatype.Token, error] {
+	return func(yield func(datatype.Token, error) bool) {
+		p.files = append(p.files[:0], lx.r.FilePath())
+		p.run(lx, yield)
+	}
+}
+
+// ScanAll is Lexer.ScanAll with the directives applied.
+func (p *Preprocessor) ScanAll(lx *Lexer) ([]datatype.Token, []error) {
+	var toks []datatype.Token
+	var errs []error
+
+	for tok, err := range p.All(lx) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		toks = append(toks, tok)
+	}
+
+	return toks, errs
+}
+
+// run preprocesses one file and reports whether the consumer wants more.
+func (p *Preprocessor) run(lx *Lexer, yield func(datatype.Token, error) bool) bool {
+	var conds []condition
+	active := func() bool {
+		return len(conds) == 0 || conds[len(conds)-1].taken
+	}
+
+	for tok, err := range lx.All() {
+		if err != nil {
+			if !active() {
+				continue
+			}
+			if lexErr, ok := err.(*LexError); ok && len(p.files) > 1 {
+				lexErr.File = lx.r.FilePath()
+			}
+			if !yield(tok, err) {
+				return false
+			}
+			continue
+		}
+
+		name, arg, ok := directive(tok)
+		if !ok {
+			if active() && !yield(tok, nil) {
+				return false
+			}
+			continue
+		}
+
+		switch name {
+		case "IFDEF", "IFNDEF":
+			outer := active()
+			taken := p.defines[datatype.NormalizeIdentifier(arg)] == (name == "IFDEF")
+			conds = append(conds, condition{outer: outer, taken: outer && taken, at: tok})
+			if outer && !yield(tok, nil) {
+				return false
+			}
+			continue
+
+		case "ELSE", "ENDIF":
+			if len(conds) == 0 || (name == "ELSE" && conds[len(conds)-1].hasElse) {
+				fix := "there is no open {$IFDEF} for it"
+				if len(conds) > 0 {
+					fix = "this {$IFDEF} already has an {$ELSE}"
+				}
+				if active() && !yield(datatype.Token{}, p.directiveError(lx, tok, fix)) {
+					return false
+				}
+				continue
+			}
+
+			c := &conds[len(conds)-1]
+			if name == "ENDIF" {
+				conds = conds[:len(conds)-1]
+			} else {
+				c.hasElse = true
+				c.taken = c.outer && !c.taken
+			}
+			if c.outer && !yield(tok, nil) {
+				return false
+			}
+			continue
+		}
+
+		if !active() {
+			continue
+		}
+		if !yield(tok, nil) {
+			return false
+		}
+
+		switch name {
+		case "DEFINE":
+			p.defines[datatype.NormalizeIdentifier(arg)] = true
+		case "UNDEF":
+			delete(p.defines, datatype.NormalizeIdentifier(arg))
+		case "I", "INCLUDE":
+			// {$I+} and {$I-} switch I/O checking in Turbo Pascal.
+			if arg != "+" && arg != "-" && !p.include(lx, tok, arg, yield) {
+				return false
+			}
+		}
+	}
+
+	for _, c := range slices.Backward(conds) {
+		if !yield(datatype.Token{}, p.directiveError(lx, c.at, "close it with {$ENDIF} before the end of the file")) {
+			return false
+		}
+	}
+	return true
+}
+
+// include yields the tokens of the file named by the directive tok.
+func (p *Preprocessor) include(lx *Lexer, tok datatype.Token, arg string, yield func(datatype.Token, error) bool) bool {
+	path := strings.Trim(arg, "'\"")
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(lx.r.FilePath()), path)
+	}
+
+	id := identity(path)
+	if i := slices.IndexFunc(p.files, func(f string) bool { return identity(f) == id }); i >= 0 {
+		cycle := append(slices.Clone(p.files[i:]), path)
+		return yield(datatype.Token{}, p.directiveError(lx, tok, "include cycle: "+strings.Join(cycle, " -> ")))
+	}
+
+	src, err := p.readFile(path)
+	if err != nil {
+		return yield(datatype.Token{}, p.directiveError(lx, tok, err.Error()))
+	}
+
+	inner := New(lx.d, iox.NewRuneReader(path, src))
+	inner.SetTracer(p.tr)
+
+	p.files = append(p.files, path)
+	more := p.run(inner, yield)
+	p.files = p.files[:len(p.files)-1]
+	return more
+}
+
+func (p *Preprocessor) directiveError(lx *Lexer, tok datatype.Token, fix string) *LexError {
+	err := &LexError{Kind: LEX_BAD_DIRECTIVE, Span: tok.Span(), Text: tok.Lexeme, Fix: fix}
+	if len(p.files) > 1 {
+		err.File = lx.r.FilePath()
+	}
+	return err
+}
+
+// directive splits a {$NAME arg} or (*$NAME arg*) comment. The name is
+// returned in upper case.
+func directive(tok datatype.Token) (name, arg string, ok bool) {
+	if tok.Type != datatype.COMMENT {
+		return "", "", false
+	}
+
+	body, ok := strings.CutPrefix(tok.Lexeme, "{$")
+	if ok {
+		body = strings.TrimSuffix(body, "}")
+	} else if body, ok = strings.CutPrefix(tok.Lexeme, "(*$"); ok {
+		body = strings.TrimSuffix(body, "*)")
+	} else {
+		return "", "", false
+	}
+
+	name, arg, _ = strings.Cut(strings.TrimSpace(body), " ")
+	// {$I+} has no space before its argument.
+	if rest, found := strings.CutPrefix(strings.ToUpper(name), "I"); found && (rest == "+" || rest == "-") {
+		return "I", rest, true
+	}
+	return strings.ToUpper(name), strings.TrimSpace(arg), true
+}
+
+// identity is how a file is recognized when it is included twice.
+func identity(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,136 @@
+package lexer
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	iox "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/common"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// preprocess runs main.pas through a preprocessor that reads included
+// files from files instead of the disk.
+func preprocess(t *testing.T, src string, files map[string]string, defines ...string) ([]datatype.Token, []error) {
+	t.Helper()
+
+	d, err := LoadJSON("../../config/tokenizer_m3.json")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	p := NewPreprocessor(d, defines)
+	p.readFile = func(path string) ([]byte, error) {
+		src, ok := files[path]
+		if !ok {
+			return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+		}
+		return []byte(src), nil
+	}
+
+	return p.ScanAll(New(d, iox.NewRuneReaderFromString("main.pas", src)))
+}
+
+// identifiers lists the lexemes of the identifiers in tokens.
+func identifiers(tokens []datatype.Token) string {
+	var names []string
+	for _, tok := range tokens {
+		if tok.Type == datatype.IDENTIFIER {
+			names = append(names, tok.Lexeme)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+func TestIncludeCycle(t *testing.T) {
+	_, errs := preprocess(t, "{$I a.inc}\n", map[string]string{
+		"a.inc": "x {$I b.inc}\n",
+		"b.inc": "y {$I a.inc}\n",
+	})
+
+	if len(errs) != 1 {
+		t.Fatalf("got %v, want one error", errs)
+	}
+	if msg := errs[0].Error(); !strings.Contains(msg, "include cycle: a.inc -> b.inc -> a.inc") || !strings.Contains(msg, "b.inc:1:3") {
+		t.Errorf("got %q, want the cycle a.inc -> b.inc -> a.inc reported at b.inc:1:3", msg)
+	}
+}
+
+func TestIncludeMissingFile(t *testing.T) {
+	tokens, errs := preprocess(t, "x\n{$I missing.inc}\ny\n", nil)
+
+	if len(errs) != 1 {
+		t.Fatalf("got %v, want one error", errs)
+	}
+	if msg := errs[0].Error(); !strings.Contains(msg, "2:1") || !strings.Contains(msg, "open missing.inc: file does not exist") {
+		t.Errorf("got %q, want missing.inc reported at 2:1", msg)
+	}
+	if got := identifiers(tokens); got != "x y" {
+		t.Errorf("identifiers %q, want the ones around the directive", got)
+	}
+}
+
+func TestUnbalancedEndif(t *testing.T) {
+	_, errs := preprocess(t, "x\n{$ENDIF}\n", nil)
+
+	if len(errs) != 1 {
+		t.Fatalf("got %v, want one error", errs)
+	}
+	if msg := errs[0].Error(); !strings.Contains(msg, "2:1") || !strings.Contains(msg, "no open {$IFDEF}") {
+		t.Errorf("got %q, want an {$ENDIF} without {$IFDEF} at 2:1", msg)
+	}
+}
+
+func TestIncludedTokenPosition(t *testing.T) {
+	tokens, errs := preprocess(t, "x\n{$I sub/a.inc}\n", map[string]string{
+		"sub/a.inc": "\n  y\n",
+	})
+
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	for _, tok := range tokens {
+		if tok.Lexeme != "y" {
+			continue
+		}
+		if tok.File != "sub/a.inc" || tok.Line != 2 || tok.Col != 3 {
+			t.Errorf("y is at %s:%d:%d, want sub/a.inc:2:3", tok.File, tok.Line, tok.Col)
+		}
+		return
+	}
+	t.Fatalf("y not found in %v", tokens)
+}
+
+func TestNestedConditionals(t *testing.T) {
+	src := `{$IFDEF A}
+  a
+  {$IFDEF B} ab {$ELSE} a_not_b {$ENDIF}
+{$ELSE}
+  not_a
+  {$IFDEF B} not_a_b {$ELSE} neither {$ENDIF}
+{$ENDIF}
+`
+
+	tests := []struct {
+		defines []string
+		want    string
+	}{
+		{nil, "not_a neither"},
+		{[]string{"A"}, "a a_not_b"},
+		{[]string{"B"}, "not_a not_a_b"},
+		{[]string{"A", "B"}, "a ab"},
+	}
+
+	for _, tt := range tests {
+		tokens, errs := preprocess(t, src, nil, tt.defines...)
+
+		if len(errs) > 0 {
+			t.Errorf("%v: unexpected errors: %v", tt.defines, errs)
+			continue
+		}
+		if got := identifiers(tokens); got != tt.want {
+			t.Errorf("%v: identifiers %q, want %q", tt.defines, got, tt.want)
+		}
+	}
+}
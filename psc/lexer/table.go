@@ -0,0 +1,99 @@
+package lexer
+
+import (
+	"strconv"
+	"strings"
+)
+
+const asciiLimit = 128
+
+// table is the compiled form of a DFA for ASCII input. Runes below
+// asciiLimit are first mapped to an equivalence class, a set of runes every
+// state treats the same way, and next[s*classes+c] is the successor of s on
+// class c, or -1. final and label mirror Finals. Other runes, and states
+// outside the table, fall back to the maps.
+type table struct {
+	class   [asciiLimit]int32
+	classes int
+	states  int
+	next    []int32
+	final   []bool
+	label   []string
+}
+
+// Compile builds the dense transition table Step and Advance use. LoadJSON
+// calls it; code that changes Trans, Spans or Default afterwards must call it
+// again.
+func (d *DFA) Compile() {
+	d.tab = nil
+
+	states := int(d.Start) + 1
+	grow := func(s State) {
+		states = max(states, int(s)+1)
+	}
+	for s := range d.Finals {
+		grow(s)
+	}
+	for s, row := range d.Trans {
+		grow(s)
+		for _, t := range row {
+			grow(t)
+		}
+	}
+	for s, spans := range d.Spans {
+		grow(s)
+		for _, span := range spans {
+			grow(span.To)
+		}
+	}
+	for s, t := range d.Default {
+		grow(s)
+		grow(t)
+	}
+
+	t := &table{states: states, final: make([]bool, states), label: make([]string, states)}
+
+	// Runes with the same column of successors share a class.
+	signatures := map[string]int32{}
+	columns := [][]int32{}
+
+	for r := rune(0); r < asciiLimit; r++ {
+		column := make([]int32, states)
+		var sb strings.Builder
+
+		for s := range column {
+			column[s] = -1
+			if next, ok := d.slowNext(State(s), r); ok {
+				column[s] = int32(next)
+			}
+			sb.WriteString(strconv.Itoa(int(column[s])))
+			sb.WriteByte(',')
+		}
+
+		key := sb.String()
+		c, ok := signatures[key]
+		if !ok {
+			c = int32(len(columns))
+			signatures[key] = c
+			columns = append(columns, column)
+		}
+		t.class[r] = c
+	}
+
+	t.classes = len(columns)
+	t.next = make([]int32, states*t.classes)
+	for c, column := range columns {
+		for s, next := range column {
+			t.next[s*t.classes+c] = next
+		}
+	}
+
+	for s, lab := range d.Finals {
+		if s >= 0 {
+			t.final[s] = true
+			t.label[s] = lab
+		}
+	}
+
+	d.tab = t
+}
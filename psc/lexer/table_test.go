@@ -0,0 +1,47 @@
+package lexer
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestTableMatchesMaps checks that the dense table gives every state the
+// successor the maps give it, for every ASCII rune and for a few others
+// that must fall back to the maps.
+func TestTableMatchesMaps(t *testing.T) {
+	paths, err := filepath.Glob("../../config/*.json")
+	if err != nil || len(paths) == 0 {
+		t.Skip("no DFA configurations found")
+	}
+
+	runes := []rune{-1, 'é', 'λ', '→', 0x10FFFF}
+	for r := rune(0); r < asciiLimit; r++ {
+		runes = append(runes, r)
+	}
+
+	for _, path := range paths {
+		d, err := LoadJSON(path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if d.tab == nil {
+			t.Fatalf("%s: LoadJSON did not compile the table", path)
+		}
+
+		for s := State(-1); int(s) <= d.tab.states; s++ {
+			for _, r := range runes {
+				got, gotOK := d.next(s, r)
+				want, wantOK := d.slowNext(s, r)
+				if gotOK != wantOK || (wantOK && got != want) {
+					t.Errorf("%s: state %d on %q: table gives (%d, %v), maps give (%d, %v)", path, s, r, got, gotOK, want, wantOK)
+				}
+			}
+
+			lab, final := d.IsFinal(s)
+			wantLab, wantFinal := d.Finals[s]
+			if final != wantFinal || lab != wantLab {
+				t.Errorf("%s: state %d: table gives final (%q, %v), Finals gives (%q, %v)", path, s, lab, final, wantLab, wantFinal)
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package lexer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// Tracer receives the steps ScanAll takes, in order. Positions are 1-based
+// line and column pairs.
+type Tracer interface {
+	// Start is called before each token, with the DFA reset to state.
+	Start(state State, line, col int)
+	// Step reports a transition on r.
+	Step(from, to State, r rune)
+	// Stuck reports that state has no transition on r, or that the input
+	// ended when eof is set. The token ends here.
+	Stuck(state State, r rune, eof bool)
+	// Accept reports that state is final, so the token up to line:col is
+	// recorded as the longest match so far.
+	Accept(state State, label string, line, col int)
+	// Backtrack reports that the reader went back from line:col to the
+	// last accepted position toLine:toCol.
+	Backtrack(line, col, toLine, toCol int)
+	// Token reports a token ScanAll emits.
+	Token(tok datatype.Token)
+	// Error reports a rune no token starts with.
+	Error(err error)
+}
+
+type textTracer struct {
+	w io.Writer
+}
+
+// NewTextTracer renders a trace as indented lines, one per event.
+func NewTextTracer(w io.Writer) Tracer {
+	return &textTracer{w: w}
+}
+
+func (t *textTracer) Start(state State, line, col int) {
+	fmt.Fprintf(t.w, "token at %d:%d, state %d\n", line, col, state)
+}
+
+func (t *textTracer) Step(from, to State, r rune) {
+	fmt.Fprintf(t.w, "  %d --%q--> %d\n", from, r, to)
+}
+
+func (t *textTracer) Stuck(state State, r rune, eof bool) {
+	if eof {
+		fmt.Fprintf(t.w, "  %d: end of input\n", state)
+		return
+	}
+	fmt.Fprintf(t.w, "  %d: no transition on %q\n", state, r)
+}
+
+func (t *textTracer) Accept(state State, label string, line, col int) {
+	fmt.Fprintf(t.w, "  %d is final (%s), last accept at %d:%d\n", state, label, line, col)
+}
+
+func (t *textTracer) Backtrack(line, col, toLine, toCol int) {
+	fmt.Fprintf(t.w, "  backtrack %d:%d -> %d:%d\n", line, col, toLine, toCol)
+}
+
+func (t *textTracer) Token(tok datatype.Token) {
+	fmt.Fprintf(t.w, "  => %s(%s)\n", tok.Type, tok.Lexeme)
+}
+
+func (t *textTracer) Error(err error) {
+	fmt.Fprintf(t.w, "  error: %v\n", err)
+}
+
+// traceEvent is one line of JSON trace output. Fields that do not apply to
+// an event are left out.
+type traceEvent struct {
+	Event  string `json:"event"`
+	State  *State `json:"state,omitempty"`
+	From   *State `json:"from,omitempty"`
+	To     *State `json:"to,omitempty"`
+	Rune   string `json:"rune,omitempty"`
+	Label  string `json:"label,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Col    int    `json:"col,omitempty"`
+	ToLine int    `json:"to_line,omitempty"`
+	ToCol  int    `json:"to_col,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Lexeme string `json:"lexeme,omitempty"`
+	EOF    bool   `json:"eof,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type jsonTracer struct {
+	enc *json.Encoder
+}
+
+// NewJSONTracer renders a trace as JSON lines, one object per event.
+func NewJSONTracer(w io.Writer) Tracer {
+	return &jsonTracer{enc: json.NewEncoder(w)}
+}
+
+func (t *jsonTracer) emit(e traceEvent) {
+	t.enc.Encode(e)
+}
+
+func (t *jsonTracer) Start(state State, line, col int) {
+	t.emit(traceEvent{Event: "start", State: &state, Line: line, Col: col})
+}
+
+func (t *jsonTracer) Step(from, to State, r rune) {
+	t.emit(traceEvent{Event: "step", From: &from, To: &to, Rune: string(r)})
+}
+
+func (t *jsonTracer) Stuck(state State, r rune, eof bool) {
+	e := traceEvent{Event: "stuck", State: &state, EOF: eof}
+	if !eof {
+		e.Rune = string(r)
+	}
+	t.emit(e)
+}
+
+func (t *jsonTracer) Accept(state State, label string, line, col int) {
+	t.emit(traceEvent{Event: "accept", State: &state, Label: label, Line: line, Col: col})
+}
+
+func (t *jsonTracer) Backtrack(line, col, toLine, toCol int) {
+	t.emit(traceEvent{Event: "backtrack", Line: line, Col: col, ToLine: toLine, ToCol: toCol})
+}
+
+func (t *jsonTracer) Token(tok datatype.Token) {
+	t.emit(traceEvent{Event: "token", Type: tok.Type.String(), Lexeme: tok.Lexeme, Line: tok.Line, Col: tok.Col})
+}
+
+func (t *jsonTracer) Error(err error) {
+	t.emit(traceEvent{Event: "error", Error: err.Error()})
+}
@@ -0,0 +1,97 @@
+package lexer
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// WithTrivia folds the comments in seq into the tokens around them. A
+// comment that starts on the line its previous token ends on trails that
+// token; any other comment, and every run of blank lines, leads the token
+// after it. Whatever is left at the end of the input trails the last token.
+// Errors are passed through in order.
+func WithTrivia(seq iter.Seq2[datatype.Token, error]) iter.Seq2[datatype.Token, error] {
+	return func(yield func(datatype.Token, error) bool) {
+		var prev *datatype.Token
+		var leading []datatype.Trivia
+		lastLine := 0 // line the last token or comment ended on
+		lastFile := ""
+		started := false
+
+		// blank records the blank lines before line. There are none to
+		// count where an included file starts or ends.
+		blank := func(line int, file string) {
+			if started && file != lastFile {
+				lastFile = file
+				return
+			}
+			started, lastFile = true, file
+			if n := line - lastLine - 1; n > 0 {
+				leading = append(leading, datatype.Trivia{Kind: datatype.TRIVIA_BLANK_LINES, Lines: n})
+			}
+		}
+
+		flush := func() bool {
+			if prev == nil {
+				return true
+			}
+			tok := *prev
+			prev = nil
+			return yield(tok, nil)
+		}
+
+		for tok, err := range seq {
+			if err != nil {
+				if !flush() || !yield(tok, err) {
+					return
+				}
+				continue
+			}
+
+			if tok.Type == datatype.COMMENT {
+				comment := datatype.Trivia{Kind: datatype.TRIVIA_COMMENT, Text: tok.Lexeme, Span: tok.Span()}
+				if prev != nil && len(leading) == 0 && tok.Line == lastLine && tok.File == lastFile {
+					prev.Trailing = append(prev.Trailing, comment)
+				} else {
+					blank(tok.Line, tok.File)
+					leading = append(leading, comment)
+				}
+				lastLine = tok.EndLine
+				continue
+			}
+
+			if !flush() {
+				return
+			}
+
+			blank(tok.Line, tok.File)
+			tok.Leading = leading
+			leading = nil
+			prev = &tok
+			lastLine = tok.EndLine
+		}
+
+		if prev != nil {
+			prev.Trailing = append(prev.Trailing, leading...)
+		}
+		flush()
+	}
+}
+
+// AttachTrivia is WithTrivia for tokens that have already been scanned. The
+// result holds no comments; tokens is left as it is.
+func AttachTrivia(tokens []datatype.Token) []datatype.Token {
+	out := make([]datatype.Token, 0, len(tokens))
+	for tok := range WithTrivia(func(yield func(datatype.Token, error) bool) {
+		for _, tok := range tokens {
+			if !yield(tok, nil) {
+				return
+			}
+		}
+	}) {
+		out = append(out, tok)
+	}
+	return slices.Clip(out)
+}
@@ -0,0 +1,127 @@
+package lexer
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Validate reports what LoadJSON lets through: state ids outside
+// 0..States-1, outputs the lexer does not know, states the start cannot
+// reach and reachable states from which no final state can be reached.
+func Validate(d *DFA) []error {
+	var errs []error
+
+	ids := d.StateIDs()
+
+	for _, s := range ids {
+		if s < 0 || (d.States > 0 && int(s) >= d.States) {
+			errs = append(errs, fmt.Errorf("state %d is out of range (states = %d)", s, d.States))
+		}
+	}
+
+	for _, s := range ids {
+		if lab, ok := d.Finals[s]; ok {
+			if _, known := labels[lab]; !known {
+				errs = append(errs, fmt.Errorf("state %d has unknown output %q", s, lab))
+			}
+		}
+	}
+
+	reachable := d.reachable()
+	for _, s := range ids {
+		if !reachable[s] {
+			errs = append(errs, fmt.Errorf("state %d is unreachable", s))
+		}
+	}
+
+	live := d.live(reachable)
+	for _, s := range ids {
+		if reachable[s] && !live[s] {
+			errs = append(errs, fmt.Errorf("state %d is dead: no final state is reachable from it", s))
+		}
+	}
+
+	return errs
+}
+
+// StateIDs lists, in order, every state d mentions plus 0..States-1.
+func (d *DFA) StateIDs() []State {
+	seen := map[State]bool{d.Start: true}
+	for s := 0; s < d.States; s++ {
+		seen[State(s)] = true
+	}
+	for s := range d.Finals {
+		seen[s] = true
+	}
+	for s := range d.Trans {
+		seen[s] = true
+		for _, t := range d.successors(s) {
+			seen[t] = true
+		}
+	}
+	for s := range d.Spans {
+		seen[s] = true
+		for _, t := range d.successors(s) {
+			seen[t] = true
+		}
+	}
+	for s, t := range d.Default {
+		seen[s] = true
+		seen[t] = true
+	}
+
+	ids := make([]State, 0, len(seen))
+	for s := range seen {
+		ids = append(ids, s)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func (d *DFA) successors(s State) []State {
+	var out []State
+	for _, t := range d.Trans[s] {
+		out = append(out, t)
+	}
+	for _, span := range d.Spans[s] {
+		out = append(out, span.To)
+	}
+	if t, ok := d.Default[s]; ok {
+		out = append(out, t)
+	}
+	return out
+}
+
+func (d *DFA) reachable() map[State]bool {
+	return d.reachableFrom(d.Start)
+}
+
+// live marks the states in among from which a final state can be reached.
+func (d *DFA) live(among map[State]bool) map[State]bool {
+	preds := map[State][]State{}
+	var queue []State
+	live := map[State]bool{}
+
+	for s := range among {
+		for _, t := range d.successors(s) {
+			preds[t] = append(preds[t], s)
+		}
+		if _, ok := d.Finals[s]; ok {
+			live[s] = true
+			queue = append(queue, s)
+		}
+	}
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+
+		for _, p := range preds[s] {
+			if !live[p] {
+				live[p] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+	return live
+}
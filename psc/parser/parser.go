@@ -2,6 +2,8 @@ package parser
 
 import (
 	"fmt"
+	"io"
+	"iter"
 	"strings"
 
 	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
@@ -10,6 +12,12 @@ import (
 type Parser struct {
 	buffer []dt.Token
 	pos    int
+	lang   *dt.LanguageProfile
+	errs   []*ParseError
+	// next pulls the following token when the parser reads from a
+	// sequence; nil once it is exhausted or when parsing a slice.
+	next func() (dt.Token, bool)
+	stop func()
 }
 
 type ParseError struct {
@@ -19,14 +27,20 @@ type ParseError struct {
 	Tips     string
 	Got      *dt.Token
 	Expected []dt.TokenType
+	// File names the included file the error is in, if it is not in the
+	// file being compiled.
+	File string
 }
 
-func reconstruct_source(tokens []dt.Token, line int) string {
+func reconstruct_source(tokens []dt.Token, file string, line int) string {
 	var sb strings.Builder
 	currentLine := max(1, line-1)
 	currenCol := 1
 	foundLine := false
 	for _, t := range tokens {
+		if t.File != file {
+			continue
+		}
 		if t.Line < max(1, line-1) {
 			continue
 		}
@@ -50,6 +64,11 @@ func reconstruct_source(tokens []dt.Token, line int) string {
 }
 
 func (e *ParseError) Error() string {
+	got := fmt.Sprintf("token '%s'", e.Got.Lexeme)
+	if e.Got.Type == dt.EOF {
+		got = "end of file"
+	}
+
 	expected := ""
 	if len(e.Expected) > 0 {
 		for i, t := range e.Expected {
@@ -61,30 +80,46 @@ func (e *ParseError) Error() string {
 		expected = fmt.Sprintf("(expected: %s)", expected)
 	}
 
+	where := fmt.Sprintf("Line %d, Col %d", e.Line, e.Col)
+	if e.File != "" {
+		where = fmt.Sprintf("File %s, %s", e.File, where)
+	}
+
 	if e.Tips != "" {
 		return fmt.Sprintf(`
-Line %d, Col %d
 %s
 %s
-SyntaxError: Unexpected token '%s' %s
+%s
+SyntaxError: Unexpected %s %s
 %s
 `,
-			e.Line, e.Col, reconstruct_source(e.buffer, e.Line), strings.Repeat(" ", e.Col-1+3)+"^", e.Got.Lexeme, expected, e.Tips)
+			where, reconstruct_source(e.buffer, e.Got.File, e.Line), strings.Repeat(" ", e.Col-1+3)+"^", got, expected, e.Tips)
 	} else {
 		return fmt.Sprintf(`
-Line %d, Col %d
 %s
 %s
-SyntaxError: Unexpected token '%s' %s
+%s
+SyntaxError: Unexpected %s %s
 `,
-			e.Line, e.Col, reconstruct_source(e.buffer, e.Line), strings.Repeat(" ", e.Col-1+3)+"^", e.Got.Lexeme, expected)
+			where, reconstruct_source(e.buffer, e.Got.File, e.Line), strings.Repeat(" ", e.Col-1+3)+"^", got, expected)
+	}
+}
+
+// PrintErrors writes errs to w, stopping after limit of them (0 prints all).
+func PrintErrors(w io.Writer, errs []*ParseError, limit int) {
+	for i, err := range errs {
+		if limit > 0 && i == limit {
+			fmt.Fprintf(w, "\n... and %d more syntax error(s)\n", len(errs)-limit)
+			return
+		}
+		fmt.Fprint(w, err.Error())
 	}
 }
 
 func (p *Parser) createParseError(expectedType dt.TokenType, tips string) error {
-	curr := p.buffer[p.pos]
+	curr := *p.peek()
 	return &ParseError{
-		buffer:   p.buffer,
+		buffer:   p.context(curr.Line),
 		Line:     curr.Line,
 		Col:      curr.Col,
 		Tips:     tips,
@@ -94,9 +129,9 @@ func (p *Parser) createParseError(expectedType dt.TokenType, tips string) error
 }
 
 func (p *Parser) createParseErrorMany(expectedType []dt.TokenType, tips string) error {
-	curr := p.buffer[p.pos]
+	curr := *p.peek()
 	return &ParseError{
-		buffer:   p.buffer,
+		buffer:   p.context(curr.Line),
 		Line:     curr.Line,
 		Col:      curr.Col,
 		Tips:     tips,
@@ -105,22 +140,186 @@ func (p *Parser) createParseErrorMany(expectedType []dt.TokenType, tips string)
 	}
 }
 
-func New(tokens []dt.Token) *Parser {
+func New(tokens []dt.Token, lang *dt.LanguageProfile) *Parser {
 	return &Parser{
 		buffer: tokens,
 		pos:    0,
+		lang:   lang,
+	}
+}
+
+// NewFromSeq parses tokens pulled from seq as the parser needs them, so
+// lexing and parsing can run together. Comments must already be folded
+// into trivia, as lexer.WithTrivia does, or filtered out, as for New.
+// Tokens the parser is done with are dropped, so it only holds the ones
+// the tree is built from. Parse pulls seq to the end even when the program
+// stops early, so whatever produces the tokens sees all of its input.
+func NewFromSeq(seq iter.Seq[dt.Token], lang *dt.LanguageProfile) *Parser {
+	next, stop := iter.Pull(seq)
+	return &Parser{
+		lang: lang,
+		next: next,
+		stop: stop,
+	}
+}
+
+// fill pulls tokens until the one n places after the current position is
+// buffered, and reports whether it exists.
+func (p *Parser) fill(n int) bool {
+	for p.pos+n >= len(p.buffer) && p.pull() {
+	}
+	return p.pos+n < len(p.buffer)
+}
+
+// pull appends the next token of the sequence to the buffer, making room
+// for it first if the buffer is full, and reports whether there was one.
+func (p *Parser) pull() bool {
+	if p.next == nil {
+		return false
+	}
+
+	if len(p.buffer) == cap(p.buffer) {
+		p.discard()
+	}
+
+	tok, ok := p.next()
+	if !ok {
+		p.next = nil
+		return false
+	}
+	p.buffer = append(p.buffer, tok)
+	return true
+}
+
+// discard drops the consumed tokens of a streaming parser, except those on
+// the line of the last one and the line before, which error messages
+// quote. It only runs when the buffer is full, and leaves it to grow when
+// less than half of it could go.
+func (p *Parser) discard() {
+	if p.stop == nil || p.pos == 0 {
+		return
+	}
+
+	last := p.buffer[p.pos-1]
+	drop := p.pos
+	for drop > 0 && p.buffer[drop-1].File == last.File && p.buffer[drop-1].Line >= last.Line-1 {
+		drop--
+	}
+
+	if drop < len(p.buffer)/2 {
+		return
+	}
+
+	// A new array, since errors reported so far still refer to the old one.
+	rest := make([]dt.Token, len(p.buffer)-drop, cap(p.buffer))
+	copy(rest, p.buffer[drop:])
+	p.buffer = rest
+	p.pos -= drop
+}
+
+// take moves past curr, the current token, and returns it for the parse
+// tree. A streaming parser hands out a copy, so the tree does not keep
+// alive the buffer the token was pulled into.
+func (p *Parser) take(curr *dt.Token) *dt.Token {
+	p.pos++
+	if p.stop != nil {
+		tok := *curr
+		return &tok
 	}
+	return curr
+}
+
+// context returns the buffered tokens, first pulling the rest of line so
+// that errors on it can show the whole line.
+func (p *Parser) context(line int) []dt.Token {
+	for (len(p.buffer) == 0 || p.buffer[len(p.buffer)-1].Line <= line) && p.pull() {
+	}
+	return p.buffer
+}
+
+func (p *Parser) kw(k dt.Keyword) string {
+	return p.lang.Lexeme(k)
 }
 
+// peek returns the current token, or an EOF token positioned right after the
+// last one once the buffer is exhausted.
 func (p *Parser) peek() *dt.Token {
-	return &p.buffer[p.pos]
+	if p.fill(0) {
+		return &p.buffer[p.pos]
+	}
+
+	eof := dt.Token{Type: dt.EOF, Line: 1, Col: 1, EndLine: 1, EndCol: 1}
+	if len(p.buffer) > 0 {
+		last := p.buffer[len(p.buffer)-1]
+		eof.Line = last.Line
+		eof.Col = last.Col + len(last.Lexeme)
+		eof.Offset = last.EndOffset
+		eof.File = last.File
+		eof.EndOffset, eof.EndLine, eof.EndCol = last.EndOffset, eof.Line, eof.Col
+	}
+
+	return &eof
+}
+
+// report records a syntax error. A second error at the same position is
+// dropped, since it is almost always a consequence of the first one.
+func (p *Parser) report(err error) {
+	parseErr, ok := err.(*ParseError)
+	if !ok {
+		parseErr = &ParseError{
+			buffer: p.context(p.peek().Line),
+			Line:   p.peek().Line,
+			Col:    p.peek().Col,
+			Tips:   err.Error(),
+			Got:    p.peek(),
+		}
+	}
+
+	if n := len(p.errs); n > 0 && p.errs[n-1].Line == parseErr.Line && p.errs[n-1].Col == parseErr.Col {
+		return
+	}
+
+	p.errs = append(p.errs, parseErr)
+}
+
+// atSync reports whether the current token is in the synchronization set:
+// ';', the end or until keyword, the start of a block or declaration, the
+// implementation of a unit, or end of file.
+func (p *Parser) atSync() bool {
+	curr := p.peek()
+
+	switch curr.Type {
+	case dt.EOF, dt.SEMICOLON:
+		return true
+	case dt.KEYWORD:
+		keyword, ok := p.lang.Keyword(curr.Key)
+		if !ok {
+			return false
+		}
+
+		switch keyword {
+		case dt.KW_END, dt.KW_UNTIL, dt.KW_BEGIN, dt.KW_CONST, dt.KW_TYPE, dt.KW_VAR, dt.KW_PROCEDURE, dt.KW_FUNCTION, dt.KW_IMPLEMENTATION:
+			return true
+		}
+	}
+
+	return false
+}
+
+// synchronize records err and skips tokens until one in the
+// synchronization set is reached.
+func (p *Parser) synchronize(err error) {
+	p.report(err)
+
+	for !p.atSync() {
+		p.pos++
+	}
 }
 
 func (p *Parser) consume(expectedType dt.TokenType) *dt.Token {
 	curr := p.peek()
 	if curr.Type == expectedType {
-		p.pos++
-		return curr
+		return p.take(curr)
 	}
 	return nil
 }
@@ -129,8 +328,7 @@ func (p *Parser) consumeMany(expectedType []dt.TokenType) *dt.Token {
 	curr := p.peek()
 	for _, tokenType := range expectedType {
 		if curr.Type == tokenType {
-			p.pos++
-			return curr
+			return p.take(curr)
 		}
 	}
 	return nil
@@ -138,9 +336,8 @@ func (p *Parser) consumeMany(expectedType []dt.TokenType) *dt.Token {
 
 func (p *Parser) consumeExact(expectedType dt.TokenType, expectedLexeme string) *dt.Token {
 	curr := p.peek()
-	if curr.Type == expectedType && curr.Lexeme == expectedLexeme {
-		p.pos++
-		return curr
+	if curr.Type == expectedType && curr.Key == expectedLexeme {
+		return p.take(curr)
 	}
 
 	return nil
@@ -153,77 +350,288 @@ func (p *Parser) match(expectedType dt.TokenType) bool {
 
 func (p *Parser) matchExact(expectedType dt.TokenType, expectedLexeme string) bool {
 	curr := p.peek()
-	return curr.Type == expectedType && curr.Lexeme == expectedLexeme
+	return curr.Type == expectedType && curr.Key == expectedLexeme
 }
 
-func (p *Parser) Parse() (*dt.ParseTree, error) {
-	tree, err := p.parseProgram()
-	return tree, err
+// Parse parses the whole token buffer. Syntax errors do not stop the parser:
+// it recovers at the next synchronizing token and keeps going, so the
+// returned tree may be partial and every error found is returned.
+func (p *Parser) Parse() (*dt.ParseTree, []*ParseError) {
+	if p.stop != nil {
+		defer func() {
+			for p.next != nil {
+				if _, ok := p.next(); !ok {
+					p.next = nil
+				}
+			}
+			p.stop()
+		}()
+	}
+
+	p.errs = nil
+
+	var tree *dt.ParseTree
+	if p.matchExact(dt.KEYWORD, p.kw(dt.KW_UNIT)) {
+		tree = p.parseUnit()
+	} else {
+		tree = p.parseProgram()
+	}
+
+	tree.ComputeSpans()
+	return tree, p.errs
 }
 
-func (p *Parser) parseProgram() (*dt.ParseTree, error) {
+func (p *Parser) parseProgram() *dt.ParseTree {
+	programTree := dt.ParseTree{
+		RootType:   dt.PROGRAM_NODE,
+		TokenValue: nil,
+		Children:   make([]dt.ParseTree, 0),
+	}
+
 	headerTree, err := p.parseProgramHeader()
+	if err != nil {
+		p.synchronize(err)
+		p.consume(dt.SEMICOLON)
+	} else {
+		programTree.Children = append(programTree.Children, *headerTree)
+	}
 
+	usesTree, err := p.parseUsesClause()
 	if err != nil {
-		return nil, err
+		p.synchronize(err)
+		p.consume(dt.SEMICOLON)
+	} else if usesTree != nil {
+		programTree.Children = append(programTree.Children, *usesTree)
 	}
 
 	declarationTree, err := p.parseDeclarationPart()
-
 	if err != nil {
-		return nil, err
+		p.synchronize(err)
 	}
+	programTree.Children = append(programTree.Children, *declarationTree)
 
 	compoundTree, err := p.parseCompoundStatement()
 	if err != nil {
-		return nil, err
+		p.report(err)
+		return &programTree
 	}
+	programTree.Children = append(programTree.Children, *compoundTree)
+
+	p.parseFinalDot(&programTree, "program")
+	return &programTree
+}
 
+// parseFinalDot parses the dot that ends a program or a unit, which must be
+// the last token.
+func (p *Parser) parseFinalDot(tree *dt.ParseTree, what string) {
 	dotToken := p.consume(dt.DOT)
 	if dotToken == nil {
-		return nil, p.createParseError(dt.DOT, "program must end with a dot (.)")
+		p.report(p.createParseError(dt.DOT, what+" must end with a dot (.)"))
+		return
 	}
+	tree.Children = append(tree.Children, dt.ParseTree{
+		RootType:   dt.TOKEN_NODE,
+		TokenValue: dotToken,
+		Children:   make([]dt.ParseTree, 0),
+	})
 
-	if p.pos < len(p.buffer) {
+	if p.fill(0) {
 		curr := p.peek()
-		return nil, &ParseError{
-			buffer: p.buffer,
+		p.report(&ParseError{
+			buffer: p.context(curr.Line),
 			Line:   curr.Line,
 			Col:    curr.Col,
-			Tips:   "unexpected token after program end (.)",
+			Tips:   "unexpected token after " + what + " end (.)",
 			Got:    curr,
+		})
+	}
+}
+
+// parseUsesClause parses the units a program or a unit interface uses, if
+// it names any.
+func (p *Parser) parseUsesClause() (*dt.ParseTree, error) {
+	usesToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_USES))
+	if usesToken == nil {
+		return nil, nil
+	}
+
+	if !p.match(dt.IDENTIFIER) {
+		return nil, p.createParseError(dt.IDENTIFIER, fmt.Sprintf("expected unit name after '%s'", p.kw(dt.KW_USES)))
+	}
+
+	units, err := p.parseIdentifierList()
+	if err != nil {
+		return nil, err
+	}
+
+	semicolon := p.consume(dt.SEMICOLON)
+	if semicolon == nil {
+		return nil, p.createParseError(dt.SEMICOLON, "unit names must be separated by commas and end with ;")
+	}
+
+	return &dt.ParseTree{
+		RootType: dt.USES_CLAUSE_NODE,
+		Children: []dt.ParseTree{
+			{RootType: dt.TOKEN_NODE, TokenValue: usesToken},
+			*units,
+			{RootType: dt.TOKEN_NODE, TokenValue: semicolon},
+		},
+	}, nil
+}
+
+// parseUnit parses a unit: its interface, which is what the programs using
+// it see, its implementation, and an optional initialization block that
+// runs before them.
+func (p *Parser) parseUnit() *dt.ParseTree {
+	unitTree := dt.ParseTree{
+		RootType: dt.UNIT_NODE,
+		Children: make([]dt.ParseTree, 0),
+	}
+
+	headerTree, err := p.parseUnitHeader()
+	if err != nil {
+		p.synchronize(err)
+		p.consume(dt.SEMICOLON)
+	} else {
+		unitTree.Children = append(unitTree.Children, *headerTree)
+	}
+
+	interfaceTree, err := p.parseInterfacePart()
+	if err != nil {
+		p.report(err)
+		return &unitTree
+	}
+	unitTree.Children = append(unitTree.Children, *interfaceTree)
+
+	implementationTree, err := p.parseImplementationPart()
+	if err != nil {
+		p.report(err)
+		return &unitTree
+	}
+	unitTree.Children = append(unitTree.Children, *implementationTree)
+
+	if p.matchExact(dt.KEYWORD, p.kw(dt.KW_BEGIN)) {
+		compoundTree, err := p.parseCompoundStatement()
+		if err != nil {
+			p.report(err)
+			return &unitTree
 		}
+		unitTree.Children = append(unitTree.Children, *compoundTree)
+	} else if endToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_END)); endToken != nil {
+		unitTree.Children = append(unitTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: endToken})
+	} else {
+		p.report(p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' or '%s' to end the unit", p.kw(dt.KW_BEGIN), p.kw(dt.KW_END))))
+		return &unitTree
 	}
 
-	programTree := dt.ParseTree{
-		RootType:   dt.PROGRAM_NODE,
-		TokenValue: nil,
+	p.parseFinalDot(&unitTree, "unit")
+	return &unitTree
+}
+
+func (p *Parser) parseUnitHeader() (*dt.ParseTree, error) {
+	unitToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_UNIT))
+	if unitToken == nil {
+		return nil, p.createParseError(dt.KEYWORD, "all units must start with unit keyword")
+	}
+
+	identifier := p.consume(dt.IDENTIFIER)
+	if identifier == nil {
+		return nil, p.createParseError(dt.IDENTIFIER, "unit name must only use alphanumerical characters and underscores")
+	}
+
+	semicolon := p.consume(dt.SEMICOLON)
+	if semicolon == nil {
+		return nil, p.createParseError(dt.SEMICOLON, "unit name must be a single word and strictly end with ;")
+	}
+
+	return &dt.ParseTree{
+		RootType: dt.UNIT_HEADER_NODE,
 		Children: []dt.ParseTree{
-			*headerTree,
-			*declarationTree,
-			*compoundTree,
-			{
-				RootType:   dt.TOKEN_NODE,
-				TokenValue: dotToken,
-				Children:   make([]dt.ParseTree, 0),
-			},
+			{RootType: dt.TOKEN_NODE, TokenValue: unitToken},
+			{RootType: dt.TOKEN_NODE, TokenValue: identifier},
+			{RootType: dt.TOKEN_NODE, TokenValue: semicolon},
 		},
+	}, nil
+}
+
+// parseInterfacePart parses what a unit exports. It has the sections of a
+// declaration part, but gives only the headings of its subprograms.
+func (p *Parser) parseInterfacePart() (*dt.ParseTree, error) {
+	interfaceToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_INTERFACE))
+	if interfaceToken == nil {
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' after the unit header", p.kw(dt.KW_INTERFACE)))
+	}
+
+	interfaceTree := dt.ParseTree{
+		RootType: dt.INTERFACE_PART_NODE,
+		Children: []dt.ParseTree{{RootType: dt.TOKEN_NODE, TokenValue: interfaceToken}},
+	}
+
+	usesTree, err := p.parseUsesClause()
+	if err != nil {
+		p.synchronize(err)
+		p.consume(dt.SEMICOLON)
+	} else if usesTree != nil {
+		interfaceTree.Children = append(interfaceTree.Children, *usesTree)
 	}
 
-	return &programTree, nil
+	if err := p.parseDataDeclarations(&interfaceTree); err != nil {
+		p.synchronize(err)
+	}
+
+	for {
+		heading, err := p.parseSubprogramHeading()
+
+		if heading == nil && err == nil {
+			break
+		}
+
+		if err != nil {
+			p.synchronize(err)
+			p.consume(dt.SEMICOLON)
+			continue
+		}
+
+		interfaceTree.Children = append(interfaceTree.Children, *heading)
+	}
+
+	return &interfaceTree, nil
+}
+
+func (p *Parser) parseImplementationPart() (*dt.ParseTree, error) {
+	implementationToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_IMPLEMENTATION))
+	if implementationToken == nil {
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' after the interface part", p.kw(dt.KW_IMPLEMENTATION)))
+	}
+
+	declarations, err := p.parseDeclarationPart()
+	if err != nil {
+		p.synchronize(err)
+	}
+
+	return &dt.ParseTree{
+		RootType: dt.IMPLEMENTATION_PART_NODE,
+		Children: []dt.ParseTree{
+			{RootType: dt.TOKEN_NODE, TokenValue: implementationToken},
+			*declarations,
+		},
+	}, nil
 }
 
 func (p *Parser) parseProgramHeader() (*dt.ParseTree, error) {
 
-	if p.consumeExact(dt.KEYWORD, "program") == nil {
+	programToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_PROGRAM))
+	if programToken == nil {
 		return nil, p.createParseError(dt.KEYWORD, "all programs must start with program keyword")
 	}
 
-	if p.consume(dt.IDENTIFIER) == nil {
+	identifier := p.consume(dt.IDENTIFIER)
+	if identifier == nil {
 		return nil, p.createParseError(dt.IDENTIFIER, "program name must only use alphanumerical characters and underscores")
 	}
 
-	if p.consume(dt.SEMICOLON) == nil {
+	semicolon := p.consume(dt.SEMICOLON)
+	if semicolon == nil {
 		return nil, p.createParseError(dt.SEMICOLON, "program name must be a single word and strictly end with ;")
 	}
 
@@ -232,15 +640,15 @@ func (p *Parser) parseProgramHeader() (*dt.ParseTree, error) {
 		TokenValue: nil,
 		Children: []dt.ParseTree{{
 			RootType:   dt.TOKEN_NODE,
-			TokenValue: &p.buffer[0],
+			TokenValue: programToken,
 			Children:   make([]dt.ParseTree, 0),
 		}, {
 			RootType:   dt.TOKEN_NODE,
-			TokenValue: &p.buffer[1],
+			TokenValue: identifier,
 			Children:   make([]dt.ParseTree, 0),
 		}, {
 			RootType:   dt.TOKEN_NODE,
-			TokenValue: &p.buffer[2],
+			TokenValue: semicolon,
 			Children:   make([]dt.ParseTree, 0),
 		}},
 	}
@@ -257,57 +665,70 @@ func (p *Parser) parseDeclarationPart() (*dt.ParseTree, error) {
 		Children:   make([]dt.ParseTree, 0),
 	}
 
+	err = p.parseDataDeclarations(&declarationTree)
+
 	for err == nil {
-		constDeclaration, newErr := p.parseConstDeclarationPart()
-		if constDeclaration == nil && newErr == nil {
+		subprogramDeclaration, newErr := p.parseSubprogramDeclaration()
+
+		if subprogramDeclaration == nil && newErr == nil {
 			break
 		}
-		err = newErr
-		if err == nil {
-			declarationTree.Children = append(declarationTree.Children, *constDeclaration)
+
+		if newErr != nil {
+			p.synchronize(newErr)
+			p.consume(dt.SEMICOLON)
+			continue
 		}
+
+		declarationTree.Children = append(declarationTree.Children, *subprogramDeclaration)
 	}
 
+	return &declarationTree, err
+}
+
+// parseDataDeclarations adds the const, type and var sections, in that
+// order, to tree.
+func (p *Parser) parseDataDeclarations(tree *dt.ParseTree) error {
+	var err error
+
 	for err == nil {
-		typeDeclaration, newErr := p.parseTypeDeclarationPart()
-		if typeDeclaration == nil && newErr == nil {
+		constDeclaration, newErr := p.parseConstDeclarationPart()
+		if constDeclaration == nil && newErr == nil {
 			break
 		}
 		err = newErr
 		if err == nil {
-			declarationTree.Children = append(declarationTree.Children, *typeDeclaration)
+			tree.Children = append(tree.Children, *constDeclaration)
 		}
 	}
 
 	for err == nil {
-		varDeclaration, newErr := p.parseVarDeclarationPart()
-		if varDeclaration == nil && newErr == nil {
+		typeDeclaration, newErr := p.parseTypeDeclarationPart()
+		if typeDeclaration == nil && newErr == nil {
 			break
 		}
 		err = newErr
 		if err == nil {
-			declarationTree.Children = append(declarationTree.Children, *varDeclaration)
+			tree.Children = append(tree.Children, *typeDeclaration)
 		}
 	}
 
 	for err == nil {
-		subprogramDeclaration, newErr := p.parseSubprogramDeclaration()
-
-		if subprogramDeclaration == nil && newErr == nil {
+		varDeclaration, newErr := p.parseVarDeclarationPart()
+		if varDeclaration == nil && newErr == nil {
 			break
 		}
-
 		err = newErr
 		if err == nil {
-			declarationTree.Children = append(declarationTree.Children, *subprogramDeclaration)
+			tree.Children = append(tree.Children, *varDeclaration)
 		}
 	}
 
-	return &declarationTree, err
+	return err
 }
 
 func (p *Parser) parseConstDeclarationPart() (*dt.ParseTree, error) {
-	expectedConst := p.consumeExact(dt.KEYWORD, "konstanta")
+	expectedConst := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_CONST))
 	if expectedConst == nil {
 		return nil, nil
 	}
@@ -325,14 +746,17 @@ func (p *Parser) parseConstDeclarationPart() (*dt.ParseTree, error) {
 	}
 
 	if !p.match(dt.IDENTIFIER) {
-		return nil, p.createParseError(dt.IDENTIFIER, "expected at least one const definition")
+		p.report(p.createParseError(dt.IDENTIFIER, "expected at least one const definition"))
+		return &constDeclaration, nil
 	}
 
 	for p.match(dt.IDENTIFIER) {
 		constDefintion, err := p.parseConstDeclaration()
 
 		if err != nil {
-			return nil, err
+			p.synchronize(err)
+			p.consume(dt.SEMICOLON)
+			continue
 		}
 
 		constDeclaration.Children = append(constDeclaration.Children,
@@ -396,7 +820,7 @@ func (p *Parser) parseConstDeclaration() (*dt.ParseTree, error) {
 }
 
 func (p *Parser) parseTypeDeclarationPart() (*dt.ParseTree, error) {
-	expectedType := p.consumeExact(dt.KEYWORD, "tipe")
+	expectedType := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_TYPE))
 	if expectedType == nil {
 		return nil, nil
 	}
@@ -414,14 +838,17 @@ func (p *Parser) parseTypeDeclarationPart() (*dt.ParseTree, error) {
 	}
 
 	if !p.match(dt.IDENTIFIER) {
-		return nil, p.createParseError(dt.IDENTIFIER, "expected at least one type declaration")
+		p.report(p.createParseError(dt.IDENTIFIER, "expected at least one type declaration"))
+		return &typeDeclarationPart, nil
 	}
 
 	for p.match(dt.IDENTIFIER) {
 		typeDeclaration, err := p.parseTypeDeclaration()
 
 		if err != nil {
-			return nil, err
+			p.synchronize(err)
+			p.consume(dt.SEMICOLON)
+			continue
 		}
 
 		typeDeclarationPart.Children = append(typeDeclarationPart.Children,
@@ -447,7 +874,7 @@ func (p *Parser) parseTypeDeclaration() (*dt.ParseTree, error) {
 	var parsedType *dt.ParseTree
 	var err error
 
-	if p.matchExact(dt.KEYWORD, "rekaman") {
+	if p.matchExact(dt.KEYWORD, p.kw(dt.KW_RECORD)) {
 		parsedType, err = p.parseRecordType()
 	} else {
 		parsedType, err = p.parseType()
@@ -489,7 +916,7 @@ func (p *Parser) parseTypeDeclaration() (*dt.ParseTree, error) {
 }
 
 func (p *Parser) parseVarDeclarationPart() (*dt.ParseTree, error) {
-	expectedVar := p.consumeExact(dt.KEYWORD, "variabel")
+	expectedVar := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_VAR))
 	if expectedVar == nil {
 		return nil, nil
 	}
@@ -507,14 +934,17 @@ func (p *Parser) parseVarDeclarationPart() (*dt.ParseTree, error) {
 	}
 
 	if !p.match(dt.IDENTIFIER) {
-		return nil, p.createParseError(dt.IDENTIFIER, "expected at least one variable declaration")
+		p.report(p.createParseError(dt.IDENTIFIER, "expected at least one variable declaration"))
+		return &varDeclaration, nil
 	}
 
 	for p.match(dt.IDENTIFIER) {
 		variableDeclaration, err := p.parseVarDeclaration()
 
 		if err != nil {
-			return nil, err
+			p.synchronize(err)
+			p.consume(dt.SEMICOLON)
+			continue
 		}
 
 		varDeclaration.Children = append(varDeclaration.Children,
@@ -626,27 +1056,39 @@ func (p *Parser) parseType() (*dt.ParseTree, error) {
 			TokenValue: p.consume(dt.IDENTIFIER),
 			Children:   make([]dt.ParseTree, 0),
 		}
+
+		// A type exported by a unit can be named through it.
+		if p.match(dt.DOT) {
+			dot := p.consume(dt.DOT)
+			name := p.consume(dt.IDENTIFIER)
+			if name == nil {
+				return nil, p.createParseError(dt.IDENTIFIER, "expected type name after '.'")
+			}
+
+			typeTree.Children = append(typeTree.Children,
+				dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: dot},
+				dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: name},
+			)
+		}
 	} else {
 		if !p.match(dt.KEYWORD) {
 			return nil, p.createParseError(dt.KEYWORD, "expected type")
 		}
 
-		switch p.peek().Lexeme {
-		case "integer":
+		switch keyword, _ := p.lang.Keyword(p.peek().Key); keyword {
+		case dt.KW_INTEGER:
 			fallthrough
-		case "real":
+		case dt.KW_REAL:
 			fallthrough
-		case "boolean":
+		case dt.KW_BOOLEAN:
 			fallthrough
-		case "char":
+		case dt.KW_CHAR:
 			typeTree.Children[0] = dt.ParseTree{
 				RootType:   dt.TOKEN_NODE,
 				TokenValue: p.consume(dt.KEYWORD),
 				Children:   make([]dt.ParseTree, 0),
 			}
-		case "larik":
-			fallthrough
-		case "array":
+		case dt.KW_ARRAY:
 			arrayTypeTree, err := p.parseArrayType()
 
 			if err != nil {
@@ -662,14 +1104,14 @@ func (p *Parser) parseType() (*dt.ParseTree, error) {
 
 func (p *Parser) parseArrayType() (*dt.ParseTree, error) {
 
-	expectedLarik := p.consumeExact(dt.KEYWORD, "larik")
+	expectedLarik := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_ARRAY))
 	if expectedLarik == nil {
-		return nil, p.createParseError(dt.KEYWORD, "expected larik keyword")
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected %s keyword", p.kw(dt.KW_ARRAY)))
 	}
 
 	expectedLB := p.consume(dt.LBRACKET)
 	if expectedLB == nil {
-		return nil, p.createParseError(dt.LBRACKET, "expected [ after larik")
+		return nil, p.createParseError(dt.LBRACKET, fmt.Sprintf("expected [ after %s", p.kw(dt.KW_ARRAY)))
 	}
 
 	rangeTree, err := p.parseRange()
@@ -680,12 +1122,12 @@ func (p *Parser) parseArrayType() (*dt.ParseTree, error) {
 
 	expectedRB := p.consume(dt.RBRACKET)
 	if expectedRB == nil {
-		return nil, p.createParseError(dt.RBRACKET, "expected ] after larik range")
+		return nil, p.createParseError(dt.RBRACKET, fmt.Sprintf("expected ] after %s range", p.kw(dt.KW_ARRAY)))
 	}
 
-	expectedDari := p.consumeExact(dt.KEYWORD, "dari")
+	expectedDari := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_OF))
 	if expectedDari == nil {
-		return nil, p.createParseError(dt.KEYWORD, "expected 'dari' after ]")
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' after ]", p.kw(dt.KW_OF)))
 	}
 
 	typeTree, err := p.parseType()
@@ -757,20 +1199,26 @@ func (p *Parser) parseRange() (*dt.ParseTree, error) {
 }
 
 func (p *Parser) parseStatement() (*dt.ParseTree, error) {
-	if p.matchExact(dt.KEYWORD, "mulai") {
+	if p.matchExact(dt.KEYWORD, p.kw(dt.KW_BEGIN)) {
 		return p.parseCompoundStatement()
 	}
-	if p.matchExact(dt.KEYWORD, "jika") {
+	if p.matchExact(dt.KEYWORD, p.kw(dt.KW_IF)) {
 		return p.parseIfStatement()
 	}
-	if p.matchExact(dt.KEYWORD, "selama") {
+	if p.matchExact(dt.KEYWORD, p.kw(dt.KW_WHILE)) {
 		return p.parseWhileStatement()
 	}
-	if p.matchExact(dt.KEYWORD, "untuk") {
+	if p.matchExact(dt.KEYWORD, p.kw(dt.KW_FOR)) {
 		return p.parseForStatement()
 	}
+	if p.matchExact(dt.KEYWORD, p.kw(dt.KW_CASE)) {
+		return p.parseCaseStatement()
+	}
+	if p.matchExact(dt.KEYWORD, p.kw(dt.KW_REPEAT)) {
+		return p.parseRepeatStatement()
+	}
 	if p.match(dt.IDENTIFIER) {
-		if p.pos+1 < len(p.buffer) && (p.buffer[p.pos+1].Type == dt.ASSIGN_OPERATOR || p.buffer[p.pos+1].Type == dt.LBRACKET || p.buffer[p.pos+1].Type == dt.DOT) {
+		if p.fill(1) && (p.buffer[p.pos+1].Type == dt.ASSIGN_OPERATOR || p.buffer[p.pos+1].Type == dt.LBRACKET || p.buffer[p.pos+1].Type == dt.DOT) && !p.qualifiedCall(true) {
 			return p.parseAssignmentStatement()
 		} else {
 			return p.parseSubprogramCall()
@@ -778,10 +1226,32 @@ func (p *Parser) parseStatement() (*dt.ParseTree, error) {
 	}
 	return nil, p.createParseErrorMany(
 		[]dt.TokenType{dt.KEYWORD, dt.IDENTIFIER},
-		"expected a statement (jika, selama, untuk, mulai, or identifier)",
+		fmt.Sprintf("expected a statement (%s, %s, %s, %s, %s, %s, or identifier)", p.kw(dt.KW_IF), p.kw(dt.KW_WHILE), p.kw(dt.KW_REPEAT), p.kw(dt.KW_FOR), p.kw(dt.KW_CASE), p.kw(dt.KW_BEGIN)),
 	)
 }
 
+// qualifiedCall reports whether the tokens ahead call a subprogram through
+// the unit that exports it, as in unit.name(...). A statement can also
+// call one without arguments, as unit.name.
+func (p *Parser) qualifiedCall(statement bool) bool {
+	if !p.fill(2) || p.buffer[p.pos].Type != dt.IDENTIFIER || p.buffer[p.pos+1].Type != dt.DOT || p.buffer[p.pos+2].Type != dt.IDENTIFIER {
+		return false
+	}
+
+	if !p.fill(3) {
+		return statement
+	}
+
+	switch p.buffer[p.pos+3].Type {
+	case dt.LPARENTHESIS:
+		return true
+	case dt.ASSIGN_OPERATOR, dt.LBRACKET, dt.DOT:
+		return false
+	}
+
+	return statement
+}
+
 func (p *Parser) parseSubprogramDeclaration() (*dt.ParseTree, error) {
 	procTree, err := p.parseProcedureDeclaration()
 	if err != nil {
@@ -808,8 +1278,48 @@ func (p *Parser) parseSubprogramDeclaration() (*dt.ParseTree, error) {
 	return nil, nil
 }
 
+// parseSubprogramHeading parses a subprogram heading in a unit interface.
+// Its body is declared in the implementation.
+func (p *Parser) parseSubprogramHeading() (*dt.ParseTree, error) {
+	procTree, err := p.parseProcedureHeading()
+	if procTree != nil || err != nil {
+		return procTree, err
+	}
+
+	return p.parseFunctionHeading()
+}
+
 func (p *Parser) parseProcedureDeclaration() (*dt.ParseTree, error) {
-	prosedurToken := p.consumeExact(dt.KEYWORD, "prosedur")
+	procTree, err := p.parseProcedureHeading()
+	if procTree == nil || err != nil {
+		return nil, err
+	}
+	procTree.RootType = dt.PROCEDURE_DECLARATION_NODE
+
+	declarations, err := p.parseDeclarationPart()
+	if err != nil {
+		p.synchronize(err)
+	}
+	procTree.Children = append(procTree.Children, *declarations)
+
+	compoundStmt, err := p.parseCompoundStatement()
+	if err != nil {
+		return nil, err
+	}
+	procTree.Children = append(procTree.Children, *compoundStmt)
+
+	semicolon2 := p.consume(dt.SEMICOLON)
+	if semicolon2 == nil {
+		p.report(p.createParseError(dt.SEMICOLON, "expected ';' after procedure block"))
+		return procTree, nil
+	}
+	procTree.Children = append(procTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: semicolon2})
+
+	return procTree, nil
+}
+
+func (p *Parser) parseProcedureHeading() (*dt.ParseTree, error) {
+	prosedurToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_PROCEDURE))
 	if prosedurToken == nil {
 		return nil, nil
 	}
@@ -820,50 +1330,67 @@ func (p *Parser) parseProcedureDeclaration() (*dt.ParseTree, error) {
 	}
 
 	procTree := dt.ParseTree{
-		RootType: dt.PROCEDURE_DECLARATION_NODE,
+		RootType: dt.PROCEDURE_HEADING_NODE,
 		Children: []dt.ParseTree{
 			{RootType: dt.TOKEN_NODE, TokenValue: prosedurToken},
 			{RootType: dt.TOKEN_NODE, TokenValue: identifier},
 		},
 	}
 
+	// Errors in the header are recovered here so that the body, if any, is
+	// still parsed as the body of this procedure.
 	if p.match(dt.LPARENTHESIS) {
 		paramList, err := p.parseFormalParameterList()
 		if err != nil {
-			return nil, err
+			p.synchronize(err)
+		} else {
+			procTree.Children = append(procTree.Children, *paramList)
 		}
-		procTree.Children = append(procTree.Children, *paramList)
 	}
 
 	semicolon1 := p.consume(dt.SEMICOLON)
 	if semicolon1 == nil {
-		return nil, p.createParseError(dt.SEMICOLON, "expected ';' after procedure header")
+		p.synchronize(p.createParseError(dt.SEMICOLON, "expected ';' after procedure header"))
+		semicolon1 = p.consume(dt.SEMICOLON)
+	}
+	if semicolon1 != nil {
+		procTree.Children = append(procTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: semicolon1})
+	}
+
+	return &procTree, nil
+}
+
+func (p *Parser) parseFunctionDeclaration() (*dt.ParseTree, error) {
+	funcTree, err := p.parseFunctionHeading()
+	if funcTree == nil || err != nil {
+		return nil, err
 	}
-	procTree.Children = append(procTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: semicolon1})
+	funcTree.RootType = dt.FUNCTION_DECLARATION_NODE
 
 	declarations, err := p.parseDeclarationPart()
 	if err != nil {
-		return nil, err
+		p.synchronize(err)
 	}
-	procTree.Children = append(procTree.Children, *declarations)
+	funcTree.Children = append(funcTree.Children, *declarations)
 
 	compoundStmt, err := p.parseCompoundStatement()
 	if err != nil {
 		return nil, err
 	}
-	procTree.Children = append(procTree.Children, *compoundStmt)
+	funcTree.Children = append(funcTree.Children, *compoundStmt)
 
 	semicolon2 := p.consume(dt.SEMICOLON)
 	if semicolon2 == nil {
-		return nil, p.createParseError(dt.SEMICOLON, "expected ';' after procedure block")
+		p.report(p.createParseError(dt.SEMICOLON, "expected ';' after function block"))
+		return funcTree, nil
 	}
-	procTree.Children = append(procTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: semicolon2})
+	funcTree.Children = append(funcTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: semicolon2})
 
-	return &procTree, nil
+	return funcTree, nil
 }
 
-func (p *Parser) parseFunctionDeclaration() (*dt.ParseTree, error) {
-	fungsiToken := p.consumeExact(dt.KEYWORD, "fungsi")
+func (p *Parser) parseFunctionHeading() (*dt.ParseTree, error) {
+	fungsiToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_FUNCTION))
 	if fungsiToken == nil {
 		return nil, nil
 	}
@@ -874,59 +1401,44 @@ func (p *Parser) parseFunctionDeclaration() (*dt.ParseTree, error) {
 	}
 
 	funcTree := dt.ParseTree{
-		RootType: dt.FUNCTION_DECLARATION_NODE,
+		RootType: dt.FUNCTION_HEADING_NODE,
 		Children: []dt.ParseTree{
 			{RootType: dt.TOKEN_NODE, TokenValue: fungsiToken},
 			{RootType: dt.TOKEN_NODE, TokenValue: identifier},
 		},
 	}
 
+	// Errors in the header are recovered here so that the body, if any, is
+	// still parsed as the body of this function.
 	if p.match(dt.LPARENTHESIS) {
 		paramList, err := p.parseFormalParameterList()
 		if err != nil {
-			return nil, err
+			p.synchronize(err)
+		} else {
+			funcTree.Children = append(funcTree.Children, *paramList)
 		}
-		funcTree.Children = append(funcTree.Children, *paramList)
 	}
 
 	colonToken := p.consume(dt.COLON)
 	if colonToken == nil {
-		return nil, p.createParseError(dt.COLON, "expected ':' for function return type")
-	}
-
-	returnType, err := p.parseType()
-	if err != nil {
-		return nil, err
+		p.synchronize(p.createParseError(dt.COLON, "expected ':' for function return type"))
+	} else if returnType, err := p.parseType(); err != nil {
+		p.synchronize(err)
+	} else {
+		funcTree.Children = append(funcTree.Children,
+			dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: colonToken},
+			*returnType,
+		)
 	}
 
 	semicolon1 := p.consume(dt.SEMICOLON)
 	if semicolon1 == nil {
-		return nil, p.createParseError(dt.SEMICOLON, "expected ';' after function header")
-	}
-
-	funcTree.Children = append(funcTree.Children,
-		dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: colonToken},
-		*returnType,
-		dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: semicolon1},
-	)
-
-	declarations, err := p.parseDeclarationPart()
-	if err != nil {
-		return nil, err
+		p.synchronize(p.createParseError(dt.SEMICOLON, "expected ';' after function header"))
+		semicolon1 = p.consume(dt.SEMICOLON)
 	}
-	funcTree.Children = append(funcTree.Children, *declarations)
-
-	compoundStmt, err := p.parseCompoundStatement()
-	if err != nil {
-		return nil, err
-	}
-	funcTree.Children = append(funcTree.Children, *compoundStmt)
-
-	semicolon2 := p.consume(dt.SEMICOLON)
-	if semicolon2 == nil {
-		return nil, p.createParseError(dt.SEMICOLON, "expected ';' after function block")
+	if semicolon1 != nil {
+		funcTree.Children = append(funcTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: semicolon1})
 	}
-	funcTree.Children = append(funcTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: semicolon2})
 
 	return &funcTree, nil
 }
@@ -944,10 +1456,10 @@ func (p *Parser) parseFormalParameterList() (*dt.ParseTree, error) {
 		},
 	}
 	if !p.match(dt.RPARENTHESIS) {
-		if p.matchExact(dt.KEYWORD, "variabel") {
+		if p.matchExact(dt.KEYWORD, p.kw(dt.KW_VAR)) {
 			paramListTree.Children = append(paramListTree.Children, dt.ParseTree{
 				RootType:   dt.TOKEN_NODE,
-				TokenValue: p.consumeExact(dt.KEYWORD, "variabel"),
+				TokenValue: p.consumeExact(dt.KEYWORD, p.kw(dt.KW_VAR)),
 			})
 		}
 
@@ -979,10 +1491,10 @@ func (p *Parser) parseFormalParameterList() (*dt.ParseTree, error) {
 				TokenValue: semicolonToken,
 			})
 
-			if p.matchExact(dt.KEYWORD, "variabel") {
+			if p.matchExact(dt.KEYWORD, p.kw(dt.KW_VAR)) {
 				paramListTree.Children = append(paramListTree.Children, dt.ParseTree{
 					RootType:   dt.TOKEN_NODE,
-					TokenValue: p.consumeExact(dt.KEYWORD, "variabel"),
+					TokenValue: p.consumeExact(dt.KEYWORD, p.kw(dt.KW_VAR)),
 				})
 			}
 
@@ -1021,68 +1533,100 @@ func (p *Parser) parseFormalParameterList() (*dt.ParseTree, error) {
 }
 
 func (p *Parser) parseCompoundStatement() (*dt.ParseTree, error) {
-	mulaiToken := p.consumeExact(dt.KEYWORD, "mulai")
+	mulaiToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_BEGIN))
 	if mulaiToken == nil {
-		return nil, p.createParseError(dt.KEYWORD, "expected 'mulai' keyword")
-	}
-
-	stmtList, err := p.parseStatementList()
-	if err != nil {
-		return nil, err
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' keyword", p.kw(dt.KW_BEGIN)))
 	}
 
-	selesaiToken := p.consumeExact(dt.KEYWORD, "selesai")
-	if selesaiToken == nil {
-		return nil, p.createParseError(dt.KEYWORD, "expected 'selesai' keyword to end compound statement")
-	}
+	stmtList := p.parseStatementList()
 
 	compoundTree := dt.ParseTree{
 		RootType: dt.COMPOUND_STATEMENT_NODE,
 		Children: []dt.ParseTree{
 			{RootType: dt.TOKEN_NODE, TokenValue: mulaiToken},
 			*stmtList,
-			{RootType: dt.TOKEN_NODE, TokenValue: selesaiToken},
 		},
 	}
+
+	selesaiToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_END))
+	if selesaiToken == nil {
+		p.report(p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' keyword to end compound statement", p.kw(dt.KW_END))))
+		return &compoundTree, nil
+	}
+
+	compoundTree.Children = append(compoundTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: selesaiToken})
 	return &compoundTree, nil
 }
 
-func (p *Parser) parseStatementList() (*dt.ParseTree, error) {
-	stmt, err := p.parseStatement()
-	if err != nil {
-		return nil, err
+// startsStatement reports whether the current token can begin a statement.
+func (p *Parser) startsStatement() bool {
+	if p.match(dt.IDENTIFIER) {
+		return true
 	}
 
+	return p.matchExact(dt.KEYWORD, p.kw(dt.KW_BEGIN)) ||
+		p.matchExact(dt.KEYWORD, p.kw(dt.KW_IF)) ||
+		p.matchExact(dt.KEYWORD, p.kw(dt.KW_WHILE)) ||
+		p.matchExact(dt.KEYWORD, p.kw(dt.KW_FOR)) ||
+		p.matchExact(dt.KEYWORD, p.kw(dt.KW_CASE)) ||
+		p.matchExact(dt.KEYWORD, p.kw(dt.KW_REPEAT))
+}
+
+// parseStatementList never fails: a broken statement is reported and skipped
+// up to the next ';', and a statement that directly follows another one is
+// reported as a missing ';' and parsed anyway.
+func (p *Parser) parseStatementList() *dt.ParseTree {
 	stmtListTree := dt.ParseTree{
 		RootType: dt.STATEMENT_LIST_NODE,
-		Children: []dt.ParseTree{*stmt},
+		Children: make([]dt.ParseTree, 0),
 	}
 
-	for p.match(dt.SEMICOLON) {
-		semicolon := p.consume(dt.SEMICOLON)
+	stmt, err := p.parseStatement()
+	if err != nil {
+		p.synchronize(err)
+	} else {
+		stmtListTree.Children = append(stmtListTree.Children, *stmt)
+	}
+
+	for {
+		var semicolon *dt.Token
+
+		if p.match(dt.SEMICOLON) {
+			semicolon = p.consume(dt.SEMICOLON)
+
+			if p.matchExact(dt.KEYWORD, p.kw(dt.KW_END)) || p.matchExact(dt.KEYWORD, p.kw(dt.KW_UNTIL)) {
+				stmtListTree.Children = append(stmtListTree.Children, dt.ParseTree{
+					RootType:   dt.TOKEN_NODE,
+					TokenValue: semicolon,
+				})
+				break
+			}
+		} else if p.startsStatement() && !p.matchExact(dt.KEYWORD, p.kw(dt.KW_BEGIN)) {
+			p.report(p.createParseErrorMany(
+				[]dt.TokenType{dt.SEMICOLON, dt.KEYWORD},
+				fmt.Sprintf("expected ';' between statements or '%s' to end compound statement", p.kw(dt.KW_END)),
+			))
+		} else {
+			break
+		}
 
-		if p.matchExact(dt.KEYWORD, "selesai") {
+		if semicolon != nil {
 			stmtListTree.Children = append(stmtListTree.Children, dt.ParseTree{
 				RootType:   dt.TOKEN_NODE,
 				TokenValue: semicolon,
 			})
-			break
 		}
 
 		nextStmt, err := p.parseStatement()
 		if err != nil {
-			return nil, err
+			p.synchronize(err)
+			continue
 		}
 
-		stmtListTree.Children = append(stmtListTree.Children,
-			dt.ParseTree{
-				RootType:   dt.TOKEN_NODE,
-				TokenValue: semicolon,
-			},
-			*nextStmt,
-		)
+		stmtListTree.Children = append(stmtListTree.Children, *nextStmt)
 	}
-	return &stmtListTree, nil
+
+	return &stmtListTree
 }
 
 func (p *Parser) parseAssignmentStatement() (*dt.ParseTree, error) {
@@ -1114,9 +1658,9 @@ func (p *Parser) parseAssignmentStatement() (*dt.ParseTree, error) {
 }
 
 func (p *Parser) parseIfStatement() (*dt.ParseTree, error) {
-	jikaToken := p.consumeExact(dt.KEYWORD, "jika")
+	jikaToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_IF))
 	if jikaToken == nil {
-		return nil, p.createParseError(dt.KEYWORD, "expected 'jika'")
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s'", p.kw(dt.KW_IF)))
 	}
 
 	expr, err := p.parseExpression()
@@ -1124,9 +1668,9 @@ func (p *Parser) parseIfStatement() (*dt.ParseTree, error) {
 		return nil, err
 	}
 
-	makaToken := p.consumeExact(dt.KEYWORD, "maka")
+	makaToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_THEN))
 	if makaToken == nil {
-		return nil, p.createParseError(dt.KEYWORD, "expected 'maka' after if-expression")
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' after if-expression", p.kw(dt.KW_THEN)))
 	}
 
 	thenStmt, err := p.parseStatement()
@@ -1144,8 +1688,8 @@ func (p *Parser) parseIfStatement() (*dt.ParseTree, error) {
 		},
 	}
 
-	if p.matchExact(dt.KEYWORD, "selain_itu") {
-		selainItuToken := p.consumeExact(dt.KEYWORD, "selain_itu")
+	if p.matchExact(dt.KEYWORD, p.kw(dt.KW_ELSE)) {
+		selainItuToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_ELSE))
 
 		elseStmt, err := p.parseStatement()
 		if err != nil {
@@ -1162,9 +1706,9 @@ func (p *Parser) parseIfStatement() (*dt.ParseTree, error) {
 }
 
 func (p *Parser) parseWhileStatement() (*dt.ParseTree, error) {
-	selamaToken := p.consumeExact(dt.KEYWORD, "selama")
+	selamaToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_WHILE))
 	if selamaToken == nil {
-		return nil, p.createParseError(dt.KEYWORD, "expected 'selama'")
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s'", p.kw(dt.KW_WHILE)))
 	}
 
 	expr, err := p.parseExpression()
@@ -1172,9 +1716,9 @@ func (p *Parser) parseWhileStatement() (*dt.ParseTree, error) {
 		return nil, err
 	}
 
-	lakukanToken := p.consumeExact(dt.KEYWORD, "lakukan")
+	lakukanToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_DO))
 	if lakukanToken == nil {
-		return nil, p.createParseError(dt.KEYWORD, "expected 'lakukan' after while-expression")
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' after while-expression", p.kw(dt.KW_DO)))
 	}
 
 	stmt, err := p.parseStatement()
@@ -1194,15 +1738,48 @@ func (p *Parser) parseWhileStatement() (*dt.ParseTree, error) {
 	return &whileTree, nil
 }
 
+// parseRepeatStatement parses a post-test loop. Its body is a statement list
+// of its own, so it needs no begin/end.
+func (p *Parser) parseRepeatStatement() (*dt.ParseTree, error) {
+	ulangiToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_REPEAT))
+	if ulangiToken == nil {
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s'", p.kw(dt.KW_REPEAT)))
+	}
+
+	stmtList := p.parseStatementList()
+
+	sampaiToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_UNTIL))
+	if sampaiToken == nil {
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' to end repeat statement", p.kw(dt.KW_UNTIL)))
+	}
+
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	repeatTree := dt.ParseTree{
+		RootType: dt.REPEAT_STATEMENT_NODE,
+		Children: []dt.ParseTree{
+			{RootType: dt.TOKEN_NODE, TokenValue: ulangiToken},
+			*stmtList,
+			{RootType: dt.TOKEN_NODE, TokenValue: sampaiToken},
+			*expr,
+		},
+	}
+
+	return &repeatTree, nil
+}
+
 func (p *Parser) parseForStatement() (*dt.ParseTree, error) {
-	untukToken := p.consumeExact(dt.KEYWORD, "untuk")
+	untukToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_FOR))
 	if untukToken == nil {
-		return nil, p.createParseError(dt.KEYWORD, "expected 'untuk'")
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s'", p.kw(dt.KW_FOR)))
 	}
 
 	identifier := p.consume(dt.IDENTIFIER)
 	if identifier == nil {
-		return nil, p.createParseError(dt.IDENTIFIER, "expected counter identifier after 'untuk'")
+		return nil, p.createParseError(dt.IDENTIFIER, fmt.Sprintf("expected counter identifier after '%s'", p.kw(dt.KW_FOR)))
 	}
 
 	assignOp := p.consume(dt.ASSIGN_OPERATOR)
@@ -1217,14 +1794,14 @@ func (p *Parser) parseForStatement() (*dt.ParseTree, error) {
 
 	// (KEYWORD(ke)|KEYWORD(turun-ke))
 	var directionToken *dt.Token
-	if p.matchExact(dt.KEYWORD, "ke") {
-		directionToken = p.consumeExact(dt.KEYWORD, "ke")
-	} else if p.matchExact(dt.KEYWORD, "turun_ke") {
-		directionToken = p.consumeExact(dt.KEYWORD, "turun_ke")
+	if p.matchExact(dt.KEYWORD, p.kw(dt.KW_TO)) {
+		directionToken = p.consumeExact(dt.KEYWORD, p.kw(dt.KW_TO))
+	} else if p.matchExact(dt.KEYWORD, p.kw(dt.KW_DOWNTO)) {
+		directionToken = p.consumeExact(dt.KEYWORD, p.kw(dt.KW_DOWNTO))
 	}
 
 	if directionToken == nil {
-		return nil, p.createParseError(dt.KEYWORD, "expected 'ke' or 'turun_ke' in for loop")
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' or '%s' in for loop", p.kw(dt.KW_TO), p.kw(dt.KW_DOWNTO)))
 	}
 
 	endExpr, err := p.parseExpression()
@@ -1232,9 +1809,9 @@ func (p *Parser) parseForStatement() (*dt.ParseTree, error) {
 		return nil, err
 	}
 
-	lakukanToken := p.consumeExact(dt.KEYWORD, "lakukan")
+	lakukanToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_DO))
 	if lakukanToken == nil {
-		return nil, p.createParseError(dt.KEYWORD, "expected 'lakukan' in for loop")
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' in for loop", p.kw(dt.KW_DO)))
 	}
 
 	stmt, err := p.parseStatement()
@@ -1258,6 +1835,140 @@ func (p *Parser) parseForStatement() (*dt.ParseTree, error) {
 	return &forTree, nil
 }
 
+// parseCaseStatement parses
+//
+//	case expr of element {; element} [;] [else statement [;]] end
+//
+// The else branch uses the same keyword as in an if statement.
+func (p *Parser) parseCaseStatement() (*dt.ParseTree, error) {
+	kasusToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_CASE))
+	if kasusToken == nil {
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s'", p.kw(dt.KW_CASE)))
+	}
+
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	dariToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_OF))
+	if dariToken == nil {
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' after case selector", p.kw(dt.KW_OF)))
+	}
+
+	caseTree := dt.ParseTree{
+		RootType: dt.CASE_STATEMENT_NODE,
+		Children: []dt.ParseTree{
+			{RootType: dt.TOKEN_NODE, TokenValue: kasusToken},
+			*expr,
+			{RootType: dt.TOKEN_NODE, TokenValue: dariToken},
+		},
+	}
+
+	for {
+		element, err := p.parseCaseElement()
+		if err != nil {
+			return nil, err
+		}
+		caseTree.Children = append(caseTree.Children, *element)
+
+		semicolon := p.consume(dt.SEMICOLON)
+		if semicolon == nil {
+			break
+		}
+		caseTree.Children = append(caseTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: semicolon})
+
+		if p.matchExact(dt.KEYWORD, p.kw(dt.KW_ELSE)) || p.matchExact(dt.KEYWORD, p.kw(dt.KW_END)) {
+			break
+		}
+	}
+
+	if p.matchExact(dt.KEYWORD, p.kw(dt.KW_ELSE)) {
+		selainItuToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_ELSE))
+
+		elseStmt, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+
+		caseTree.Children = append(caseTree.Children,
+			dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: selainItuToken},
+			*elseStmt,
+		)
+
+		if semicolon := p.consume(dt.SEMICOLON); semicolon != nil {
+			caseTree.Children = append(caseTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: semicolon})
+		}
+	}
+
+	selesaiToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_END))
+	if selesaiToken == nil {
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' to end case statement", p.kw(dt.KW_END)))
+	}
+
+	caseTree.Children = append(caseTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: selesaiToken})
+	return &caseTree, nil
+}
+
+// parseCaseElement parses a comma separated list of labels, each a constant
+// or a range of constants, followed by ':' and a statement.
+func (p *Parser) parseCaseElement() (*dt.ParseTree, error) {
+	elementTree := dt.ParseTree{
+		RootType: dt.CASE_ELEMENT_NODE,
+		Children: make([]dt.ParseTree, 0),
+	}
+
+	for {
+		label, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.match(dt.RANGE_OPERATOR) {
+			rangeOperator := p.consume(dt.RANGE_OPERATOR)
+
+			high, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+
+			label = &dt.ParseTree{
+				RootType: dt.RANGE_NODE,
+				Children: []dt.ParseTree{
+					*label,
+					{RootType: dt.TOKEN_NODE, TokenValue: rangeOperator},
+					*high,
+				},
+			}
+		}
+
+		elementTree.Children = append(elementTree.Children, *label)
+
+		comma := p.consume(dt.COMMA)
+		if comma == nil {
+			break
+		}
+		elementTree.Children = append(elementTree.Children, dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: comma})
+	}
+
+	colon := p.consume(dt.COLON)
+	if colon == nil {
+		return nil, p.createParseError(dt.COLON, "expected ':' after case label")
+	}
+
+	stmt, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	elementTree.Children = append(elementTree.Children,
+		dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: colon},
+		*stmt,
+	)
+
+	return &elementTree, nil
+}
+
 func (p *Parser) parseSubprogramCall() (*dt.ParseTree, error) {
 	identifier := p.consume(dt.IDENTIFIER)
 	if identifier == nil {
@@ -1276,6 +1987,21 @@ func (p *Parser) parseSubprogramCall() (*dt.ParseTree, error) {
 		},
 	}
 
+	// A call through a unit keeps the unit name and the dot in front of
+	// the subprogram name.
+	if p.match(dt.DOT) {
+		dot := p.consume(dt.DOT)
+		name := p.consume(dt.IDENTIFIER)
+		if name == nil {
+			return nil, p.createParseError(dt.IDENTIFIER, "expected function/procedure identifier after '.'")
+		}
+
+		subprogramCall.Children = append(subprogramCall.Children,
+			dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: dot},
+			dt.ParseTree{RootType: dt.TOKEN_NODE, TokenValue: name},
+		)
+	}
+
 	if p.match(dt.LPARENTHESIS) {
 		lp := p.consume(dt.LPARENTHESIS)
 		params, err := p.parseParameterList()
@@ -1522,8 +2248,8 @@ func (p *Parser) parseFactor() (*dt.ParseTree, error) {
 				Children:   nil,
 			},
 		)
-	} else if p.matchExact(dt.LOGICAL_OPERATOR, "tidak") {
-		expectedNot := p.consumeExact(dt.LOGICAL_OPERATOR, "tidak")
+	} else if p.matchExact(dt.LOGICAL_OPERATOR, p.kw(dt.KW_NOT)) {
+		expectedNot := p.consumeExact(dt.LOGICAL_OPERATOR, p.kw(dt.KW_NOT))
 		factor, err := p.parseFactor()
 		if err != nil {
 			return nil, err
@@ -1571,8 +2297,8 @@ func (p *Parser) parseAdditiveOperator() (*dt.ParseTree, error) {
 		expectedAdditionOperator = p.consumeExact(dt.ARITHMETIC_OPERATOR, "+")
 	} else if p.matchExact(dt.ARITHMETIC_OPERATOR, "-") {
 		expectedAdditionOperator = p.consumeExact(dt.ARITHMETIC_OPERATOR, "-")
-	} else if p.matchExact(dt.LOGICAL_OPERATOR, "atau") {
-		expectedAdditionOperator = p.consumeExact(dt.LOGICAL_OPERATOR, "atau")
+	} else if p.matchExact(dt.LOGICAL_OPERATOR, p.kw(dt.KW_OR)) {
+		expectedAdditionOperator = p.consumeExact(dt.LOGICAL_OPERATOR, p.kw(dt.KW_OR))
 	} else {
 		return nil, p.createParseErrorMany([]dt.TokenType{dt.ARITHMETIC_OPERATOR, dt.LOGICAL_OPERATOR}, "additive operator not found")
 	}
@@ -1598,12 +2324,12 @@ func (p *Parser) parseMultiplicativeOperator() (*dt.ParseTree, error) {
 		expectedMultiplicativeOperator = p.consumeExact(dt.ARITHMETIC_OPERATOR, "*")
 	} else if p.matchExact(dt.ARITHMETIC_OPERATOR, "/") {
 		expectedMultiplicativeOperator = p.consumeExact(dt.ARITHMETIC_OPERATOR, "/")
-	} else if p.matchExact(dt.ARITHMETIC_OPERATOR, "bagi") {
-		expectedMultiplicativeOperator = p.consumeExact(dt.ARITHMETIC_OPERATOR, "bagi")
-	} else if p.matchExact(dt.ARITHMETIC_OPERATOR, "mod") {
-		expectedMultiplicativeOperator = p.consumeExact(dt.ARITHMETIC_OPERATOR, "mod")
-	} else if p.matchExact(dt.LOGICAL_OPERATOR, "dan") {
-		expectedMultiplicativeOperator = p.consumeExact(dt.LOGICAL_OPERATOR, "dan")
+	} else if p.matchExact(dt.ARITHMETIC_OPERATOR, p.kw(dt.KW_DIV)) {
+		expectedMultiplicativeOperator = p.consumeExact(dt.ARITHMETIC_OPERATOR, p.kw(dt.KW_DIV))
+	} else if p.matchExact(dt.ARITHMETIC_OPERATOR, p.kw(dt.KW_MOD)) {
+		expectedMultiplicativeOperator = p.consumeExact(dt.ARITHMETIC_OPERATOR, p.kw(dt.KW_MOD))
+	} else if p.matchExact(dt.LOGICAL_OPERATOR, p.kw(dt.KW_AND)) {
+		expectedMultiplicativeOperator = p.consumeExact(dt.LOGICAL_OPERATOR, p.kw(dt.KW_AND))
 	} else {
 		return nil, p.createParseErrorMany([]dt.TokenType{dt.ARITHMETIC_OPERATOR, dt.LOGICAL_OPERATOR}, "multiplicative operator not found")
 	}
@@ -1634,8 +2360,8 @@ func (p *Parser) parseAccess() (*dt.ParseTree, error) {
 		return nil, p.createParseError(dt.IDENTIFIER, "expected identifier")
 	}
 
-	if p.pos < len(p.buffer)-1 {
-		if p.buffer[p.pos+1].Type == dt.LPARENTHESIS {
+	if p.fill(1) {
+		if p.buffer[p.pos+1].Type == dt.LPARENTHESIS || p.qualifiedCall(false) {
 			call, err := p.parseSubprogramCall()
 
 			if err != nil {
@@ -1678,7 +2404,7 @@ func (p *Parser) parseStaticAccess() (*dt.ParseTree, error) {
 	var node *dt.ParseTree
 	var err error
 
-	if p.pos < len(p.buffer)-1 {
+	if p.fill(1) {
 		switch p.buffer[p.pos+1].Type {
 		case dt.LBRACKET:
 			node, err = p.parseArrayAccess()
@@ -1707,7 +2433,7 @@ func (p *Parser) parseStaticAccess() (*dt.ParseTree, error) {
 			TokenValue: p.consume(dt.DOT),
 		})
 
-		if p.pos < len(p.buffer)-1 {
+		if p.fill(1) {
 			switch p.buffer[p.pos+1].Type {
 			case dt.LBRACKET:
 				node, err = p.parseArrayAccess()
@@ -1811,7 +2537,7 @@ func (p *Parser) parseArrayAccess() (*dt.ParseTree, error) {
 }
 
 func (p *Parser) parseRecordType() (*dt.ParseTree, error) {
-	record := p.consumeExact(dt.KEYWORD, "rekaman")
+	record := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_RECORD))
 
 	if record == nil {
 		return nil, p.createParseError(dt.KEYWORD, "expected rekaman keyword")
@@ -1837,7 +2563,9 @@ func (p *Parser) parseRecordType() (*dt.ParseTree, error) {
 		variableDeclaration, err := p.parseVarDeclaration()
 
 		if err != nil {
-			return nil, err
+			p.synchronize(err)
+			p.consume(dt.SEMICOLON)
+			continue
 		}
 
 		recordType.Children = append(recordType.Children,
@@ -1845,9 +2573,9 @@ func (p *Parser) parseRecordType() (*dt.ParseTree, error) {
 		)
 	}
 
-	selesaiToken := p.consumeExact(dt.KEYWORD, "selesai")
+	selesaiToken := p.consumeExact(dt.KEYWORD, p.kw(dt.KW_END))
 	if selesaiToken == nil {
-		return nil, p.createParseError(dt.KEYWORD, "expected 'selesai' keyword to end record")
+		return nil, p.createParseError(dt.KEYWORD, fmt.Sprintf("expected '%s' keyword to end record", p.kw(dt.KW_END)))
 	}
 
 	recordType.Children = append(recordType.Children, dt.ParseTree{
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	iox "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/common"
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/lexer"
+)
+
+const rules = "../../config/tokenizer_m3.json"
+
+func loadDFA(t *testing.T) *lexer.DFA {
+	t.Helper()
+
+	d, err := lexer.LoadJSON(rules)
+	if err != nil {
+		t.Skip(err)
+	}
+	return d
+}
+
+// stream lexes src as it is pulled, the way the compiler feeds a
+// streaming parser.
+func stream(d *lexer.DFA, src string) iter.Seq[dt.Token] {
+	lx := lexer.New(d, iox.NewRuneReaderFromReader("test.pas", strings.NewReader(src)))
+	return func(yield func(dt.Token) bool) {
+		for tok, err := range lexer.WithTrivia(lx.All()) {
+			if err == nil && !yield(tok) {
+				return
+			}
+		}
+	}
+}
+
+func TestSeqMatchesSlice(t *testing.T) {
+	d := loadDFA(t)
+
+	paths, _ := filepath.Glob("../../test/milestone-*/*.pas")
+	if len(paths) == 0 {
+		t.Skip("no test programs found")
+	}
+
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tokens, _ := lexer.New(d, iox.NewRuneReader("test.pas", src)).ScanAll()
+		want, wantErrs := New(lexer.AttachTrivia(tokens), dt.LANGUAGE_INDO).Parse()
+		got, gotErrs := NewFromSeq(stream(d, string(src)), dt.LANGUAGE_INDO).Parse()
+
+		if got.String() != want.String() {
+			t.Errorf("%s: streamed tree differs from the one parsed from a slice", path)
+		}
+		if len(gotErrs) != len(wantErrs) {
+			t.Errorf("%s: streamed parse gave %d errors, want %d", path, len(gotErrs), len(wantErrs))
+		}
+	}
+}
+
+func longProgram(statements int, tail string) string {
+	var sb strings.Builder
+	sb.WriteString("program Long;\nvariabel x: integer;\nmulai\n")
+	for i := range statements {
+		fmt.Fprintf(&sb, "  x := x + %d;\n", i)
+	}
+	sb.WriteString(tail)
+	sb.WriteString("  x := 0\nselesai.\n")
+	return sb.String()
+}
+
+func TestSeqDropsConsumedTokens(t *testing.T) {
+	d := loadDFA(t)
+
+	p := NewFromSeq(stream(d, longProgram(5000, "")), dt.LANGUAGE_INDO)
+	tree, errs := p.Parse()
+
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if cap(p.buffer) > 256 {
+		t.Errorf("buffer holds %d tokens after parsing 5000 lines, want at most 256", cap(p.buffer))
+	}
+
+	// header, declarations, block, dot
+	block := tree.Children[2]
+	if n := (len(block.Children[1].Children) + 1) / 2; n != 5001 {
+		t.Errorf("parsed %d statements, want 5001", n)
+	}
+}
+
+func TestSeqQuotesLinesBeforeLateErrors(t *testing.T) {
+	d := loadDFA(t)
+
+	_, errs := NewFromSeq(stream(d, longProgram(5000, "  x := 1\n  x := 2;\n")), dt.LANGUAGE_INDO).Parse()
+
+	if len(errs) == 0 {
+		t.Fatal("missing ';' was not reported")
+	}
+	msg := errs[0].Error()
+	if !strings.Contains(msg, "5004:   x := 1") || !strings.Contains(msg, "5005:   x := 2;") {
+		t.Errorf("error does not quote the lines around it:\n%s", msg)
+	}
+}
@@ -11,12 +11,12 @@ func (a *SemanticAnalyzer) analyzeAdditiveOperator(parsetree *dt.ParseTree) (dt.
 		return dt.DST_ADD_OPERATOR, errors.New("operator is not additive")
 	}
 
-	switch parsetree.Children[0].TokenValue.Lexeme {
+	switch parsetree.Children[0].TokenValue.Key {
 	case "+":
 		return dt.DST_ADD_OPERATOR, nil
 	case "-":
 		return dt.DST_SUB_OPERATOR, nil
-	case "atau":
+	case a.lang.Lexeme(dt.KW_OR):
 		return dt.DST_OR_OPERATOR, nil
 	default:
 		return dt.DST_ADD_OPERATOR, errors.New("unknown additive operator")
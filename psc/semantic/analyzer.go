@@ -1,6 +1,7 @@
 package semantic
 
 import (
+	"slices"
 	"strconv"
 
 	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
@@ -8,6 +9,7 @@ import (
 
 type SemanticAnalyzer struct {
 	parseTree *dt.ParseTree
+	lang      *dt.LanguageProfile
 	tab       dt.Tab
 	atab      dt.Atab
 	btab      dt.Btab
@@ -15,6 +17,26 @@ type SemanticAnalyzer struct {
 	root      int
 	depth     int
 	stackSize int
+	diags     []*SemanticError
+	// at is the span of the statement or declaration being analyzed, used
+	// for diagnostics that have no token of their own.
+	at dt.Span
+
+	// units are the parse trees of the units that can be used, by
+	// normalized name. unitIndex holds the tab entry of each one analyzed
+	// so far, or -1 while its interface is still being analyzed.
+	units     map[string]*dt.ParseTree
+	unitIndex map[string]int
+	// uses are the tab entries of the units whose interfaces are in
+	// scope, in the order they were named.
+	uses []int
+	// forward holds the subprograms declared by an interface heading whose
+	// implementation has not been seen yet, with the name in the heading.
+	forward map[int]*dt.Token
+	// unitDecls and unitInits collect the declarations and initialization
+	// blocks of every unit analyzed, which the program runs ahead of its own.
+	unitDecls []dt.DecoratedSyntaxTree
+	unitInits []dt.DecoratedSyntaxTree
 }
 
 type semanticType struct {
@@ -22,9 +44,29 @@ type semanticType struct {
 	Reference  int
 }
 
-func New(parseTree *dt.ParseTree) *SemanticAnalyzer {
+// typeError is given to an expression that already produced a diagnostic.
+// Checks involving it are skipped so one mistake is reported only once.
+const typeError dt.TabEntryType = -1
+
+var errorType = semanticType{StaticType: typeError}
+
+func (t semanticType) isError() bool {
+	return t.StaticType == typeError
+}
+
+// errorExpression reports err, if any, and returns the placeholder for an
+// expression whose type could not be determined.
+func (a *SemanticAnalyzer) errorExpression(err error, token *dt.Token) (*dt.DecoratedSyntaxTree, semanticType, error) {
+	if err != nil {
+		a.report(err, token)
+	}
+	return &dt.DecoratedSyntaxTree{}, errorType, nil
+}
+
+func New(parseTree *dt.ParseTree, lang *dt.LanguageProfile) *SemanticAnalyzer {
 	return &SemanticAnalyzer{
 		parseTree: parseTree,
+		lang:      lang,
 		tab: dt.Tab{
 			dt.TabEntry{
 				Identifier: "string",
@@ -36,36 +78,6 @@ func New(parseTree *dt.ParseTree) *SemanticAnalyzer {
 				Level:      0,
 				Data:       0,
 			},
-			dt.TabEntry{
-				Identifier: "write",
-				Link:       0,
-				Object:     dt.TAB_ENTRY_PROC,
-				Type:       dt.TAB_ENTRY_NONE,
-				Reference:  0,
-				Normal:     false,
-				Level:      0,
-				Data:       0,
-			},
-			dt.TabEntry{
-				Identifier: "writeparam1",
-				Link:       1,
-				Object:     dt.TAB_ENTRY_PARAM,
-				Type:       dt.TAB_ENTRY_ALIAS,
-				Reference:  0,
-				Normal:     false,
-				Level:      1,
-				Data:       0,
-			},
-			dt.TabEntry{
-				Identifier: "writeparam2",
-				Link:       2,
-				Object:     dt.TAB_ENTRY_PARAM,
-				Type:       dt.TAB_ENTRY_ALIAS,
-				Reference:  0,
-				Normal:     false,
-				Level:      1,
-				Data:       0,
-			},
 		},
 		atab: dt.Atab{
 			dt.AtabEntry{
@@ -78,32 +90,109 @@ func New(parseTree *dt.ParseTree) *SemanticAnalyzer {
 				TotalSize:        256,
 			},
 		},
-		btab: dt.Btab{
-			dt.BtabEntry{
-				Start:        2,
-				ParamEnd:     3,
-				ReturnEnd:    3,
-				End:          3,
-				ParamSize:    512,
-				ReturnSize:   0,
-				VariableSize: 0,
-			},
-		},
+		btab:      make(dt.Btab, 0),
 		strtab:    make(dt.StrTab, 0),
-		root:      3,
+		root:      0,
 		depth:     0,
 		stackSize: 0,
+		units:     make(map[string]*dt.ParseTree),
+		unitIndex: make(map[string]int),
+		forward:   make(map[int]*dt.Token),
 	}
 }
 
+// AddUnit makes the unit in parseTree available to uses clauses. Units are
+// only analyzed once something uses them.
+func (a *SemanticAnalyzer) AddUnit(parseTree *dt.ParseTree) {
+	if parseTree.RootType != dt.UNIT_NODE || len(parseTree.Children) == 0 || parseTree.Children[0].RootType != dt.UNIT_HEADER_NODE {
+		return
+	}
+
+	name := parseTree.Children[0].Children[1].TokenValue.Lexeme
+	a.units[dt.NormalizeIdentifier(name)] = parseTree
+}
+
 func (a *SemanticAnalyzer) GetSymbols() (dt.Tab, dt.Atab, dt.Btab, dt.StrTab) {
 	return a.tab, a.atab, a.btab, a.strtab
 }
 
-func (a *SemanticAnalyzer) Analyze() (dt.Tab, dt.Atab, dt.Btab, dt.StrTab, *dt.DecoratedSyntaxTree, error) { // ilangin switchcase
-	dst, err := a.analyzeProgram(a.parseTree)
+// Analyze checks the whole program. It does not stop at the first problem:
+// every diagnostic found is returned, sorted by position, and the tree is
+// only meaningful when there are none.
+func (a *SemanticAnalyzer) Analyze() (dt.Tab, dt.Atab, dt.Btab, dt.StrTab, *dt.DecoratedSyntaxTree, []*SemanticError) { // ilangin switchcase
+	a.diags = nil
+
+	var dst *dt.DecoratedSyntaxTree
+	var err error
+
+	if a.parseTree.RootType == dt.UNIT_NODE {
+		dst, err = a.analyzeUnitOnly(a.parseTree)
+	} else {
+		dst, err = a.analyzeProgram(a.parseTree)
+	}
+	if err != nil {
+		a.report(err, a.parseTree.FirstToken())
+	}
+
+	if dst != nil {
+		fillSpans(dst, a.parseTree.Span)
+	}
+
+	slices.SortStableFunc(a.diags, func(x, y *SemanticError) int {
+		if x.Line != y.Line {
+			return x.Line - y.Line
+		}
+		return x.Column - y.Column
+	})
+
 	tab, atab, btab, strtab := a.GetSymbols()
-	return tab, atab, btab, strtab, dst, err
+	return tab, atab, btab, strtab, dst, a.diags
+}
+
+// find looks id up in the current scope: the declarations visible from
+// root, then the interfaces of the units in uses, the last one named first.
+func (a *SemanticAnalyzer) find(id string) (int, *dt.TabEntry) {
+	if index, entry := a.tab.FindIdentifier(id, a.root); entry != nil {
+		return index, entry
+	}
+
+	for _, unit := range slices.Backward(a.uses) {
+		if index, entry := a.tab.FindIdentifier(id, a.tab[unit].Data); entry != nil {
+			return index, entry
+		}
+	}
+
+	return -1, nil
+}
+
+// spanned gives dst the span of parsetree, unless analyzing it already set
+// a narrower one.
+func spanned(dst *dt.DecoratedSyntaxTree, parsetree *dt.ParseTree) {
+	if dst != nil && dst.Span.IsZero() {
+		dst.Span = parsetree.Span
+	}
+}
+
+// fillSpans gives every node of dst that is still without a span the span
+// of its children, or failing that the span of its parent.
+func fillSpans(dst *dt.DecoratedSyntaxTree, parent dt.Span) {
+	own := dst.Span
+	if own.IsZero() {
+		own = parent
+	}
+
+	var children dt.Span
+	for i := range dst.Children {
+		fillSpans(&dst.Children[i], own)
+		children = children.Join(dst.Children[i].Span)
+	}
+
+	if dst.Span.IsZero() {
+		if children.IsZero() {
+			children = parent
+		}
+		dst.Span = children
+	}
 }
 
 func (a *SemanticAnalyzer) resolveAliasType(t semanticType) semanticType {
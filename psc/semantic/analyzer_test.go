@@ -0,0 +1,47 @@
+package semantic_test
+
+import (
+	"testing"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+func TestSpansComeFromIdentifiers(t *testing.T) {
+	res := check(t, `program S;
+variabel
+  a, bb: integer;
+  xs: larik[1..3] dari integer;
+mulai
+  a := 1;
+  xs[a + 1] := bb;
+selesai.
+`)
+
+	if len(res.SemanticErrors) > 0 {
+		t.Fatalf("unexpected diagnostics: %v", res.Diagnostics())
+	}
+
+	vars := res.DST.Children[0]
+	block := res.DST.Children[len(res.DST.Children)-1]
+
+	tests := []struct {
+		name string
+		node dt.DecoratedSyntaxTree
+		want string
+	}{
+		{"declared a", vars.Children[0], "3:3-4"},
+		{"declared bb", vars.Children[1], "3:6-8"},
+		{"declared xs", vars.Children[2], "4:3-5"},
+		{"assignment", block.Children[0], "6:3-9"},
+		{"target a", block.Children[0].Children[0], "6:3-4"},
+		{"target xs[a + 1]", block.Children[1].Children[0], "7:3-12"},
+		{"array xs", block.Children[1].Children[0].Children[0], "7:3-5"},
+		{"value bb", block.Children[1].Children[1], "7:16-18"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.node.Span.String(); got != tt.want {
+			t.Errorf("%s: span %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
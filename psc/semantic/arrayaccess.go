@@ -37,17 +37,32 @@ func (a *SemanticAnalyzer) analyzeArrayAccess(parsetree *dt.ParseTree, prev *dt.
 	}
 
 	token := parsetree.Children[0].TokenValue
-	index, tabEntry := a.tab.FindIdentifier(token.Lexeme, root)
+
+	var index int
+	var tabEntry *dt.TabEntry
+	if prev == nil {
+		index, tabEntry = a.find(token.Lexeme)
+	} else {
+		index, tabEntry = a.tab.FindIdentifier(token.Lexeme, root)
+	}
 
 	if tabEntry == nil {
-		return nil, semanticType{}, errors.New("undeclared identifier")
+		return nil, semanticType{}, a.newUndeclaredIdentError(token.Lexeme, token)
 	}
 
+	return a.analyzeArrayAccessOf(parsetree, prev, index, tabEntry)
+}
+
+// analyzeArrayAccessOf analyzes the indexing in parsetree of the array
+// found at index.
+func (a *SemanticAnalyzer) analyzeArrayAccessOf(parsetree *dt.ParseTree, prev *dt.DecoratedSyntaxTree, index int, tabEntry *dt.TabEntry) (*dt.DecoratedSyntaxTree, semanticType, error) {
 	var dstType dt.DSTNodeType
 
 	switch tabEntry.Object {
 	case dt.TAB_ENTRY_CONST:
 		dstType = dt.DST_CONST
+	case dt.TAB_ENTRY_PARAM:
+		fallthrough
 	case dt.TAB_ENTRY_VAR:
 		dstType = dt.DST_VARIABLE
 	case dt.TAB_ENTRY_FIELD:
@@ -56,33 +71,43 @@ func (a *SemanticAnalyzer) analyzeArrayAccess(parsetree *dt.ParseTree, prev *dt.
 		return nil, semanticType{}, errors.New("identifier does not reference a constant, variable, or field")
 	}
 
-	if tabEntry.Type != dt.TAB_ENTRY_ARRAY {
+	if a.resolveAliasType(semanticType{StaticType: tabEntry.Type, Reference: tabEntry.Reference}).StaticType != dt.TAB_ENTRY_ARRAY {
 		return nil, semanticType{}, errors.New("identifier does not hold an array value")
 	}
 
 	var children []dt.DecoratedSyntaxTree
+	var span dt.Span
 	if prev == nil {
 		children = make([]dt.DecoratedSyntaxTree, 0)
 	} else {
 		children = []dt.DecoratedSyntaxTree{*prev}
+		span = prev.Span
 	}
 
 	prev = &dt.DecoratedSyntaxTree{
 		Property: dt.DST_FROM,
 		SelfType: dstType,
 		Data:     index,
+		Span:     span.Join(parsetree.Children[0].Span),
 		Children: children,
 	}
 
+	// Each index is followed by the ] that ends the element it selects.
 	recursiveNodes := make([]dt.ParseTree, 0)
+	ends := make([]dt.Span, 0)
 	for i := 2; i < len(parsetree.Children); i += 3 {
 		recursiveNodes = append(recursiveNodes, parsetree.Children[i])
+		if i+1 < len(parsetree.Children) {
+			ends = append(ends, parsetree.Children[i+1].Span)
+		} else {
+			ends = append(ends, parsetree.Children[i].Span)
+		}
 	}
 
-	return a.analyzeRecursiveArrayAccess(recursiveNodes, prev)
+	return a.analyzeRecursiveArrayAccess(recursiveNodes, ends, prev)
 }
 
-func (a *SemanticAnalyzer) analyzeRecursiveArrayAccess(nodes []dt.ParseTree, prev *dt.DecoratedSyntaxTree) (*dt.DecoratedSyntaxTree, semanticType, error) {
+func (a *SemanticAnalyzer) analyzeRecursiveArrayAccess(nodes []dt.ParseTree, ends []dt.Span, prev *dt.DecoratedSyntaxTree) (*dt.DecoratedSyntaxTree, semanticType, error) {
 	if nodes[0].RootType != dt.EXPRESSION_NODE {
 		return nil, semanticType{}, errors.New("expected valid array index")
 	}
@@ -103,7 +128,16 @@ func (a *SemanticAnalyzer) analyzeRecursiveArrayAccess(nodes []dt.ParseTree, pre
 
 		atabIndex = a.tab[tabIndex].Reference
 	case dt.DST_ARRAY_ELEMENT:
-		atabIndex = prev.Data
+		elementType := a.resolveAliasType(semanticType{
+			StaticType: a.atab[prev.Data].ElementType,
+			Reference:  a.atab[prev.Data].ElementReference,
+		})
+
+		if elementType.StaticType != dt.TAB_ENTRY_ARRAY {
+			return nil, semanticType{}, errors.New("cannot access non-array type as if it was an array")
+		}
+
+		atabIndex = elementType.Reference
 	default:
 		return nil, semanticType{}, errors.New("object cannot be indexed")
 	}
@@ -132,6 +166,7 @@ func (a *SemanticAnalyzer) analyzeRecursiveArrayAccess(nodes []dt.ParseTree, pre
 		self = &dt.DecoratedSyntaxTree{
 			SelfType: dt.DST_ARRAY_ELEMENT,
 			Data:     atabIndex,
+			Span:     prev.Span.Join(ends[0]),
 			Children: []dt.DecoratedSyntaxTree{
 				*prev,
 				*index,
@@ -149,9 +184,9 @@ func (a *SemanticAnalyzer) analyzeRecursiveArrayAccess(nodes []dt.ParseTree, pre
 		return self, expectedType, nil
 	}
 
-	if expectedType.StaticType != dt.TAB_ENTRY_ARRAY && len(nodes) > 1 {
+	if a.resolveAliasType(expectedType).StaticType != dt.TAB_ENTRY_ARRAY && len(nodes) > 1 {
 		return nil, semanticType{}, errors.New("cannot access non-array type as if it was an array")
 	}
 
-	return a.analyzeRecursiveArrayAccess(nodes[1:], self)
+	return a.analyzeRecursiveArrayAccess(nodes[1:], ends[1:], self)
 }
@@ -14,7 +14,7 @@ func (a *SemanticAnalyzer) analyzeAssignmentStatement(parsetree *dt.ParseTree) (
 	target, targetType, err := a.analyzeStaticAccess(&parsetree.Children[0], nil)
 
 	if err != nil {
-		return nil, err
+		target, targetType, _ = a.errorExpression(err, parsetree.Children[0].FirstToken())
 	}
 
 	value, valueType, err := a.analyzeExpression(&parsetree.Children[2])
@@ -23,7 +23,7 @@ func (a *SemanticAnalyzer) analyzeAssignmentStatement(parsetree *dt.ParseTree) (
 		return nil, err
 	}
 
-	if !a.checkTypeEquality(targetType, valueType) {
+	if !targetType.isError() && !valueType.isError() && !a.checkTypeEquality(targetType, valueType) {
 		if a.canCastImplicitly(valueType, targetType) {
 			value, valueType = a.insertImplicitCast(value, valueType, targetType)
 		} else {
@@ -0,0 +1,150 @@
+package semantic
+
+import (
+	"fmt"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// analyzeBuiltinCall applies the typing rule of b. Arguments that need to be
+// real are wrapped in a cast so backends never have to convert them.
+func (a *SemanticAnalyzer) analyzeBuiltinCall(b dt.Builtin, parseTree *dt.ParseTree) (*dt.DecoratedSyntaxTree, semanticType, error) {
+	token := parseTree.Children[0].TokenValue
+	name := b.String()
+
+	var args []dt.DecoratedSyntaxTree
+	var types []semanticType
+
+	if len(parseTree.Children) > 2 {
+		var err error
+		args, types, err = a.analyzeParameterList(&parseTree.Children[2])
+
+		if err != nil {
+			return nil, semanticType{}, err
+		}
+	}
+
+	dst := &dt.DecoratedSyntaxTree{
+		SelfType: dt.DST_BUILTIN_CALL,
+		Data:     int(b),
+		Children: args,
+	}
+
+	switch b {
+	case dt.BUILTIN_WRITE, dt.BUILTIN_WRITELN:
+		if b == dt.BUILTIN_WRITE && len(args) == 0 {
+			a.report(a.newParameterCountError(1, 0, name, token), token)
+		}
+
+		for i, typ := range types {
+			if !typ.isError() && !a.isWritable(typ) {
+				a.report(a.newParameterTypeError(i, "integer, real, boolean, char or string", typ.StaticType.String(), name, token), token)
+			}
+		}
+
+		return dst, semanticType{StaticType: dt.TAB_ENTRY_NONE}, nil
+
+	case dt.BUILTIN_READ, dt.BUILTIN_READLN:
+		if b == dt.BUILTIN_READ && len(args) == 0 {
+			a.report(a.newParameterCountError(1, 0, name, token), token)
+		}
+
+		for i, typ := range types {
+			if typ.isError() {
+				continue
+			}
+
+			switch args[i].SelfType {
+			case dt.DST_VARIABLE, dt.DST_ARRAY_ELEMENT, dt.DST_RECORD_FIELD:
+			default:
+				a.report(NewSemanticError(fmt.Sprintf("parameter %d of '%s' must be a variable", i+1, name), token, "subprogram call"), token)
+				continue
+			}
+
+			switch a.resolveAliasType(typ).StaticType {
+			case dt.TAB_ENTRY_INTEGER, dt.TAB_ENTRY_REAL, dt.TAB_ENTRY_CHAR:
+			default:
+				a.report(a.newParameterTypeError(i, "integer, real or char", typ.StaticType.String(), name, token), token)
+			}
+		}
+
+		return dst, semanticType{StaticType: dt.TAB_ENTRY_NONE}, nil
+	}
+
+	// Everything else is a function of exactly one argument.
+	if len(args) != 1 {
+		a.report(a.newParameterCountError(1, len(args), name, token), token)
+		return dst, errorType, nil
+	}
+
+	if types[0].isError() {
+		return dst, errorType, nil
+	}
+
+	typ := a.resolveAliasType(types[0])
+	realType := semanticType{StaticType: dt.TAB_ENTRY_REAL}
+
+	isNumeric := typ.StaticType == dt.TAB_ENTRY_INTEGER || typ.StaticType == dt.TAB_ENTRY_REAL
+	isOrdinal := typ.StaticType == dt.TAB_ENTRY_INTEGER || typ.StaticType == dt.TAB_ENTRY_CHAR || typ.StaticType == dt.TAB_ENTRY_BOOLEAN
+
+	var result semanticType
+	var expected string
+	ok := false
+
+	switch b {
+	case dt.BUILTIN_ABS, dt.BUILTIN_SQR:
+		ok, expected, result = isNumeric, "integer or real", typ
+
+	case dt.BUILTIN_SQRT, dt.BUILTIN_SIN, dt.BUILTIN_COS, dt.BUILTIN_EXP, dt.BUILTIN_LN:
+		ok, expected, result = isNumeric, "integer or real", realType
+		if ok {
+			cast, _ := a.insertImplicitCast(&args[0], typ, realType)
+			dst.Children[0] = *cast
+		}
+
+	case dt.BUILTIN_TRUNC, dt.BUILTIN_ROUND:
+		ok, expected, result = isNumeric, "real", semanticType{StaticType: dt.TAB_ENTRY_INTEGER}
+		if ok {
+			cast, _ := a.insertImplicitCast(&args[0], typ, realType)
+			dst.Children[0] = *cast
+		}
+
+	case dt.BUILTIN_ODD:
+		ok, expected, result = typ.StaticType == dt.TAB_ENTRY_INTEGER, "integer", semanticType{StaticType: dt.TAB_ENTRY_BOOLEAN}
+
+	case dt.BUILTIN_ORD:
+		ok, expected, result = isOrdinal, "integer, char or boolean", semanticType{StaticType: dt.TAB_ENTRY_INTEGER}
+
+	case dt.BUILTIN_CHR:
+		ok, expected, result = typ.StaticType == dt.TAB_ENTRY_INTEGER, "integer", semanticType{StaticType: dt.TAB_ENTRY_CHAR}
+
+	case dt.BUILTIN_SUCC, dt.BUILTIN_PRED:
+		ok, expected, result = isOrdinal, "integer, char or boolean", typ
+	}
+
+	if !ok {
+		a.report(a.newParameterTypeError(0, expected, types[0].StaticType.String(), name, token), token)
+		return dst, errorType, nil
+	}
+
+	return dst, result, nil
+}
+
+// isWritable reports whether write can print a value of type t: a scalar
+// other than a record, or an array of char such as a string.
+func (a *SemanticAnalyzer) isWritable(t semanticType) bool {
+	t = a.resolveAliasType(t)
+
+	switch t.StaticType {
+	case dt.TAB_ENTRY_INTEGER, dt.TAB_ENTRY_REAL, dt.TAB_ENTRY_BOOLEAN, dt.TAB_ENTRY_CHAR:
+		return true
+	case dt.TAB_ENTRY_ARRAY:
+		element := a.resolveAliasType(semanticType{
+			StaticType: a.atab[t.Reference].ElementType,
+			Reference:  a.atab[t.Reference].ElementReference,
+		})
+		return element.StaticType == dt.TAB_ENTRY_CHAR
+	}
+
+	return false
+}
@@ -0,0 +1,210 @@
+package semantic
+
+import (
+	"errors"
+	"fmt"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// caseLabel is a label already seen in a case statement, kept to detect
+// duplicates. A single label has low == high.
+type caseLabel struct {
+	low  int
+	high int
+}
+
+// analyzeCaseStatement checks the selector and every label. Labels are folded
+// to literals of the selector type, so backends only compare ordinals.
+func (a *SemanticAnalyzer) analyzeCaseStatement(parsetree *dt.ParseTree) (*dt.DecoratedSyntaxTree, error) {
+	if parsetree.RootType != dt.CASE_STATEMENT_NODE {
+		return nil, errors.New("expected case block")
+	}
+
+	selector, typ, err := a.analyzeExpression(&parsetree.Children[1])
+
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := a.resolveAliasType(typ)
+	checkLabels := !typ.isError()
+
+	switch resolved.StaticType {
+	case dt.TAB_ENTRY_INTEGER, dt.TAB_ENTRY_CHAR, dt.TAB_ENTRY_BOOLEAN:
+	default:
+		if checkLabels {
+			token := parsetree.Children[0].TokenValue
+			a.report(NewSemanticError(
+				fmt.Sprintf("case selector must be of an ordinal type, got %s", typ.StaticType),
+				token,
+				"case statement",
+			), token)
+			checkLabels = false
+		}
+	}
+
+	selector.Property = dt.DST_SELECTOR
+
+	children := []dt.DecoratedSyntaxTree{*selector}
+	var seen []caseLabel
+
+	for i := 3; i < len(parsetree.Children); i++ {
+		child := &parsetree.Children[i]
+
+		switch {
+		case child.RootType == dt.CASE_ELEMENT_NODE:
+			arm, err := a.analyzeCaseElement(child, resolved, checkLabels, &seen)
+
+			if err != nil {
+				return nil, err
+			}
+
+			children = append(children, *arm)
+
+		case child.TokenValue != nil && a.lang.Is(dt.KW_ELSE, child.TokenValue.Lexeme) && i+1 < len(parsetree.Children):
+			elseBlock, err := a.analyzeStatement(&parsetree.Children[i+1])
+
+			if err != nil {
+				return nil, err
+			}
+
+			elseBlock.Property = dt.DST_ELSE
+			children = append(children, *elseBlock)
+			i++
+		}
+	}
+
+	return &dt.DecoratedSyntaxTree{
+		SelfType: dt.DST_CASE_BLOCK,
+		Children: children,
+	}, nil
+}
+
+func (a *SemanticAnalyzer) analyzeCaseElement(parsetree *dt.ParseTree, selector semanticType, checkLabels bool, seen *[]caseLabel) (*dt.DecoratedSyntaxTree, error) {
+	var children []dt.DecoratedSyntaxTree
+
+	n := len(parsetree.Children)
+
+	for i := 0; i < n-2; i += 2 {
+		label := &parsetree.Children[i]
+		token := label.FirstToken()
+
+		var bounds []*dt.ParseTree
+		if label.RootType == dt.RANGE_NODE {
+			bounds = []*dt.ParseTree{&label.Children[0], &label.Children[2]}
+		} else {
+			bounds = []*dt.ParseTree{label}
+		}
+
+		values := make([]int, 0, 2)
+		literals := make([]dt.DecoratedSyntaxTree, 0, 2)
+
+		for _, bound := range bounds {
+			dst, typ, err := a.analyzeExpression(bound)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if !checkLabels || typ.isError() {
+				continue
+			}
+
+			if !a.checkTypeEquality(a.resolveAliasType(typ), selector) {
+				a.report(a.newTypeMismatchError(selector.StaticType.String(), typ.StaticType.String(), token), token)
+				continue
+			}
+
+			value, err := a.staticEvaluate(dst, selector)
+			if err != nil {
+				a.report(a.newConstantExpectedError(token), token)
+				continue
+			}
+
+			literal := caseLiteral(selector.StaticType, value)
+			literal.Span = bound.Span
+
+			values = append(values, value)
+			literals = append(literals, literal)
+		}
+
+		if len(values) != len(bounds) {
+			continue
+		}
+
+		current := caseLabel{low: values[0], high: values[len(values)-1]}
+
+		if current.low > current.high {
+			a.report(NewSemanticError("case label range is empty", token, "case statement"), token)
+			continue
+		}
+
+		for _, prev := range *seen {
+			if current.low <= prev.high && prev.low <= current.high {
+				a.report(NewSemanticError(
+					fmt.Sprintf("duplicate case label %s", formatCaseLabel(selector.StaticType, max(current.low, prev.low))),
+					token,
+					"case statement",
+				), token)
+				break
+			}
+		}
+
+		*seen = append(*seen, current)
+
+		if len(literals) == 1 {
+			literals[0].Property = dt.DST_LABEL
+			children = append(children, literals[0])
+			continue
+		}
+
+		literals[0].Property = dt.DST_FROM
+		literals[1].Property = dt.DST_UPTO
+		children = append(children, dt.DecoratedSyntaxTree{
+			Property: dt.DST_LABEL,
+			SelfType: dt.DST_CASE_RANGE,
+			Span:     label.Span,
+			Children: literals,
+		})
+	}
+
+	block, err := a.analyzeStatement(&parsetree.Children[n-1])
+
+	if err != nil {
+		return nil, err
+	}
+
+	block.Property = dt.DST_EXECUTE
+	children = append(children, *block)
+
+	return &dt.DecoratedSyntaxTree{
+		SelfType: dt.DST_CASE_ARM,
+		Children: children,
+	}, nil
+}
+
+func caseLiteral(typ dt.TabEntryType, value int) dt.DecoratedSyntaxTree {
+	switch typ {
+	case dt.TAB_ENTRY_CHAR:
+		return dt.DecoratedSyntaxTree{SelfType: dt.DST_CHAR_LITERAL, Data: value}
+	case dt.TAB_ENTRY_BOOLEAN:
+		return dt.DecoratedSyntaxTree{SelfType: dt.DST_BOOL_LITERAL, Data: value}
+	default:
+		return dt.DecoratedSyntaxTree{SelfType: dt.DST_INT_LITERAL, Data: value}
+	}
+}
+
+func formatCaseLabel(typ dt.TabEntryType, value int) string {
+	switch typ {
+	case dt.TAB_ENTRY_CHAR:
+		return fmt.Sprintf("'%c'", rune(value))
+	case dt.TAB_ENTRY_BOOLEAN:
+		if value != 0 {
+			return "true"
+		}
+		return "false"
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -11,16 +11,18 @@ func (a *SemanticAnalyzer) analyzeConstDeclarationPart(parsetree *dt.ParseTree)
 		return nil, errors.New("expected const declaration part")
 	}
 
-	declarations := make([]dt.DecoratedSyntaxTree, len(parsetree.Children)-1)
+	declarations := make([]dt.DecoratedSyntaxTree, 0, len(parsetree.Children)-1)
 
-	for i, constDeclaration := range parsetree.Children[1:] {
+	for _, constDeclaration := range parsetree.Children[1:] {
 		declaration, err := a.analyzeConstDeclaration(&constDeclaration)
 
 		if err != nil {
-			return nil, err
+			a.report(err, constDeclaration.FirstToken())
+			continue
 		}
 
-		declarations[i] = *declaration
+		spanned(declaration, &constDeclaration)
+		declarations = append(declarations, *declaration)
 	}
 
 	return &dt.DecoratedSyntaxTree{
@@ -7,7 +7,7 @@ import (
 )
 
 func (a *SemanticAnalyzer) analyzeDeclarationPart(parsetree *dt.ParseTree) ([]dt.DecoratedSyntaxTree, error) {
-	if parsetree.RootType != dt.DECLARATION_PART_NODE {
+	if parsetree.RootType != dt.DECLARATION_PART_NODE && parsetree.RootType != dt.INTERFACE_PART_NODE {
 		return nil, errors.New("expected declaration part")
 	}
 
@@ -26,14 +26,28 @@ func (a *SemanticAnalyzer) analyzeDeclarationPart(parsetree *dt.ParseTree) ([]dt
 			declaration, err = a.analyzeVarDeclarationPart(&child)
 		case dt.SUBPROGRAM_DECLARATION_NODE:
 			declaration, err = a.analyzeSubprogramDeclaration(&child)
+		case dt.PROCEDURE_HEADING_NODE:
+			// A heading only declares the subprogram. Its implementation
+			// is the one that ends up in the tree.
+			_, err = a.analyzeProcedureDeclaration(&child)
+		case dt.FUNCTION_HEADING_NODE:
+			_, err = a.analyzeFunctionDeclaration(&child)
+		case dt.TOKEN_NODE, dt.USES_CLAUSE_NODE:
+			continue
 		default:
 			return nil, errors.New("unknown declaration section")
 		}
 
 		if err != nil {
-			return nil, err
+			a.report(err, child.FirstToken())
+			continue
 		}
 
+		if declaration == nil {
+			continue
+		}
+
+		spanned(declaration, &child)
 		declarations = append(declarations, *declaration)
 	}
 
@@ -12,23 +12,34 @@ type SemanticError struct {
 	Column  int
 	Context string
 	Token   *dt.Token
+	Span    dt.Span
+	// File names the included file the error is in, if it is not in the
+	// file being compiled.
+	File string
 }
 
 func (e *SemanticError) Error() string {
+	where := ""
+	if e.File != "" {
+		where = " in " + e.File
+	}
+
 	if e.Token != nil {
-		return fmt.Sprintf("Semantic error at line %d, column %d: %s\nContext: %s\nNear: '%s'",
-			e.Line, e.Column, e.Message, e.Context, e.Token.Lexeme)
+		return fmt.Sprintf("Semantic error%s at line %d, column %d: %s\nContext: %s\nNear: '%s'",
+			where, e.Line, e.Column, e.Message, e.Context, e.Token.Lexeme)
 	}
-	return fmt.Sprintf("Semantic error at line %d, column %d: %s\nContext: %s",
-		e.Line, e.Column, e.Message, e.Context)
+	return fmt.Sprintf("Semantic error%s at line %d, column %d: %s\nContext: %s",
+		where, e.Line, e.Column, e.Message, e.Context)
 }
 
 func NewSemanticError(message string, token *dt.Token, context string) *SemanticError {
 	line := 0
 	column := 0
+	var span dt.Span
 	if token != nil {
 		line = token.Line
 		column = token.Col
+		span = token.Span()
 	}
 	return &SemanticError{
 		Message: message,
@@ -36,7 +47,37 @@ func NewSemanticError(message string, token *dt.Token, context string) *Semantic
 		Column:  column,
 		Context: context,
 		Token:   token,
+		Span:    span,
+	}
+}
+
+// report records err as a diagnostic. An error that carries no position of its
+// own is placed at token, the closest token the caller knows about, or else at
+// the statement or declaration being analyzed.
+func (a *SemanticAnalyzer) report(err error, token *dt.Token) {
+	semErr, ok := err.(*SemanticError)
+	if !ok {
+		semErr = NewSemanticError(err.Error(), token, "semantic analysis")
+	} else if semErr.Token == nil && token != nil {
+		semErr.Token = token
+		semErr.Line = token.Line
+		semErr.Column = token.Col
+		semErr.Span = token.Span()
+	}
+
+	if semErr.Span.IsZero() && !a.at.IsZero() {
+		semErr.Span = a.at
+		semErr.Line = a.at.Start.Line
+		semErr.Column = a.at.Start.Col
+	}
+
+	for _, prev := range a.diags {
+		if prev.Line == semErr.Line && prev.Column == semErr.Column && prev.Message == semErr.Message {
+			return
+		}
 	}
+
+	a.diags = append(a.diags, semErr)
 }
 
 const (
@@ -181,3 +222,43 @@ func (a *SemanticAnalyzer) newAssignmentError(targetType string, valueType strin
 		"assignment statement",
 	)
 }
+
+func (a *SemanticAnalyzer) newUnitNotFoundError(unit string, token *dt.Token) error {
+	return NewSemanticError(
+		fmt.Sprintf("unit '%s' not found", unit),
+		token,
+		"uses clause",
+	)
+}
+
+func (a *SemanticAnalyzer) newUnitCycleError(unit string, token *dt.Token) error {
+	return NewSemanticError(
+		fmt.Sprintf("unit '%s' is used by its own interface, directly or through other units", unit),
+		token,
+		"uses clause",
+	)
+}
+
+func (a *SemanticAnalyzer) newNotExportedError(unit string, identifier string, token *dt.Token) error {
+	return NewSemanticError(
+		fmt.Sprintf("unit '%s' does not export '%s'", unit, identifier),
+		token,
+		"qualified identifier",
+	)
+}
+
+func (a *SemanticAnalyzer) newMissingImplementationError(kind string, identifier string, token *dt.Token) error {
+	return NewSemanticError(
+		fmt.Sprintf("%s '%s' is declared in the interface but never implemented", kind, identifier),
+		token,
+		"unit implementation",
+	)
+}
+
+func (a *SemanticAnalyzer) newHeadingMismatchError(identifier string, token *dt.Token) error {
+	return NewSemanticError(
+		fmt.Sprintf("heading of '%s' does not match the one in the interface", identifier),
+		token,
+		"unit implementation",
+	)
+}
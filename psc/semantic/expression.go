@@ -1,8 +1,6 @@
 package semantic
 
 import (
-	"errors"
-
 	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
 )
 
@@ -28,13 +26,24 @@ func (a *SemanticAnalyzer) analyzeExpression(parseTree *dt.ParseTree) (*dt.Decor
 			return nil, rtype, err
 		}
 
+		if ltype.isError() || rtype.isError() {
+			return a.errorExpression(nil, nil)
+		}
+
 		promotedLhs, promotedRhs, _, compatible := a.promoteTypes(lhs, ltype, rhs, rtype)
 		if !compatible {
-			return nil, ltype, errors.New("operand types are incompatible")
+			token := parseTree.Children[1].FirstToken()
+			return a.errorExpression(a.newOperatorTypeError(
+				token.Lexeme,
+				ltype.StaticType.String(),
+				rtype.StaticType.String(),
+				token,
+			), token)
 		}
 
 		return &dt.DecoratedSyntaxTree{
 			SelfType: optype,
+			Span:     parseTree.Span,
 			Children: []dt.DecoratedSyntaxTree{
 				*promotedLhs,
 				*promotedRhs,
@@ -7,16 +7,31 @@ import (
 )
 
 func (a *SemanticAnalyzer) analyzeFactor(parseTree *dt.ParseTree) (*dt.DecoratedSyntaxTree, semanticType, error) {
+	dst, typ, err := a.analyzeFactorKind(parseTree)
+
+	if err != nil {
+		dst, typ, err = a.errorExpression(err, parseTree.FirstToken())
+	}
+
+	spanned(dst, parseTree)
+	return dst, typ, err
+}
+
+func (a *SemanticAnalyzer) analyzeFactorKind(parseTree *dt.ParseTree) (*dt.DecoratedSyntaxTree, semanticType, error) {
 	if parseTree.Children[0].RootType == dt.TOKEN_NODE {
 		if parseTree.Children[0].TokenValue.Type == dt.LPARENTHESIS {
 			return a.analyzeExpression(&parseTree.Children[1])
-		} else if parseTree.Children[0].TokenValue.Lexeme == "tidak" {
+		} else if a.lang.Is(dt.KW_NOT, parseTree.Children[0].TokenValue.Lexeme) {
 			dst, typ, err := a.analyzeFactor(&parseTree.Children[1])
 
 			if err != nil {
 				return nil, typ, err
 			}
 
+			if typ.isError() {
+				return dst, typ, nil
+			}
+
 			if typ.StaticType != dt.TAB_ENTRY_BOOLEAN {
 				return nil, typ, errors.New("not operator only works on boolean expressions")
 			}
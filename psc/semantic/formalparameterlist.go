@@ -19,7 +19,7 @@ func (a *SemanticAnalyzer) analyzeFormalParameterList(parsetree *dt.ParseTree) (
 	i := 0
 	for i < len(parsetree.Children) {
 		child := &parsetree.Children[i]
-		if child.RootType == dt.TOKEN_NODE && child.TokenValue.Type == dt.KEYWORD && child.TokenValue.Lexeme == "variabel" {
+		if child.RootType == dt.TOKEN_NODE && child.TokenValue.Type == dt.KEYWORD && a.lang.Is(dt.KW_VAR, child.TokenValue.Lexeme) {
 			isRef = true
 			i++
 			continue
@@ -51,10 +51,12 @@ func (a *SemanticAnalyzer) analyzeFormalParameterList(parsetree *dt.ParseTree) (
 				return nil, err
 			}
 
-			for _, identifier := range identifierList {
+			for j, identifier := range identifierList {
 				_, check := a.tab.FindIdentifier(identifier, a.root)
 				if check != nil && check.Level == a.depth {
-					return nil, fmt.Errorf("cannot redeclare identifier '%s' in the same scope", identifier)
+					token := identifierListNode.Children[2*j].TokenValue
+					a.report(a.newRedeclarationError(identifier, token), token)
+					continue
 				}
 
 				var entry dt.TabEntry
@@ -90,14 +92,17 @@ func (a *SemanticAnalyzer) analyzeFormalParameterList(parsetree *dt.ParseTree) (
 					Property: dt.DST_PARAMETER,
 					SelfType: dt.DST_VARIABLE,
 					Data:     a.root,
+					Span:     identifierListNode.Children[2*j].Span,
 				})
 			}
 
 			isRef = false
 			i += 3
-			// If the node is not a recognized token or an identifier list, it's a syntax error.
-			return nil, fmt.Errorf("unexpected node type '%s' in formal parameter list at index %d", child.RootType, i)
+			continue
 		}
+
+		// If the node is not a recognized token or an identifier list, it's a syntax error.
+		return nil, fmt.Errorf("unexpected node type '%s' in formal parameter list at index %d", child.RootType, i)
 	}
 
 	return &dt.DecoratedSyntaxTree{
@@ -2,6 +2,7 @@ package semantic
 
 import (
 	"errors"
+	"fmt"
 
 	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
 )
@@ -14,11 +15,9 @@ func (a *SemanticAnalyzer) analyzeForStatement(parsetree *dt.ParseTree) (*dt.Dec
 	target, targetType, err := a.analyzeToken(&parsetree.Children[1])
 
 	if err != nil {
-		return nil, err
-	}
-
-	if target.SelfType != dt.DST_VARIABLE {
-		return nil, errors.New("expected variable in for loop assignment")
+		target, targetType, _ = a.errorExpression(err, parsetree.Children[1].TokenValue)
+	} else if target.SelfType != dt.DST_VARIABLE {
+		target, targetType, _ = a.errorExpression(errors.New("expected variable in for loop assignment"), parsetree.Children[1].TokenValue)
 	}
 
 	initial, initialType, err := a.analyzeExpression(&parsetree.Children[3])
@@ -27,8 +26,8 @@ func (a *SemanticAnalyzer) analyzeForStatement(parsetree *dt.ParseTree) (*dt.Dec
 		return nil, err
 	}
 
-	if !a.checkTypeEquality(targetType, initialType) {
-		return nil, errors.New("assigned expression type does not match variable type")
+	if !targetType.isError() && !initialType.isError() && !a.checkTypeEquality(targetType, initialType) {
+		a.report(errors.New("assigned expression type does not match variable type"), parsetree.Children[3].FirstToken())
 	}
 
 	final, finalType, err := a.analyzeExpression(&parsetree.Children[5])
@@ -37,8 +36,8 @@ func (a *SemanticAnalyzer) analyzeForStatement(parsetree *dt.ParseTree) (*dt.Dec
 		return nil, err
 	}
 
-	if !a.checkTypeEquality(targetType, finalType) {
-		return nil, errors.New("final expression type does not match variable type")
+	if !targetType.isError() && !finalType.isError() && !a.checkTypeEquality(targetType, finalType) {
+		a.report(errors.New("final expression type does not match variable type"), parsetree.Children[5].FirstToken())
 	}
 
 	block, err := a.analyzeStatement(&parsetree.Children[7])
@@ -51,13 +50,13 @@ func (a *SemanticAnalyzer) analyzeForStatement(parsetree *dt.ParseTree) (*dt.Dec
 	initial.Property = dt.DST_VALUE
 	block.Property = dt.DST_EXECUTE
 
-	switch parsetree.Children[4].TokenValue.Lexeme {
-	case "ke":
+	switch parsetree.Children[4].TokenValue.Key {
+	case a.lang.Lexeme(dt.KW_TO):
 		final.Property = dt.DST_UPTO
-	case "turun_ke":
+	case a.lang.Lexeme(dt.KW_DOWNTO):
 		final.Property = dt.DST_DOWNTO
 	default:
-		return nil, errors.New("expected 'ke' or 'turun_ke'")
+		return nil, fmt.Errorf("expected '%s' or '%s'", a.lang.Lexeme(dt.KW_TO), a.lang.Lexeme(dt.KW_DOWNTO))
 	}
 
 	return &dt.DecoratedSyntaxTree{
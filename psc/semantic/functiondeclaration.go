@@ -7,28 +7,39 @@ import (
 )
 
 func (a *SemanticAnalyzer) analyzeFunctionDeclaration(parsetree *dt.ParseTree) (*dt.DecoratedSyntaxTree, error) {
-	if parsetree.RootType != dt.FUNCTION_DECLARATION_NODE {
+	if parsetree.RootType != dt.FUNCTION_DECLARATION_NODE && parsetree.RootType != dt.FUNCTION_HEADING_NODE {
 		return nil, errors.New("expected procedure declaration")
 	}
 
 	identifier := parsetree.Children[1].TokenValue.Lexeme
 
-	_, check := a.tab.FindIdentifier(identifier, a.root)
-	if check != nil {
-		if check.Level == a.depth {
-			return nil, errors.New("cannot redeclare identifier in the same scope")
+	tabIndex := a.implementedHeading(parsetree, dt.TAB_ENTRY_FUNC)
+	implemented := tabIndex != -1
+	heading := parsetree.RootType == dt.FUNCTION_HEADING_NODE
+
+	if !implemented {
+		_, check := a.tab.FindIdentifier(identifier, a.root)
+		if check != nil {
+			if check.Level == a.depth {
+				token := parsetree.Children[1].TokenValue
+				a.report(a.newRedeclarationError(identifier, token), token)
+			}
 		}
-	}
 
-	tabIndex := len(a.tab)
-	a.tab = append(a.tab, dt.TabEntry{
-		Identifier: identifier,
-		Link:       a.root,
-		Object:     dt.TAB_ENTRY_FUNC,
-		Level:      a.depth,
-	})
+		tabIndex = len(a.tab)
+		a.tab = append(a.tab, dt.TabEntry{
+			Identifier: identifier,
+			Link:       a.root,
+			Object:     dt.TAB_ENTRY_FUNC,
+			Level:      a.depth,
+		})
+
+		a.root = tabIndex
+	}
 
-	a.root = tabIndex
+	// The result type the interface heading gave, before the
+	// implementation's own replaces it.
+	headingType := semanticType{StaticType: a.tab[tabIndex].Type, Reference: a.tab[tabIndex].Reference}
 
 	root := a.root
 	stackSize := a.stackSize
@@ -61,7 +72,8 @@ func (a *SemanticAnalyzer) analyzeFunctionDeclaration(parsetree *dt.ParseTree) (
 			_, check := a.tab.FindIdentifier(identifier, a.root)
 			if check != nil {
 				if check.Level == a.depth {
-					return nil, errors.New("cannot redeclare identifier in the same scope")
+					token := parsetree.Children[1].TokenValue
+					a.report(a.newRedeclarationError(identifier, token), token)
 				}
 			}
 
@@ -81,48 +93,39 @@ func (a *SemanticAnalyzer) analyzeFunctionDeclaration(parsetree *dt.ParseTree) (
 			a.root++
 
 		case dt.DECLARATION_PART_NODE:
+			if implemented && (!a.sameParameters(a.btab[a.tab[tabIndex].Data], parameters) || !a.checkTypeEquality(headingType, semanticType{StaticType: returnEntry.Type, Reference: returnEntry.Reference})) {
+				token := parsetree.Children[1].TokenValue
+				a.report(a.newHeadingMismatchError(identifier, token), token)
+			}
+
+			a.registerBlock(tabIndex, parameters, dt.BtabEntry{
+				ReturnEnd:  returnIndex,
+				ParamSize:  paramSize,
+				ReturnSize: returnSize,
+			}, implemented)
+
 			declarations, err = a.analyzeDeclarationPart(&child)
+			a.completeBlock(tabIndex, declarations, a.stackSize-paramSize)
 
 		case dt.COMPOUND_STATEMENT_NODE:
 			block, err = a.analyzeCompoundStatement(&child)
 		}
 
 		if err != nil {
-			return nil, err
+			a.report(err, child.FirstToken())
+			err = nil
 		}
 	}
 
-	varSize := a.stackSize - paramSize
-
-	start := 0
-	paramEnd := 0
-	varEnd := 0
-
-	for _, part := range declarations {
-		if part.SelfType == dt.DST_VARIABLE_DECLARATIONS && len(part.Children) != 0 {
-			start = part.Children[0].Data
-			varEnd = part.Children[len(part.Children)-1].Data
-		}
-	}
-
-	if parameters != nil {
-		start = parameters.Children[0].Data
-		paramEnd = parameters.Children[len(parameters.Children)-1].Data
+	if heading {
+		a.registerBlock(tabIndex, parameters, dt.BtabEntry{
+			ReturnEnd:  returnIndex,
+			ParamSize:  paramSize,
+			ReturnSize: returnSize,
+		}, false)
+		a.forward[tabIndex] = parsetree.Children[1].TokenValue
 	}
 
-	btabIndex := len(a.btab)
-	a.btab = append(a.btab, dt.BtabEntry{
-		Start:        start,
-		ParamEnd:     paramEnd,
-		ReturnEnd:    returnIndex,
-		End:          varEnd,
-		ParamSize:    paramSize,
-		ReturnSize:   returnSize,
-		VariableSize: varSize,
-	})
-
-	a.tab[tabIndex].Data = btabIndex
-
 	a.depth--
 	a.stackSize = stackSize
 	a.root = root
@@ -136,7 +139,9 @@ func (a *SemanticAnalyzer) analyzeFunctionDeclaration(parsetree *dt.ParseTree) (
 	}
 
 	children = append(children, declarations...)
-	children = append(children, *block)
+	if block != nil {
+		children = append(children, *block)
+	}
 
 	return &dt.DecoratedSyntaxTree{
 		SelfType: dt.DST_FUNCTION,
@@ -17,9 +17,9 @@ func (a *SemanticAnalyzer) analyzeIfStatement(parsetree *dt.ParseTree) (*dt.Deco
 		return nil, err
 	}
 
-	if typ.StaticType != dt.TAB_ENTRY_BOOLEAN {
+	if !typ.isError() && typ.StaticType != dt.TAB_ENTRY_BOOLEAN {
 		token := parsetree.Children[0].TokenValue
-		return nil, a.newConditionTypeError(typ.StaticType.String(), token)
+		a.report(a.newConditionTypeError(typ.StaticType.String(), token), token)
 	}
 
 	thenBlock, err := a.analyzeStatement(&parsetree.Children[3])
@@ -28,22 +28,27 @@ func (a *SemanticAnalyzer) analyzeIfStatement(parsetree *dt.ParseTree) (*dt.Deco
 		return nil, err
 	}
 
-	elseBlock, err := a.analyzeStatement(&parsetree.Children[5])
+	condition.Property = dt.DST_CONDITION
+	thenBlock.Property = dt.DST_THEN
 
-	if err != nil {
-		return nil, err
+	children := []dt.DecoratedSyntaxTree{
+		*condition,
+		*thenBlock,
 	}
 
-	condition.Property = dt.DST_CONDITION
-	thenBlock.Property = dt.DST_THEN
-	elseBlock.Property = dt.DST_ELSE
+	if len(parsetree.Children) > 5 {
+		elseBlock, err := a.analyzeStatement(&parsetree.Children[5])
+
+		if err != nil {
+			return nil, err
+		}
+
+		elseBlock.Property = dt.DST_ELSE
+		children = append(children, *elseBlock)
+	}
 
 	return &dt.DecoratedSyntaxTree{
 		SelfType: dt.DST_IF_BLOCK,
-		Children: []dt.DecoratedSyntaxTree{
-			*condition,
-			*thenBlock,
-			*elseBlock,
-		},
+		Children: children,
 	}, nil
 }
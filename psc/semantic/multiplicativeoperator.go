@@ -11,16 +11,16 @@ func (a *SemanticAnalyzer) analyzeMultiplicativeOperator(parsetree *dt.ParseTree
 		return dt.DST_ADD_OPERATOR, errors.New("operator is not multiplicative")
 	}
 
-	switch parsetree.Children[0].TokenValue.Lexeme {
+	switch parsetree.Children[0].TokenValue.Key {
 	case "*":
 		return dt.DST_MUL_OPERATOR, nil
 	case "/":
 		return dt.DST_DIV_OPERATOR, nil
-	case "bagi":
+	case a.lang.Lexeme(dt.KW_DIV):
 		return dt.DST_DIV_OPERATOR, nil
-	case "mod":
+	case a.lang.Lexeme(dt.KW_MOD):
 		return dt.DST_MOD_OPERATOR, nil
-	case "dan":
+	case a.lang.Lexeme(dt.KW_AND):
 		return dt.DST_AND_OPERATOR, nil
 	default:
 		return dt.DST_ADD_OPERATOR, errors.New("unknown multiplicative operator")
@@ -0,0 +1,91 @@
+package semantic
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// analyzeNumber reads a NUMBER token: a decimal integer, a real, or a
+// Turbo Pascal style $FF hex or %1010 binary integer. Integers must fit in
+// the target's int, and reals are stored as the bits of a float of the
+// same size.
+func (a *SemanticAnalyzer) analyzeNumber(token *dt.Token) (*dt.DecoratedSyntaxTree, semanticType, error) {
+	lexeme := token.Key
+
+	base, digits := 10, lexeme
+	switch {
+	case strings.HasPrefix(lexeme, "$"):
+		base, digits = 16, lexeme[1:]
+	case strings.HasPrefix(lexeme, "%"):
+		base, digits = 2, lexeme[1:]
+	case strings.ContainsAny(lexeme, ".e"):
+		return a.analyzeReal(token)
+	}
+
+	val, err := strconv.ParseInt(digits, base, strconv.IntSize)
+	if errors.Is(err, strconv.ErrRange) {
+		return nil, semanticType{}, NewSemanticError(
+			fmt.Sprintf("integer literal %s out of range: the largest integer is %d", token.Lexeme, math.MaxInt),
+			token,
+			"numeric literal",
+		)
+	}
+	if err != nil {
+		return nil, semanticType{}, NewSemanticError(fmt.Sprintf("invalid integer literal %s", token.Lexeme), token, "numeric literal")
+	}
+
+	return &dt.DecoratedSyntaxTree{
+		SelfType: dt.DST_INT_LITERAL,
+		Data:     int(val),
+	}, semanticType{StaticType: dt.TAB_ENTRY_INTEGER}, nil
+}
+
+func (a *SemanticAnalyzer) analyzeReal(token *dt.Token) (*dt.DecoratedSyntaxTree, semanticType, error) {
+	val, err := strconv.ParseFloat(token.Key, strconv.IntSize)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return nil, semanticType{}, NewSemanticError(fmt.Sprintf("invalid real literal %s", token.Lexeme), token, "numeric literal")
+	}
+
+	// ParseFloat gives ±Inf when the literal overflows, and 0 without an
+	// error when it underflows.
+	if math.IsInf(val, 0) {
+		return nil, semanticType{}, NewSemanticError(
+			fmt.Sprintf("real literal %s out of range: too large for a %d-bit real", token.Lexeme, strconv.IntSize),
+			token,
+			"numeric literal",
+		)
+	}
+	if val == 0 && nonZeroMantissa(token.Key) {
+		return nil, semanticType{}, NewSemanticError(
+			fmt.Sprintf("real literal %s out of range: too small for a %d-bit real", token.Lexeme, strconv.IntSize),
+			token,
+			"numeric literal",
+		)
+	}
+
+	var data int
+	if strconv.IntSize == 32 {
+		data = int(math.Float32bits(float32(val)))
+	} else {
+		data = int(math.Float64bits(val))
+	}
+
+	return &dt.DecoratedSyntaxTree{
+		SelfType: dt.DST_REAL_LITERAL,
+		Data:     data,
+	}, semanticType{StaticType: dt.TAB_ENTRY_REAL}, nil
+}
+
+// nonZeroMantissa reports whether a real literal has a non-zero digit
+// before its exponent.
+func nonZeroMantissa(lexeme string) bool {
+	if i := strings.IndexAny(lexeme, "eE"); i >= 0 {
+		lexeme = lexeme[:i]
+	}
+	return strings.ContainsAny(lexeme, "123456789")
+}
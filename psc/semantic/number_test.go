@@ -0,0 +1,30 @@
+package semantic_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRealLiteralRange(t *testing.T) {
+	tests := []struct {
+		literal string
+		want    string // part of the error, empty when the literal is valid
+	}{
+		{"1.5e-3", ""},
+		{"0.0e-400", ""},
+		{"1e400", "too large"},
+		{"1e-400", "too small"},
+		{"2E-324", "too small"},
+	}
+
+	for _, tt := range tests {
+		res := check(t, "program R;\nvariabel x: real;\nmulai\n  x := "+tt.literal+";\nselesai.\n")
+
+		switch {
+		case tt.want == "" && len(res.SemanticErrors) > 0:
+			t.Errorf("%s: unexpected diagnostics: %v", tt.literal, res.Diagnostics())
+		case tt.want != "" && (len(res.SemanticErrors) != 1 || !strings.Contains(res.SemanticErrors[0].Message, tt.want)):
+			t.Errorf("%s: got %v, want an error saying %q", tt.literal, res.Diagnostics(), tt.want)
+		}
+	}
+}
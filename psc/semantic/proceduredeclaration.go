@@ -7,28 +7,35 @@ import (
 )
 
 func (a *SemanticAnalyzer) analyzeProcedureDeclaration(parsetree *dt.ParseTree) (*dt.DecoratedSyntaxTree, error) {
-	if parsetree.RootType != dt.PROCEDURE_DECLARATION_NODE {
+	if parsetree.RootType != dt.PROCEDURE_DECLARATION_NODE && parsetree.RootType != dt.PROCEDURE_HEADING_NODE {
 		return nil, errors.New("expected procedure declaration")
 	}
 
 	identifier := parsetree.Children[1].TokenValue.Lexeme
 
-	_, check := a.tab.FindIdentifier(identifier, a.root)
-	if check != nil {
-		if check.Level == a.depth {
-			return nil, errors.New("cannot redeclare identifier in the same scope")
+	tabIndex := a.implementedHeading(parsetree, dt.TAB_ENTRY_PROC)
+	implemented := tabIndex != -1
+	heading := parsetree.RootType == dt.PROCEDURE_HEADING_NODE
+
+	if !implemented {
+		_, check := a.tab.FindIdentifier(identifier, a.root)
+		if check != nil {
+			if check.Level == a.depth {
+				token := parsetree.Children[1].TokenValue
+				a.report(a.newRedeclarationError(identifier, token), token)
+			}
 		}
-	}
 
-	tabIndex := len(a.tab)
-	a.tab = append(a.tab, dt.TabEntry{
-		Identifier: identifier,
-		Link:       a.root,
-		Object:     dt.TAB_ENTRY_PROC,
-		Level:      a.depth,
-	})
+		tabIndex = len(a.tab)
+		a.tab = append(a.tab, dt.TabEntry{
+			Identifier: identifier,
+			Link:       a.root,
+			Object:     dt.TAB_ENTRY_PROC,
+			Level:      a.depth,
+		})
 
-	a.root = tabIndex
+		a.root = tabIndex
+	}
 
 	root := a.root
 	stackSize := a.stackSize
@@ -47,45 +54,30 @@ func (a *SemanticAnalyzer) analyzeProcedureDeclaration(parsetree *dt.ParseTree)
 			parameters, err = a.analyzeFormalParameterList(&child)
 			paramSize = a.stackSize
 		case dt.DECLARATION_PART_NODE:
+			if implemented && !a.sameParameters(a.btab[a.tab[tabIndex].Data], parameters) {
+				token := parsetree.Children[1].TokenValue
+				a.report(a.newHeadingMismatchError(identifier, token), token)
+			}
+
+			a.registerBlock(tabIndex, parameters, dt.BtabEntry{ParamSize: paramSize}, implemented)
+
 			declarations, err = a.analyzeDeclarationPart(&child)
+			a.completeBlock(tabIndex, declarations, a.stackSize-paramSize)
 		case dt.COMPOUND_STATEMENT_NODE:
 			block, err = a.analyzeCompoundStatement(&child)
 		}
 
 		if err != nil {
-			return nil, err
+			a.report(err, child.FirstToken())
+			err = nil
 		}
 	}
 
-	varSize := a.stackSize - paramSize
-
-	start := 0
-	paramEnd := 0
-	varEnd := 0
-
-	for _, part := range declarations {
-		if part.SelfType == dt.DST_VARIABLE_DECLARATIONS && len(part.Children) != 0 {
-			start = part.Children[0].Data
-			varEnd = part.Children[len(part.Children)-1].Data
-		}
+	if heading {
+		a.registerBlock(tabIndex, parameters, dt.BtabEntry{ParamSize: paramSize}, false)
+		a.forward[tabIndex] = parsetree.Children[1].TokenValue
 	}
 
-	if parameters != nil {
-		start = parameters.Children[0].Data
-		paramEnd = parameters.Children[len(parameters.Children)-1].Data
-	}
-
-	btabIndex := len(a.btab)
-	a.btab = append(a.btab, dt.BtabEntry{
-		Start:        start,
-		End:          varEnd,
-		ParamEnd:     paramEnd,
-		ParamSize:    paramSize,
-		VariableSize: varSize,
-	})
-
-	a.tab[tabIndex].Data = btabIndex
-
 	a.depth--
 	a.stackSize = stackSize
 	a.root = root
@@ -99,7 +91,9 @@ func (a *SemanticAnalyzer) analyzeProcedureDeclaration(parsetree *dt.ParseTree)
 	}
 
 	children = append(children, declarations...)
-	children = append(children, *block)
+	if block != nil {
+		children = append(children, *block)
+	}
 
 	return &dt.DecoratedSyntaxTree{
 		SelfType: dt.DST_PROCEDURE,
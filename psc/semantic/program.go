@@ -2,6 +2,7 @@ package semantic
 
 import (
 	"errors"
+	"slices"
 
 	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
 )
@@ -17,22 +18,38 @@ func (a *SemanticAnalyzer) analyzeProgram(parsetree *dt.ParseTree) (*dt.Decorate
 		return nil, err
 	}
 
-	declarations, err := a.analyzeDeclarationPart(&parsetree.Children[1])
+	parts := parsetree.Children[1:]
+	if parts[0].RootType == dt.USES_CLAUSE_NODE {
+		a.uses = a.useUnits(parsetree.Uses())
+		parts = parts[1:]
+	}
+
+	declarations, err := a.analyzeDeclarationPart(&parts[0])
 
 	if err != nil {
-		return nil, err
+		a.report(err, parts[0].FirstToken())
 	}
 
-	block, err := a.analyzeCompoundStatement(&parsetree.Children[2])
+	block, err := a.analyzeCompoundStatement(&parts[1])
 
 	if err != nil {
-		return nil, err
+		a.report(err, parts[1].FirstToken())
+		block = &dt.DecoratedSyntaxTree{SelfType: dt.DST_BLOCK}
+	}
+
+	// The units used run as part of the program: their declarations come
+	// before its own, and their initialization blocks, in the order the
+	// units were analyzed, before its statements.
+	if len(a.unitInits) > 0 {
+		spanned(block, &parts[1])
+		block.Children = append(slices.Clone(a.unitInits), block.Children...)
 	}
 
 	return &dt.DecoratedSyntaxTree{
 		Property: dt.DST_ROOT,
 		SelfType: dt.DST_PROGRAM,
+		Span:     parsetree.Span,
 		Data:     headerIndex,
-		Children: append(declarations, *block),
+		Children: append(append(slices.Clone(a.unitDecls), declarations...), *block),
 	}, nil
 }
@@ -68,7 +68,7 @@ func (a *SemanticAnalyzer) analyzeRecordType(parsetree *dt.ParseTree, identifier
 	btabEntry.End = len(a.tab) - 1
 
 	totalSize := 0
-	for i := btabEntry.Start; i < btabEntry.End; i++ {
+	for i := btabEntry.Start; i <= btabEntry.End; i++ {
 		fieldType := semanticType{
 			StaticType: a.tab[i].Type,
 			Reference:  a.tab[i].Reference,
@@ -11,7 +11,7 @@ func (a *SemanticAnalyzer) analyzeRelationalOperator(parsetree *dt.ParseTree) (d
 		return dt.DST_ADD_OPERATOR, errors.New("operator is not relational")
 	}
 
-	switch parsetree.Children[0].TokenValue.Lexeme {
+	switch parsetree.Children[0].TokenValue.Key {
 	case ">":
 		return dt.DST_GT_OPERATOR, nil
 	case "<":
@@ -22,7 +22,7 @@ func (a *SemanticAnalyzer) analyzeRelationalOperator(parsetree *dt.ParseTree) (d
 		return dt.DST_LE_OPERATOR, nil
 	case "=":
 		return dt.DST_EQ_OPERATOR, nil
-	case "!=":
+	case "<>", "!=":
 		return dt.DST_NE_OPERATOR, nil
 	default:
 		return dt.DST_ADD_OPERATOR, errors.New("unknown relational operator")
@@ -0,0 +1,45 @@
+package semantic
+
+import (
+	"errors"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+func (a *SemanticAnalyzer) analyzeRepeatStatement(parsetree *dt.ParseTree) (*dt.DecoratedSyntaxTree, error) {
+	if parsetree.RootType != dt.REPEAT_STATEMENT_NODE {
+		return nil, errors.New("expected repeat block")
+	}
+
+	statements, err := a.analyzeStatementList(&parsetree.Children[1])
+
+	if err != nil {
+		return nil, err
+	}
+
+	condition, typ, err := a.analyzeExpression(&parsetree.Children[3])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !typ.isError() && typ.StaticType != dt.TAB_ENTRY_BOOLEAN {
+		// Get token from 'sampai' keyword
+		token := parsetree.Children[2].TokenValue
+		a.report(a.newConditionTypeError(typ.StaticType.String(), token), token)
+	}
+
+	condition.Property = dt.DST_CONDITION
+
+	return &dt.DecoratedSyntaxTree{
+		SelfType: dt.DST_REPEAT_BLOCK,
+		Children: []dt.DecoratedSyntaxTree{
+			{
+				Property: dt.DST_EXECUTE,
+				SelfType: dt.DST_BLOCK,
+				Children: statements,
+			},
+			*condition,
+		},
+	}, nil
+}
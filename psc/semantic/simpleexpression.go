@@ -7,56 +7,53 @@ import (
 )
 
 func (a *SemanticAnalyzer) analyzeSimpleExpression(parseTree *dt.ParseTree) (*dt.DecoratedSyntaxTree, semanticType, error) {
-	beginOffset := 0
-	negated := false
+	var sign *dt.ParseTree
 
 	if parseTree.Children[0].RootType == dt.TOKEN_NODE {
-		switch parseTree.Children[0].TokenValue.Lexeme {
-		case "-":
-			negated = true
-			fallthrough
-		case "+":
-			beginOffset = 1
+		switch parseTree.Children[0].TokenValue.Key {
+		case "-", "+":
+			sign = &parseTree.Children[0]
 		default:
 			return nil, semanticType{}, errors.New("unknown modifier at beginning of expression")
 		}
 	}
 
-	var dst *dt.DecoratedSyntaxTree
-	var typ semanticType
-	var err error
-
-	if len(parseTree.Children) == 1 {
-		dst, typ, err = a.analyzeTerm(&parseTree.Children[beginOffset])
-	} else {
-		dst, typ, err = a.recurseSimpleExpression(parseTree.Children[beginOffset:])
+	if sign != nil {
+		return a.recurseSimpleExpression(parseTree.Children[1:], sign)
 	}
 
-	if err != nil {
-		return nil, typ, err
-	}
+	return a.recurseSimpleExpression(parseTree.Children, nil)
+}
 
-	if negated {
-		switch typ.StaticType {
-		case dt.TAB_ENTRY_INTEGER:
-		case dt.TAB_ENTRY_REAL:
-		default:
-			return nil, typ, errors.New("cannot negate non numeric expression")
-		}
+// analyzeSignedTerm analyzes the first term of a simple expression. A
+// leading sign binds to that term only, so -5 - 1 is (-5) - 1.
+func (a *SemanticAnalyzer) analyzeSignedTerm(term *dt.ParseTree, sign *dt.ParseTree) (*dt.DecoratedSyntaxTree, semanticType, error) {
+	dst, typ, err := a.analyzeTerm(term)
+
+	if err != nil || sign == nil || sign.TokenValue.Key != "-" {
+		return dst, typ, err
+	}
 
-		dst.Property = dt.DST_OPERAND
-		return &dt.DecoratedSyntaxTree{
-			SelfType: dt.DST_NEG_OPERATOR,
-			Children: []dt.DecoratedSyntaxTree{*dst},
-		}, typ, nil
+	switch typ.StaticType {
+	case dt.TAB_ENTRY_INTEGER:
+	case dt.TAB_ENTRY_REAL:
+	case typeError:
+		return dst, typ, nil
+	default:
+		return a.errorExpression(errors.New("cannot negate non numeric expression"), sign.TokenValue)
 	}
 
-	return dst, typ, nil
+	dst.Property = dt.DST_OPERAND
+	return &dt.DecoratedSyntaxTree{
+		SelfType: dt.DST_NEG_OPERATOR,
+		Span:     sign.Span.Join(term.Span),
+		Children: []dt.DecoratedSyntaxTree{*dst},
+	}, typ, nil
 }
 
-func (a *SemanticAnalyzer) recurseSimpleExpression(nodes []dt.ParseTree) (*dt.DecoratedSyntaxTree, semanticType, error) {
+func (a *SemanticAnalyzer) recurseSimpleExpression(nodes []dt.ParseTree, sign *dt.ParseTree) (*dt.DecoratedSyntaxTree, semanticType, error) {
 	if len(nodes) == 1 {
-		return a.analyzeTerm(&nodes[0])
+		return a.analyzeSignedTerm(&nodes[0], sign)
 	}
 
 	optype, err := a.analyzeAdditiveOperator(&nodes[len(nodes)-2])
@@ -70,7 +67,7 @@ func (a *SemanticAnalyzer) recurseSimpleExpression(nodes []dt.ParseTree) (*dt.De
 		Children: make([]dt.DecoratedSyntaxTree, 2),
 	}
 
-	lval, ltype, err := a.recurseSimpleExpression(nodes[:len(nodes)-2])
+	lval, ltype, err := a.recurseSimpleExpression(nodes[:len(nodes)-2], sign)
 
 	if err != nil {
 		return nil, ltype, err
@@ -82,16 +79,20 @@ func (a *SemanticAnalyzer) recurseSimpleExpression(nodes []dt.ParseTree) (*dt.De
 		return nil, rtype, err
 	}
 
+	if ltype.isError() || rtype.isError() {
+		return a.errorExpression(nil, nil)
+	}
+
 	promotedLval, promotedRval, resultType, compatible := a.promoteTypes(lval, ltype, rval, rtype)
 
 	if !compatible {
 		token := nodes[len(nodes)-2].Children[0].TokenValue
-		return nil, ltype, a.newOperatorTypeError(
+		return a.errorExpression(a.newOperatorTypeError(
 			token.Lexeme,
 			ltype.StaticType.String(),
 			rtype.StaticType.String(),
 			token,
-		)
+		), token)
 	}
 
 	dst.Children[0] = *promotedLval
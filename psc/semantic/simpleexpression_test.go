@@ -0,0 +1,26 @@
+package semantic_test
+
+import (
+	"testing"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+func TestLeadingSignBindsToFirstTerm(t *testing.T) {
+	res := check(t, `program M;
+variabel a: integer;
+mulai
+  a := -5 - 1;
+selesai.
+`)
+
+	if len(res.SemanticErrors) > 0 {
+		t.Fatalf("unexpected errors: %v", res.SemanticErrors)
+	}
+
+	// program > block > assign-op > value
+	value := res.DST.Children[len(res.DST.Children)-1].Children[0].Children[1]
+	if value.SelfType != dt.DST_SUB_OPERATOR || value.Children[0].SelfType != dt.DST_NEG_OPERATOR {
+		t.Errorf("-5 - 1 is %v, want (-5) - 1", value)
+	}
+}
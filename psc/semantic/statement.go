@@ -2,11 +2,36 @@ package semantic
 
 import (
 	"errors"
+	"fmt"
 
 	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
 )
 
+// analyzeStatement reports a statement that fails to check and replaces it
+// with an empty block, so the statements after it are still analyzed.
 func (a *SemanticAnalyzer) analyzeStatement(parsetree *dt.ParseTree) (*dt.DecoratedSyntaxTree, error) {
+	outer := a.at
+	a.at = parsetree.Span
+	defer func() { a.at = outer }()
+
+	dst, err := a.analyzeStatementKind(parsetree)
+	token := parsetree.FirstToken()
+
+	if err != nil {
+		a.report(err, token)
+		dst = &dt.DecoratedSyntaxTree{SelfType: dt.DST_BLOCK}
+	}
+
+	if dst != nil && token != nil {
+		dst.Line = token.Line
+	}
+
+	spanned(dst, parsetree)
+
+	return dst, nil
+}
+
+func (a *SemanticAnalyzer) analyzeStatementKind(parsetree *dt.ParseTree) (*dt.DecoratedSyntaxTree, error) {
 	switch parsetree.RootType {
 	case dt.COMPOUND_STATEMENT_NODE:
 		return a.analyzeCompoundStatement(parsetree)
@@ -14,12 +39,19 @@ func (a *SemanticAnalyzer) analyzeStatement(parsetree *dt.ParseTree) (*dt.Decora
 		return a.analyzeIfStatement(parsetree)
 	case dt.WHILE_STATEMENT_NODE:
 		return a.analyzeWhileStatement(parsetree)
+	case dt.REPEAT_STATEMENT_NODE:
+		return a.analyzeRepeatStatement(parsetree)
 	case dt.FOR_STATEMENT_NODE:
 		return a.analyzeForStatement(parsetree)
+	case dt.CASE_STATEMENT_NODE:
+		return a.analyzeCaseStatement(parsetree)
 	case dt.ASSIGNMENT_STATEMENT_NODE:
 		return a.analyzeAssignmentStatement(parsetree)
 	case dt.SUBPROGRAM_CALL_NODE:
 		dst, _, err := a.analyzeSubprogramCall(parsetree)
+		if err == nil && dst.SelfType == dt.DST_BUILTIN_CALL && !dt.Builtin(dst.Data).IsProcedure() {
+			return nil, fmt.Errorf("result of function '%s' is not used", dt.Builtin(dst.Data))
+		}
 		return dst, err
 	default:
 		return nil, errors.New("unrecognized statement")
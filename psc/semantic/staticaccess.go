@@ -35,11 +35,54 @@ func (a *SemanticAnalyzer) analyzeStaticAccess(parsetree *dt.ParseTree, prev *dt
 		root = a.btab[a.tab[typeIndex].Reference].End
 	}
 
+	// unit.name reaches what a unit exports. Like any other declaration,
+	// one with the name of the unit hides it.
+	unit := -1
+	if prev == nil && len(nodes) > 1 && nodes[0].RootType == dt.TOKEN_NODE {
+		if index, entry := a.find(nodes[0].TokenValue.Lexeme); entry != nil && entry.Object == dt.TAB_ENTRY_UNIT {
+			unit = index
+			nodes = nodes[1:]
+		}
+	}
+
 	switch nodes[0].RootType {
 	case dt.TOKEN_NODE:
-		tabIndex, tabEntry := a.tab.FindIdentifier(nodes[0].TokenValue.Lexeme, root)
+		var tabIndex int
+		var tabEntry *dt.TabEntry
+
+		switch {
+		case unit != -1:
+			tabIndex, tabEntry, err = a.findExported(unit, nodes[0].TokenValue)
+			if err != nil {
+				return nil, semanticType{}, err
+			}
+		case prev != nil:
+			tabIndex, tabEntry = a.tab.FindIdentifier(nodes[0].TokenValue.Lexeme, root)
+		default:
+			tabIndex, tabEntry = a.find(nodes[0].TokenValue.Lexeme)
+		}
+
+		if tabIndex == -1 && prev == nil && len(nodes) == 1 {
+			// The DFAs lex the boolean constants as identifiers, so they
+			// only become literals once no declaration shadows them.
+			lexeme := nodes[0].TokenValue.Lexeme
+
+			if a.lang.Is(dt.KW_TRUE, lexeme) || a.lang.Is(dt.KW_FALSE, lexeme) {
+				data := 0
+				if a.lang.Is(dt.KW_TRUE, lexeme) {
+					data = 1
+				}
+
+				return &dt.DecoratedSyntaxTree{
+					SelfType: dt.DST_BOOL_LITERAL,
+					Data:     data,
+					Span:     nodes[0].Span,
+				}, semanticType{StaticType: dt.TAB_ENTRY_BOOLEAN}, nil
+			}
+		}
+
 		if tabIndex == -1 {
-			return nil, semanticType{}, errors.New("undeclared identifier")
+			return nil, semanticType{}, a.newUndeclaredIdentError(nodes[0].TokenValue.Lexeme, nodes[0].TokenValue)
 		}
 
 		typ = semanticType{
@@ -52,6 +95,7 @@ func (a *SemanticAnalyzer) analyzeStaticAccess(parsetree *dt.ParseTree, prev *dt
 			prev = &dt.DecoratedSyntaxTree{
 				SelfType: dt.DST_RECORD_FIELD,
 				Data:     tabIndex,
+				Span:     prev.Span.Join(nodes[0].Span),
 				Children: []dt.DecoratedSyntaxTree{*prev},
 			}
 		} else {
@@ -73,10 +117,21 @@ func (a *SemanticAnalyzer) analyzeStaticAccess(parsetree *dt.ParseTree, prev *dt
 			prev = &dt.DecoratedSyntaxTree{
 				SelfType: dstType,
 				Data:     tabIndex,
+				Span:     nodes[0].Span,
 			}
 		}
 	case dt.ARRAY_ACCESS_NODE:
-		prev, typ, err = a.analyzeArrayAccess(&nodes[0], prev)
+		if unit != -1 {
+			var index int
+			var entry *dt.TabEntry
+			index, entry, err = a.findExported(unit, nodes[0].Children[0].TokenValue)
+			if err != nil {
+				return nil, semanticType{}, err
+			}
+			prev, typ, err = a.analyzeArrayAccessOf(&nodes[0], nil, index, entry)
+		} else {
+			prev, typ, err = a.analyzeArrayAccess(&nodes[0], prev)
+		}
 		if err != nil {
 			return nil, semanticType{}, err
 		}
@@ -101,7 +156,7 @@ func (a *SemanticAnalyzer) analyzeStaticAccess(parsetree *dt.ParseTree, prev *dt
 		case dt.TOKEN_NODE:
 			tabIndex, tabEntry := a.tab.FindIdentifier(node.TokenValue.Lexeme, root)
 			if tabIndex == -1 {
-				return nil, semanticType{}, errors.New("undeclared identifier")
+				return nil, semanticType{}, a.newUndeclaredFieldError(node.TokenValue.Lexeme, a.tab[typeIndex].Identifier, node.TokenValue)
 			}
 
 			typ = semanticType{
@@ -113,6 +168,7 @@ func (a *SemanticAnalyzer) analyzeStaticAccess(parsetree *dt.ParseTree, prev *dt
 			prev = &dt.DecoratedSyntaxTree{
 				SelfType: dt.DST_RECORD_FIELD,
 				Data:     tabIndex,
+				Span:     prev.Span.Join(node.Span),
 				Children: []dt.DecoratedSyntaxTree{*prev},
 			}
 		case dt.ARRAY_ACCESS_NODE:
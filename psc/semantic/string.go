@@ -0,0 +1,106 @@
+package semantic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// decodeString returns the value of a string or char literal as written:
+// quoted parts, in which a doubled quote stands for one, and #65 or #$41
+// character codes, all run together.
+func decodeString(lexeme string) ([]rune, error) {
+	var value []rune
+
+	for i := 0; i < len(lexeme); {
+		switch lexeme[i] {
+		case '\'':
+			i++
+			for {
+				end := strings.IndexByte(lexeme[i:], '\'')
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated string literal %s", lexeme)
+				}
+				value = append(value, []rune(lexeme[i:i+end])...)
+				i += end + 1
+
+				if i < len(lexeme) && lexeme[i] == '\'' {
+					value = append(value, '\'')
+					i++
+					continue
+				}
+				break
+			}
+
+		case '#':
+			i++
+			code := i
+			base := 10
+			if i < len(lexeme) && lexeme[i] == '$' {
+				base = 16
+				i++
+			}
+
+			start := i
+			for i < len(lexeme) && lexeme[i] != '\'' && lexeme[i] != '#' {
+				i++
+			}
+
+			n, err := strconv.ParseUint(lexeme[start:i], base, 32)
+			if err != nil || n > 255 {
+				return nil, fmt.Errorf("character code #%s out of range: must be 0..255", lexeme[code:i])
+			}
+			value = append(value, rune(n))
+
+		default:
+			return nil, fmt.Errorf("invalid string literal %s", lexeme)
+		}
+	}
+
+	return value, nil
+}
+
+func (a *SemanticAnalyzer) analyzeCharLiteral(token *dt.Token) (*dt.DecoratedSyntaxTree, semanticType, error) {
+	value, err := decodeString(token.Lexeme)
+	if err != nil {
+		return nil, semanticType{}, NewSemanticError(err.Error(), token, "character literal")
+	}
+	if len(value) != 1 {
+		return nil, semanticType{}, NewSemanticError(fmt.Sprintf("character literal %s is not one character", token.Lexeme), token, "character literal")
+	}
+
+	return &dt.DecoratedSyntaxTree{
+		SelfType: dt.DST_CHAR_LITERAL,
+		Data:     int(value[0]),
+	}, semanticType{StaticType: dt.TAB_ENTRY_CHAR}, nil
+}
+
+// analyzeStringLiteral interns the value of token in the string table.
+// Length counts characters, not bytes.
+func (a *SemanticAnalyzer) analyzeStringLiteral(token *dt.Token) (*dt.DecoratedSyntaxTree, semanticType, error) {
+	runes, err := decodeString(token.Lexeme)
+	if err != nil {
+		return nil, semanticType{}, NewSemanticError(err.Error(), token, "string literal")
+	}
+	value := string(runes)
+
+	stridx, _ := a.strtab.FindString(value)
+
+	if stridx == -1 {
+		stridx = len(a.strtab)
+		a.strtab = append(a.strtab, dt.StrTabEntry{
+			Length: len(runes),
+			String: value,
+		})
+	}
+
+	return &dt.DecoratedSyntaxTree{
+		SelfType: dt.DST_STR_LITERAL,
+		Data:     stridx,
+	}, semanticType{
+		StaticType: dt.TAB_ENTRY_ALIAS,
+		Reference:  0,
+	}, nil
+}
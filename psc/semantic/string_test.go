@@ -0,0 +1,34 @@
+package semantic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeString(t *testing.T) {
+	tests := []struct {
+		literal string
+		want    string
+		err     string // part of the error, empty when the literal is valid
+	}{
+		{"'it''s'", "it's", ""},
+		{"#13#10", "\r\n", ""},
+		{"#$41", "A", ""},
+		{"'a'#10'b'", "a\nb", ""},
+		{"''", "", ""},
+		{"#256", "", "out of range"},
+	}
+
+	for _, tt := range tests {
+		got, err := decodeString(tt.literal)
+
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.literal, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: got error %v, want one saying %q", tt.literal, err, tt.err)
+		case tt.err == "" && string(got) != tt.want:
+			t.Errorf("%s: decoded to %q, want %q", tt.literal, string(got), tt.want)
+		}
+	}
+}
@@ -11,11 +11,47 @@ func (a *SemanticAnalyzer) analyzeSubprogramCall(parseTree *dt.ParseTree) (*dt.D
 		return nil, semanticType{}, errors.New("parse tree node is not subprogram call node")
 	}
 
-	subprogramIdentifier := parseTree.Children[0].TokenValue.Lexeme
-	index, tabEntry := a.tab.FindIdentifier(subprogramIdentifier, a.root)
+	children := parseTree.Children
+	subprogramIdentifier := children[0].TokenValue.Lexeme
+
+	var index int
+	var tabEntry *dt.TabEntry
+
+	if len(children) > 1 && children[1].TokenValue != nil && children[1].TokenValue.Type == dt.DOT {
+		// unit.name calls a subprogram the unit exports.
+		unitToken := children[0].TokenValue
+		unit, unitEntry := a.find(unitToken.Lexeme)
+		if unitEntry == nil {
+			return nil, semanticType{}, a.newUndeclaredIdentError(unitToken.Lexeme, unitToken)
+		}
+		if unitEntry.Object != dt.TAB_ENTRY_UNIT {
+			return nil, semanticType{}, a.newInvalidTypeError(unitToken.Lexeme, "unit", unitEntry.Object.String(), unitToken)
+		}
+
+		children = children[2:]
+		subprogramIdentifier = children[0].TokenValue.Lexeme
+
+		var err error
+		index, tabEntry, err = a.findExported(unit, children[0].TokenValue)
+		if err != nil {
+			return nil, semanticType{}, err
+		}
+	} else {
+		index, tabEntry = a.find(subprogramIdentifier)
+
+		// Inside a function body its own name resolves to the return
+		// slot, so a recursive call has to continue the lookup past it.
+		if tabEntry != nil && tabEntry.Object == dt.TAB_ENTRY_RETURN {
+			index, tabEntry = a.tab.FindIdentifier(subprogramIdentifier, tabEntry.Link)
+		}
+	}
 
 	if tabEntry == nil {
-		token := parseTree.Children[0].TokenValue
+		if builtin, ok := dt.LookupBuiltin(subprogramIdentifier); ok {
+			return a.analyzeBuiltinCall(builtin, parseTree)
+		}
+
+		token := children[0].TokenValue
 		return nil, semanticType{}, a.newUndeclaredIdentError(subprogramIdentifier, token)
 	}
 
@@ -27,7 +63,7 @@ func (a *SemanticAnalyzer) analyzeSubprogramCall(parseTree *dt.ParseTree) (*dt.D
 	case dt.TAB_ENTRY_PROC:
 		callType = dt.DST_PROCEDURE_CALL
 	default:
-		token := parseTree.Children[0].TokenValue
+		token := children[0].TokenValue
 		return nil, semanticType{}, a.newNotCallableError(
 			subprogramIdentifier,
 			tabEntry.Object.String(),
@@ -39,20 +75,39 @@ func (a *SemanticAnalyzer) analyzeSubprogramCall(parseTree *dt.ParseTree) (*dt.D
 	paramStart := btabEntry.Start
 	paramEnd := btabEntry.ParamEnd
 
-	callParams, callTypes, err := a.analyzeParameterList(&parseTree.Children[2])
+	var callParams []dt.DecoratedSyntaxTree
+	var callTypes []semanticType
+	var err error
 
-	if err != nil {
-		return nil, semanticType{}, err
+	if len(children) > 2 {
+		callParams, callTypes, err = a.analyzeParameterList(&children[2])
+
+		if err != nil {
+			return nil, semanticType{}, err
+		}
+	}
+
+	resultType := semanticType{
+		StaticType: tabEntry.Type,
+		Reference:  tabEntry.Reference,
 	}
 
+	// A call with bad arguments still has the declared result type, so the
+	// surrounding expression is checked as usual.
 	if len(callParams) != (paramEnd - paramStart + 1) {
-		token := parseTree.Children[0].TokenValue
-		return nil, semanticType{}, a.newParameterCountError(
+		token := children[0].TokenValue
+		a.report(a.newParameterCountError(
 			paramEnd-paramStart+1,
 			len(callParams),
 			subprogramIdentifier,
 			token,
-		)
+		), token)
+
+		return &dt.DecoratedSyntaxTree{
+			SelfType: callType,
+			Data:     index,
+			Children: callParams,
+		}, resultType, nil
 	}
 
 	dst := &dt.DecoratedSyntaxTree{
@@ -67,22 +122,19 @@ func (a *SemanticAnalyzer) analyzeSubprogramCall(parseTree *dt.ParseTree) (*dt.D
 			Reference:  a.tab[i].Reference,
 		}
 
-		if !a.checkTypeEquality(declaredType, callTypes[i-paramStart]) {
-			token := parseTree.Children[0].TokenValue
-			return nil, semanticType{}, a.newParameterTypeError(
+		if !callTypes[i-paramStart].isError() && !a.checkTypeEquality(declaredType, callTypes[i-paramStart]) {
+			token := children[0].TokenValue
+			a.report(a.newParameterTypeError(
 				i-paramStart,
 				declaredType.StaticType.String(),
 				callTypes[i-paramStart].StaticType.String(),
 				subprogramIdentifier,
 				token,
-			)
+			), token)
 		}
 
 		dst.Children[i-paramStart] = callParams[i-paramStart]
 	}
 
-	return dst, semanticType{
-		StaticType: tabEntry.Type,
-		Reference:  tabEntry.Reference,
-	}, nil
+	return dst, resultType, nil
 }
@@ -22,3 +22,45 @@ func (a *SemanticAnalyzer) analyzeSubprogramDeclaration(parsetree *dt.ParseTree)
 		return nil, errors.New("expected procedure or function declaration")
 	}
 }
+
+// registerBlock makes entry, with the tab range of the parameters, the
+// block of the subprogram at tabIndex. It is registered before the local
+// declarations are analyzed so that calls from nested subprograms, and
+// recursive calls, can already check their arguments; completeBlock fills
+// in the rest. The implementation of an interface heading takes over the
+// block the heading registered, so calls checked against the heading stay
+// valid.
+func (a *SemanticAnalyzer) registerBlock(tabIndex int, parameters *dt.DecoratedSyntaxTree, entry dt.BtabEntry, implemented bool) {
+	entry.ParamEnd = -1
+
+	if parameters != nil && len(parameters.Children) != 0 {
+		entry.Start = parameters.Children[0].Data
+		entry.ParamEnd = parameters.Children[len(parameters.Children)-1].Data
+	}
+
+	if implemented {
+		a.btab[a.tab[tabIndex].Data] = entry
+		return
+	}
+
+	a.tab[tabIndex].Data = len(a.btab)
+	a.btab = append(a.btab, entry)
+}
+
+// completeBlock records the variables of the subprogram at tabIndex, once
+// its declarations have been analyzed, in the block registerBlock made.
+func (a *SemanticAnalyzer) completeBlock(tabIndex int, declarations []dt.DecoratedSyntaxTree, variableSize int) {
+	entry := &a.btab[a.tab[tabIndex].Data]
+
+	for _, part := range declarations {
+		if part.SelfType == dt.DST_VARIABLE_DECLARATIONS && len(part.Children) != 0 {
+			if entry.ParamEnd < entry.Start {
+				entry.Start = part.Children[0].Data
+				entry.ParamEnd = entry.Start - 1
+			}
+			entry.End = part.Children[len(part.Children)-1].Data
+		}
+	}
+
+	entry.VariableSize = variableSize
+}
@@ -0,0 +1,86 @@
+package semantic_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/compiler"
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+const rules = "../../config/tokenizer_m3.json"
+
+// check runs src through the analyzer, failing the test if it does not get
+// that far.
+func check(t *testing.T, src string) *compiler.Result {
+	t.Helper()
+
+	res, err := compiler.Compile([]byte(src), compiler.Options{Rules: rules, Path: "test.pas"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.LexErrors) > 0 || len(res.ParseErrors) > 0 {
+		t.Fatalf("unexpected diagnostics: %v", res.Diagnostics())
+	}
+	return res
+}
+
+func TestNestedCallToEnclosingSubprogram(t *testing.T) {
+	res := check(t, `program Disp;
+prosedur P(a, b: integer);
+  prosedur Q;
+    prosedur R(k: integer);
+    mulai
+      jika k > 0 maka P(k - 1, b);
+    selesai;
+  mulai
+    R(a);
+  selesai;
+mulai
+  Q;
+selesai;
+mulai
+  P(2, 5);
+selesai.
+`)
+
+	if len(res.SemanticErrors) > 0 {
+		t.Fatalf("unexpected errors: %v", res.SemanticErrors)
+	}
+
+	index := slices.IndexFunc(res.Tab, func(e dt.TabEntry) bool {
+		return strings.EqualFold(e.Identifier, "P") && e.Object == dt.TAB_ENTRY_PROC
+	})
+	if index == -1 {
+		t.Fatal("P not found in tab")
+	}
+
+	block := res.Btab[res.Tab[index].Data]
+	if block.ParamEnd-block.Start+1 != 2 || !strings.EqualFold(res.Tab[block.Start].Identifier, "a") {
+		t.Errorf("block of P = %+v, want the parameters a and b", block)
+	}
+}
+
+func TestNestedCallToEnclosingSubprogramChecksArguments(t *testing.T) {
+	res := check(t, `program Disp;
+prosedur P(a, b: integer);
+  prosedur R(k: integer);
+  mulai
+    jika k > 0 maka P(k - 1);
+  selesai;
+mulai
+  R(a);
+selesai;
+mulai
+  P(1, 5);
+selesai.
+`)
+
+	if len(res.SemanticErrors) != 1 {
+		t.Fatalf("got %v, want one semantic error", res.SemanticErrors)
+	}
+	if err := res.SemanticErrors[0]; !strings.Contains(err.Message, "expected 2, got 1") || err.Line != 5 {
+		t.Errorf("got %v, want a parameter count mismatch for P at line 5", err)
+	}
+}
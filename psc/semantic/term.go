@@ -40,16 +40,20 @@ func (a *SemanticAnalyzer) recurseTerm(nodes []dt.ParseTree) (*dt.DecoratedSynta
 		return nil, rtype, err
 	}
 
+	if ltype.isError() || rtype.isError() {
+		return a.errorExpression(nil, nil)
+	}
+
 	promotedLval, promotedRval, resultType, compatible := a.promoteTypes(lval, ltype, rval, rtype)
 
 	if !compatible {
 		token := nodes[len(nodes)-2].Children[0].TokenValue
-		return nil, ltype, a.newOperatorTypeError(
+		return a.errorExpression(a.newOperatorTypeError(
 			token.Lexeme,
 			ltype.StaticType.String(),
 			rtype.StaticType.String(),
 			token,
-		)
+		), token)
 	}
 
 	dst.Children[0] = *promotedLval
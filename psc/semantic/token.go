@@ -2,119 +2,39 @@ package semantic
 
 import (
 	"errors"
-	"math"
-	"strconv"
-	"strings"
 
 	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
 )
 
 func (a *SemanticAnalyzer) analyzeToken(parsetree *dt.ParseTree) (*dt.DecoratedSyntaxTree, semanticType, error) {
+	dst, typ, err := a.analyzeTokenKind(parsetree)
+	spanned(dst, parsetree)
+	return dst, typ, err
+}
+
+func (a *SemanticAnalyzer) analyzeTokenKind(parsetree *dt.ParseTree) (*dt.DecoratedSyntaxTree, semanticType, error) {
 	if parsetree.RootType != dt.TOKEN_NODE {
 		return nil, semanticType{}, errors.New("parsetree root is not token node")
 	}
 
 	switch parsetree.TokenValue.Type {
 	case dt.CHAR_LITERAL:
-		return &dt.DecoratedSyntaxTree{
-			SelfType: dt.DST_CHAR_LITERAL,
-			Data:     int(parsetree.TokenValue.Lexeme[0]),
-		}, semanticType{StaticType: dt.TAB_ENTRY_CHAR}, nil
+		return a.analyzeCharLiteral(parsetree.TokenValue)
 
 	case dt.STRING_LITERAL:
-		value := parsetree.TokenValue.Lexeme[1 : len(parsetree.TokenValue.Lexeme)-1]
-
-		stridx, _ := a.strtab.FindString(value)
-
-		if stridx == -1 {
-			stridx = len(a.strtab)
-			a.strtab = append(a.strtab, dt.StrTabEntry{
-				Length: len(parsetree.TokenValue.Lexeme),
-				String: parsetree.TokenValue.Lexeme,
-			})
-		}
-
-		return &dt.DecoratedSyntaxTree{
-				SelfType: dt.DST_STR_LITERAL,
-				Data:     stridx,
-			}, semanticType{
-				StaticType: dt.TAB_ENTRY_ALIAS,
-				Reference:  0,
-			}, nil
+		return a.analyzeStringLiteral(parsetree.TokenValue)
 
 	case dt.NUMBER:
-		if strings.ContainsAny(parsetree.TokenValue.Lexeme, "e") {
-			parts := strings.Split(parsetree.TokenValue.Lexeme, "e")
-
-			if len(parts) != 2 {
-				return nil, semanticType{}, errors.New("unexpected error reading float")
-			}
-
-			significant, err := strconv.ParseFloat(parts[0], 64)
-
-			if err != nil {
-				return nil, semanticType{}, errors.New("unexpected error reading float")
-			}
-
-			exponent, err := strconv.ParseFloat(parts[1], 64)
-
-			if err != nil {
-				return nil, semanticType{}, errors.New("unexpected error reading float")
-			}
-
-			rawValue := significant * math.Pow(10, exponent)
-			var data int
-
-			if strconv.IntSize == 32 {
-				data = int(math.Float32bits(float32(rawValue)))
-			} else {
-				data = int(math.Float64bits(float64(rawValue)))
-			}
-
-			return &dt.DecoratedSyntaxTree{
-				SelfType: dt.DST_REAL_LITERAL,
-				Data:     data,
-			}, semanticType{StaticType: dt.TAB_ENTRY_REAL}, nil
-		} else if strings.ContainsAny(parsetree.TokenValue.Lexeme, ".") {
-			val, err := strconv.ParseFloat(parsetree.TokenValue.Lexeme, strconv.IntSize)
-
-			if err != nil {
-				return nil, semanticType{}, errors.New("unexpected error reading float")
-			}
-
-			var data int
-
-			if strconv.IntSize == 32 {
-				data = int(math.Float32bits(float32(val)))
-			} else {
-				data = int(math.Float64bits(val))
-			}
-
-			return &dt.DecoratedSyntaxTree{
-				SelfType: dt.DST_REAL_LITERAL,
-				Data:     data,
-			}, semanticType{StaticType: dt.TAB_ENTRY_REAL}, nil
-		} else {
-			val, err := strconv.ParseInt(parsetree.TokenValue.Lexeme, 10, 64)
-
-			if err != nil {
-				return nil, semanticType{}, errors.New("unexpected error reading integer")
-			}
-
-			return &dt.DecoratedSyntaxTree{
-				SelfType: dt.DST_INT_LITERAL,
-				Data:     int(val),
-			}, semanticType{StaticType: dt.TAB_ENTRY_INTEGER}, nil
-		}
+		return a.analyzeNumber(parsetree.TokenValue)
 
 	case dt.KEYWORD:
-		switch parsetree.TokenValue.Lexeme {
-		case "true":
+		switch parsetree.TokenValue.Key {
+		case a.lang.Lexeme(dt.KW_TRUE):
 			return &dt.DecoratedSyntaxTree{
 				SelfType: dt.DST_BOOL_LITERAL,
 				Data:     1,
 			}, semanticType{StaticType: dt.TAB_ENTRY_BOOLEAN}, nil
-		case "false":
+		case a.lang.Lexeme(dt.KW_FALSE):
 			return &dt.DecoratedSyntaxTree{
 				SelfType: dt.DST_BOOL_LITERAL,
 				Data:     0,
@@ -124,7 +44,7 @@ func (a *SemanticAnalyzer) analyzeToken(parsetree *dt.ParseTree) (*dt.DecoratedS
 		}
 
 	case dt.IDENTIFIER:
-		index, tabEntry := a.tab.FindIdentifier(parsetree.TokenValue.Lexeme, a.root)
+		index, tabEntry := a.find(parsetree.TokenValue.Lexeme)
 
 		if tabEntry == nil {
 			return nil, semanticType{}, a.newUndeclaredIdentError(
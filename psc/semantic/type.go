@@ -16,13 +16,27 @@ func (a *SemanticAnalyzer) analyzeType(parsetree *dt.ParseTree) (int, dt.TabEntr
 	switch child.RootType {
 	case dt.TOKEN_NODE:
 		if child.TokenValue.Type == dt.IDENTIFIER {
-			index, tabEntry := a.tab.FindIdentifier(child.TokenValue.Lexeme, a.root)
+			index, tabEntry := a.find(child.TokenValue.Lexeme)
 
 			if tabEntry == nil {
 				token := child.TokenValue
 				return -1, dt.TabEntry{}, a.newUndeclaredIdentError(child.TokenValue.Lexeme, token)
 			}
 
+			// unit.name names a type the unit exports.
+			if len(parsetree.Children) == 3 {
+				if tabEntry.Object != dt.TAB_ENTRY_UNIT {
+					return -1, dt.TabEntry{}, a.newInvalidTypeError(child.TokenValue.Lexeme, "unit", tabEntry.Object.String(), child.TokenValue)
+				}
+
+				var err error
+				child = parsetree.Children[2]
+				index, tabEntry, err = a.findExported(index, child.TokenValue)
+				if err != nil {
+					return -1, dt.TabEntry{}, err
+				}
+			}
+
 			if tabEntry.Object != dt.TAB_ENTRY_TYPE {
 				token := child.TokenValue
 				return -1, dt.TabEntry{}, a.newInvalidTypeError(
@@ -35,7 +49,7 @@ func (a *SemanticAnalyzer) analyzeType(parsetree *dt.ParseTree) (int, dt.TabEntr
 
 			return index, *tabEntry, nil
 		} else {
-			switch child.TokenValue.Lexeme {
+			switch child.TokenValue.Key {
 			case "integer":
 				return -1, dt.TabEntry{Type: dt.TAB_ENTRY_INTEGER}, nil
 			case "real":
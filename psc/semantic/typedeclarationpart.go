@@ -11,16 +11,18 @@ func (a *SemanticAnalyzer) analyzeTypeDeclarationPart(parsetree *dt.ParseTree) (
 		return nil, errors.New("expected type declaration part")
 	}
 
-	declarations := make([]dt.DecoratedSyntaxTree, len(parsetree.Children)-1)
+	declarations := make([]dt.DecoratedSyntaxTree, 0, len(parsetree.Children)-1)
 
-	for i, typeDeclaration := range parsetree.Children[1:] {
+	for _, typeDeclaration := range parsetree.Children[1:] {
 		declaration, err := a.analyzeTypeDeclaration(&typeDeclaration)
 
 		if err != nil {
-			return nil, err
+			a.report(err, typeDeclaration.FirstToken())
+			continue
 		}
 
-		declarations[i] = *declaration
+		spanned(declaration, &typeDeclaration)
+		declarations = append(declarations, *declaration)
 	}
 
 	return &dt.DecoratedSyntaxTree{
@@ -0,0 +1,188 @@
+package semantic
+
+import (
+	"errors"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// analyzeUnit analyzes a unit into the shared tables and returns its tab
+// entry. The interface declarations are chained from that entry, whose Data
+// is the last of them, so they are only visible where the unit is used. The
+// implementation declarations follow on from them and are never visible
+// outside the unit.
+func (a *SemanticAnalyzer) analyzeUnit(parsetree *dt.ParseTree) (int, error) {
+	if parsetree.RootType != dt.UNIT_NODE {
+		return -1, errors.New("expected unit")
+	}
+
+	name := parsetree.Children[0].Children[1].TokenValue.Lexeme
+
+	unitIndex := len(a.tab)
+	a.tab = append(a.tab, dt.TabEntry{
+		Identifier: name,
+		Link:       0, // the predeclared identifiers, as for a program
+		Object:     dt.TAB_ENTRY_UNIT,
+		Type:       dt.TAB_ENTRY_NONE,
+		Level:      a.depth,
+		Data:       unitIndex,
+	})
+
+	root, uses := a.root, a.uses
+	a.root = unitIndex
+
+	interfacePart := &parsetree.Children[1]
+	a.uses = a.useUnits(interfacePart.Uses())
+
+	declarations, err := a.analyzeDeclarationPart(interfacePart)
+	if err != nil {
+		a.report(err, interfacePart.FirstToken())
+	}
+
+	a.tab[unitIndex].Data = a.root
+
+	implementationPart := &parsetree.Children[2].Children[1]
+	implementation, err := a.analyzeDeclarationPart(implementationPart)
+	if err != nil {
+		a.report(err, implementationPart.FirstToken())
+	}
+	declarations = append(declarations, implementation...)
+
+	for tabIndex, token := range a.forward {
+		a.report(a.newMissingImplementationError(a.tab[tabIndex].Object.String(), token.Lexeme, token), token)
+		delete(a.forward, tabIndex)
+	}
+
+	if init := &parsetree.Children[3]; init.RootType == dt.COMPOUND_STATEMENT_NODE {
+		block, err := a.analyzeCompoundStatement(init)
+		if err != nil {
+			a.report(err, init.FirstToken())
+		} else {
+			spanned(block, init)
+			a.unitInits = append(a.unitInits, *block)
+		}
+	}
+
+	a.unitDecls = append(a.unitDecls, declarations...)
+	a.root, a.uses = root, uses
+
+	return unitIndex, nil
+}
+
+// analyzeUnitOnly checks a unit on its own, as when it is compiled without
+// a program. The tree it returns cannot be run.
+func (a *SemanticAnalyzer) analyzeUnitOnly(parsetree *dt.ParseTree) (*dt.DecoratedSyntaxTree, error) {
+	if parsetree.RootType != dt.UNIT_NODE {
+		return nil, errors.New("expected unit")
+	}
+
+	key := dt.NormalizeIdentifier(parsetree.Children[0].Children[1].TokenValue.Lexeme)
+	a.unitIndex[key] = -1
+
+	unitIndex, err := a.analyzeUnit(parsetree)
+	if err != nil {
+		return nil, err
+	}
+	a.unitIndex[key] = unitIndex
+
+	children := append(a.unitDecls, a.unitInits...)
+
+	return &dt.DecoratedSyntaxTree{
+		Property: dt.DST_ROOT,
+		SelfType: dt.DST_UNIT,
+		Span:     parsetree.Span,
+		Data:     unitIndex,
+		Children: children,
+	}, nil
+}
+
+// useUnits returns the tab entries of the units names refers to, analyzing
+// the ones that have not been analyzed yet.
+func (a *SemanticAnalyzer) useUnits(names []*dt.Token) []int {
+	uses := make([]int, 0, len(names))
+
+	for _, name := range names {
+		key := dt.NormalizeIdentifier(name.Lexeme)
+
+		if unitIndex, ok := a.unitIndex[key]; ok {
+			if unitIndex == -1 {
+				a.report(a.newUnitCycleError(name.Lexeme, name), name)
+				continue
+			}
+			uses = append(uses, unitIndex)
+			continue
+		}
+
+		unit, ok := a.units[key]
+		if !ok {
+			a.report(a.newUnitNotFoundError(name.Lexeme, name), name)
+			continue
+		}
+
+		a.unitIndex[key] = -1
+		unitIndex, err := a.analyzeUnit(unit)
+		if err != nil {
+			a.report(err, name)
+			delete(a.unitIndex, key)
+			continue
+		}
+
+		a.unitIndex[key] = unitIndex
+		uses = append(uses, unitIndex)
+	}
+
+	return uses
+}
+
+// implementedHeading returns the subprogram an interface heading declared
+// when parsetree is its implementation, and -1 otherwise.
+func (a *SemanticAnalyzer) implementedHeading(parsetree *dt.ParseTree, object dt.TabEntryObject) int {
+	if parsetree.RootType != dt.PROCEDURE_DECLARATION_NODE && parsetree.RootType != dt.FUNCTION_DECLARATION_NODE {
+		return -1
+	}
+
+	tabIndex, entry := a.tab.FindIdentifier(parsetree.Children[1].TokenValue.Lexeme, a.root)
+	if entry == nil || entry.Level != a.depth || entry.Object != object || a.forward[tabIndex] == nil {
+		return -1
+	}
+
+	delete(a.forward, tabIndex)
+	return tabIndex
+}
+
+// sameParameters reports whether parameters are the ones the heading whose
+// block is heading declared: as many, passed the same way, and of the same
+// types.
+func (a *SemanticAnalyzer) sameParameters(heading dt.BtabEntry, parameters *dt.DecoratedSyntaxTree) bool {
+	var declared []dt.DecoratedSyntaxTree
+	if parameters != nil {
+		declared = parameters.Children
+	}
+
+	if len(declared) != heading.ParamEnd-heading.Start+1 {
+		return false
+	}
+
+	for i, param := range declared {
+		want, got := a.tab[heading.Start+i], a.tab[param.Data]
+
+		if want.Normal != got.Normal || !a.checkTypeEquality(
+			semanticType{StaticType: want.Type, Reference: want.Reference},
+			semanticType{StaticType: got.Type, Reference: got.Reference},
+		) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// findExported looks id up among what the unit at unitIndex exports.
+func (a *SemanticAnalyzer) findExported(unitIndex int, id *dt.Token) (int, *dt.TabEntry, error) {
+	index, entry := a.tab.FindIdentifier(id.Lexeme, a.tab[unitIndex].Data)
+	if entry == nil || index <= unitIndex {
+		return -1, nil, a.newNotExportedError(a.tab[unitIndex].Identifier, id.Lexeme, id)
+	}
+
+	return index, entry, nil
+}
@@ -23,13 +23,15 @@ func (a *SemanticAnalyzer) analyzeVarDeclaration(parsetree *dt.ParseTree) ([]dt.
 		return nil, err
 	}
 
-	declarations := make([]dt.DecoratedSyntaxTree, len(identifiers))
+	declarations := make([]dt.DecoratedSyntaxTree, 0, len(identifiers))
 
 	for i, identifier := range identifiers {
 		_, check := a.tab.FindIdentifier(identifier, a.root)
 		if check != nil {
 			if check.Level == a.depth {
-				return nil, errors.New("cannot redeclare identifier in the same scope")
+				token := parsetree.Children[0].Children[2*i].TokenValue
+				a.report(a.newRedeclarationError(identifier, token), token)
+				continue
 			}
 		}
 
@@ -54,10 +56,11 @@ func (a *SemanticAnalyzer) analyzeVarDeclaration(parsetree *dt.ParseTree) ([]dt.
 		a.root = len(a.tab)
 		a.tab = append(a.tab, tabEntry)
 
-		declarations[i] = dt.DecoratedSyntaxTree{
+		declarations = append(declarations, dt.DecoratedSyntaxTree{
 			SelfType: dt.DST_VARIABLE,
 			Data:     a.root,
-		}
+			Span:     parsetree.Children[0].Children[2*i].Span,
+		})
 	}
 
 	return declarations, nil
@@ -18,7 +18,8 @@ func (a *SemanticAnalyzer) analyzeVarDeclarationPart(parsetree *dt.ParseTree) (*
 		partialDeclarations, err := a.analyzeVarDeclaration(&node)
 
 		if err != nil {
-			return nil, err
+			a.report(err, node.FirstToken())
+			continue
 		}
 
 		declarations = append(declarations, partialDeclarations...)
@@ -17,10 +17,10 @@ func (a *SemanticAnalyzer) analyzeWhileStatement(parsetree *dt.ParseTree) (*dt.D
 		return nil, err
 	}
 
-	if typ.StaticType != dt.TAB_ENTRY_BOOLEAN {
+	if !typ.isError() && typ.StaticType != dt.TAB_ENTRY_BOOLEAN {
 		// Get token from 'selama' keyword
 		token := parsetree.Children[0].TokenValue
-		return nil, a.newConditionTypeError(typ.StaticType.String(), token)
+		a.report(a.newConditionTypeError(typ.StaticType.String(), token), token)
 	}
 
 	block, err := a.analyzeStatement(&parsetree.Children[3])
@@ -0,0 +1,139 @@
+package vm
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"unicode"
+
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+// builtin applies a standard function to the cell v. isReal tells whether v
+// holds a real; the functions that always take a real get one from the
+// generator.
+func (m *VM) builtin(b dt.Builtin, isReal bool, v int) (int, error) {
+	switch b {
+	case dt.BUILTIN_ABS:
+		if isReal {
+			return fromReal(math.Abs(toReal(v))), nil
+		}
+		return max(v, -v), nil
+	case dt.BUILTIN_SQR:
+		if isReal {
+			return fromReal(toReal(v) * toReal(v)), nil
+		}
+		return v * v, nil
+	case dt.BUILTIN_ODD:
+		return fromBool(v%2 != 0), nil
+	case dt.BUILTIN_ORD, dt.BUILTIN_CHR:
+		return v, nil
+	case dt.BUILTIN_SUCC:
+		return v + 1, nil
+	case dt.BUILTIN_PRED:
+		return v - 1, nil
+	}
+
+	x := toReal(v)
+
+	switch b {
+	case dt.BUILTIN_SQRT:
+		if x < 0 {
+			return 0, m.trap(TRAP_INVALID_ARGUMENT, "sqrt of negative number %g", x)
+		}
+		return fromReal(math.Sqrt(x)), nil
+	case dt.BUILTIN_LN:
+		if x <= 0 {
+			return 0, m.trap(TRAP_INVALID_ARGUMENT, "ln of non-positive number %g", x)
+		}
+		return fromReal(math.Log(x)), nil
+	case dt.BUILTIN_SIN:
+		return fromReal(math.Sin(x)), nil
+	case dt.BUILTIN_COS:
+		return fromReal(math.Cos(x)), nil
+	case dt.BUILTIN_EXP:
+		return fromReal(math.Exp(x)), nil
+	case dt.BUILTIN_TRUNC:
+		return int(math.Trunc(x)), nil
+	case dt.BUILTIN_ROUND:
+		return int(math.Round(x)), nil
+	}
+
+	return 0, m.trap(TRAP_BAD_OPCODE, "unknown builtin %d", int(b))
+}
+
+// read reads one value of type typ. A char is the next character as is;
+// numbers skip leading white space.
+func (m *VM) read(typ dt.TabEntryType) (int, error) {
+	if err := m.out.Flush(); err != nil {
+		return 0, err
+	}
+
+	if typ == dt.TAB_ENTRY_CHAR {
+		r, _, err := m.in.ReadRune()
+		if err == io.EOF {
+			return 0, m.trap(TRAP_BAD_INPUT, "unexpected end of input")
+		}
+		return int(r), err
+	}
+
+	word, err := m.readWord()
+	if err != nil {
+		return 0, err
+	}
+
+	switch typ {
+	case dt.TAB_ENTRY_INTEGER:
+		n, err := strconv.Atoi(word)
+		if err != nil {
+			return 0, m.trap(TRAP_BAD_INPUT, "invalid integer input %q", word)
+		}
+		return n, nil
+	case dt.TAB_ENTRY_REAL:
+		x, err := strconv.ParseFloat(word, 64)
+		if err != nil {
+			return 0, m.trap(TRAP_BAD_INPUT, "invalid real input %q", word)
+		}
+		return fromReal(x), nil
+	}
+
+	return 0, m.trap(TRAP_BAD_INPUT, "cannot read a value of type %s", typ)
+}
+
+func (m *VM) readWord() (string, error) {
+	var word []rune
+
+	for {
+		r, _, err := m.in.ReadRune()
+		if err == io.EOF {
+			if len(word) == 0 {
+				return "", m.trap(TRAP_BAD_INPUT, "unexpected end of input")
+			}
+			return string(word), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if unicode.IsSpace(r) {
+			if len(word) == 0 {
+				continue
+			}
+			return string(word), m.in.UnreadRune()
+		}
+
+		word = append(word, r)
+	}
+}
+
+func (m *VM) skipLine() error {
+	for {
+		r, _, err := m.in.ReadRune()
+		if err == io.EOF || r == '\n' {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
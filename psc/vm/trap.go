@@ -0,0 +1,49 @@
+package vm
+
+import (
+	"fmt"
+)
+
+type TrapKind int
+
+const (
+	TRAP_STACK_OVERFLOW TrapKind = iota
+	TRAP_BAD_OPCODE
+	TRAP_DIVISION_BY_ZERO
+	TRAP_INDEX_OUT_OF_BOUNDS
+	TRAP_BAD_ADDRESS
+	TRAP_INVALID_ARGUMENT
+	TRAP_BAD_INPUT
+)
+
+func (k TrapKind) String() string {
+	names := [...]string{
+		"stack overflow",
+		"bad opcode",
+		"division by zero",
+		"index out of bounds",
+		"bad address",
+		"invalid argument",
+		"bad input",
+	}
+	if int(k) < 0 || int(k) >= len(names) {
+		return "unknown"
+	}
+	return names[k]
+}
+
+// Trap stops the machine. Line is the source line of the instruction at PC,
+// or 0 when the generator did not know it.
+type Trap struct {
+	Kind    TrapKind
+	PC      int
+	Line    int
+	Message string
+}
+
+func (t *Trap) Error() string {
+	if t.Line > 0 {
+		return fmt.Sprintf("Runtime error at line %d: %s", t.Line, t.Message)
+	}
+	return fmt.Sprintf("Runtime error at pc %d: %s", t.PC, t.Message)
+}
@@ -0,0 +1,520 @@
+package vm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/codegen"
+	dt "github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/datatype"
+)
+
+const DefaultStackSize = 1 << 20
+
+// VM executes a codegen.Program. Every call pushes a mark stack of
+// codegen.FrameHeader cells:
+//
+//	s[b+0] function result
+//	s[b+1] return address
+//	s[b+2] static link, the frame of the lexically enclosing block
+//	s[b+3] dynamic link, the frame of the caller
+//	s[b+4] block index
+//
+// display[l] always holds the base of the innermost active frame at level l,
+// so a variable at (level, offset) lives at s[display[level]+offset].
+type VM struct {
+	program *codegen.Program
+
+	stack   []int
+	display []int
+
+	pc int // next instruction
+	ir int // instruction being executed
+	t  int // top of stack
+	b  int // base of the current frame
+
+	in  *bufio.Reader
+	out *bufio.Writer
+}
+
+func New(program *codegen.Program, out io.Writer) *VM {
+	levels := 1
+	for _, block := range program.Blocks {
+		levels = max(levels, block.Level+1)
+	}
+
+	return &VM{
+		program: program,
+		stack:   make([]int, DefaultStackSize),
+		display: make([]int, levels),
+		in:      bufio.NewReader(strings.NewReader("")),
+		out:     bufio.NewWriter(out),
+	}
+}
+
+// SetInput sets where RED takes its input from. Without it the program sees
+// an empty input.
+func (m *VM) SetInput(in io.Reader) {
+	m.in = bufio.NewReader(in)
+}
+
+func (m *VM) SetStackSize(size int) {
+	m.stack = make([]int, size)
+}
+
+func (m *VM) Run() (err error) {
+	if len(m.program.Blocks) == 0 {
+		return errors.New("program has no main block")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(runtime.Error); !ok {
+				panic(r)
+			}
+			err = m.trap(TRAP_BAD_ADDRESS, "invalid memory access")
+		}
+
+		if flushErr := m.out.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+
+	main := m.program.Blocks[0]
+
+	m.b = 0
+	m.t = codegen.FrameHeader + main.VarSize - 1
+	m.pc = main.Entry
+
+	if m.t >= len(m.stack) {
+		return m.trap(TRAP_STACK_OVERFLOW, "stack overflow")
+	}
+
+	clear(m.stack[:m.t+1])
+
+	return m.execute()
+}
+
+func (m *VM) trap(kind TrapKind, format string, args ...any) error {
+	line := 0
+	if m.ir >= 0 && m.ir < len(m.program.Code) {
+		line = m.program.Code[m.ir].Line
+	}
+
+	return &Trap{
+		Kind:    kind,
+		PC:      m.ir,
+		Line:    line,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (m *VM) push(v int) error {
+	if m.t+1 >= len(m.stack) {
+		return m.trap(TRAP_STACK_OVERFLOW, "stack overflow")
+	}
+
+	m.t++
+	m.stack[m.t] = v
+	return nil
+}
+
+func (m *VM) grow(cells int) error {
+	if m.t+cells >= len(m.stack) {
+		return m.trap(TRAP_STACK_OVERFLOW, "stack overflow")
+	}
+
+	m.t += cells
+	return nil
+}
+
+func (m *VM) execute() error {
+	s := m.stack
+
+	for {
+		if m.pc < 0 || m.pc >= len(m.program.Code) {
+			m.ir = m.pc
+			return m.trap(TRAP_BAD_OPCODE, "program counter %d outside of the code", m.pc)
+		}
+
+		m.ir = m.pc
+		ins := m.program.Code[m.pc]
+		m.pc++
+
+		var err error
+
+		switch ins.Op {
+		case codegen.LDA:
+			err = m.push(m.display[ins.X] + ins.Y)
+		case codegen.LOD:
+			err = m.push(s[m.display[ins.X]+ins.Y])
+		case codegen.LDI:
+			err = m.push(s[s[m.display[ins.X]+ins.Y]])
+		case codegen.IND:
+			s[m.t] = s[s[m.t]]
+		case codegen.FLD:
+			s[m.t] += ins.Y
+		case codegen.INT:
+			if ins.Y > 0 {
+				err = m.grow(ins.Y)
+			} else {
+				m.t += ins.Y
+			}
+		case codegen.LDC:
+			err = m.push(ins.Y)
+		case codegen.LDR:
+			err = m.push(int(math.Float64bits(m.program.Reals[ins.Y])))
+
+		case codegen.LDS:
+			runes := []rune(m.program.Strings[ins.Y])
+			base := m.t + 1
+			if err = m.grow(ins.X); err == nil {
+				for i := range ins.X {
+					s[base+i] = 0
+					if i < len(runes) {
+						s[base+i] = int(runes[i])
+					}
+				}
+			}
+
+		case codegen.LDB:
+			addr := s[m.t]
+			m.t--
+			base := m.t + 1
+			if err = m.grow(ins.Y); err == nil {
+				copy(s[base:base+ins.Y], s[addr:addr+ins.Y])
+			}
+
+		case codegen.STO:
+			s[s[m.t-1]] = s[m.t]
+			m.t -= 2
+
+		case codegen.STB:
+			addr := s[m.t-ins.Y]
+			copy(s[addr:addr+ins.Y], s[m.t-ins.Y+1:m.t+1])
+			m.t -= ins.Y + 1
+
+		case codegen.CPB:
+			copy(s[s[m.t-1]:s[m.t-1]+ins.Y], s[s[m.t]:s[m.t]+ins.Y])
+			m.t -= 2
+
+		case codegen.CMB:
+			rhs := s[m.t-ins.Y+1 : m.t+1]
+			m.t -= ins.Y
+			lhs := s[m.t-ins.X+1 : m.t+1]
+			m.t -= ins.X
+			err = m.push(compareBlocks(lhs, rhs))
+
+		case codegen.IDX:
+			arr := m.program.Arrays[ins.Y]
+			index := s[m.t]
+			if index < arr.Low || index > arr.High {
+				return m.trap(TRAP_INDEX_OUT_OF_BOUNDS, "index %d out of bounds [%d..%d]", index, arr.Low, arr.High)
+			}
+			m.t--
+			s[m.t] += (index - arr.Low) * arr.ElementSize
+
+		case codegen.JMP:
+			m.pc = ins.Y
+		case codegen.JPC:
+			if s[m.t] == 0 {
+				m.pc = ins.Y
+			}
+			m.t--
+		case codegen.SWT:
+			m.pc = m.selectCase(s[m.t], ins.Y)
+			m.t--
+
+		case codegen.F1U:
+			if s[m.t-1] <= s[m.t] {
+				s[s[m.t-2]] = s[m.t-1]
+			} else {
+				m.t -= 3
+				m.pc = ins.Y
+			}
+		case codegen.F2U:
+			addr := s[m.t-2]
+			if s[addr] < s[m.t] {
+				s[addr]++
+				m.pc = ins.Y
+			} else {
+				m.t -= 3
+			}
+		case codegen.F1D:
+			if s[m.t-1] >= s[m.t] {
+				s[s[m.t-2]] = s[m.t-1]
+			} else {
+				m.t -= 3
+				m.pc = ins.Y
+			}
+		case codegen.F2D:
+			addr := s[m.t-2]
+			if s[addr] > s[m.t] {
+				s[addr]--
+				m.pc = ins.Y
+			} else {
+				m.t -= 3
+			}
+
+		case codegen.MKS:
+			base := m.t + 1
+			if err = m.grow(codegen.FrameHeader); err == nil {
+				clear(s[base : base+codegen.FrameHeader])
+				s[base+4] = ins.Y
+			}
+
+		case codegen.CAL:
+			err = m.call(ins.Y)
+
+		case codegen.EXP:
+			m.t = m.b - 1
+			m.exit()
+		case codegen.EXF:
+			m.t = m.b
+			m.exit()
+
+		case codegen.FLT:
+			s[m.t] = fromReal(float64(s[m.t]))
+		case codegen.NOT:
+			s[m.t] = fromBool(s[m.t] == 0)
+		case codegen.MUS:
+			if ins.X == 1 {
+				s[m.t] = fromReal(-toReal(s[m.t]))
+			} else {
+				s[m.t] = -s[m.t]
+			}
+
+		case codegen.EQL, codegen.NEQ, codegen.LSS, codegen.LEQ, codegen.GRT, codegen.GEQ,
+			codegen.ORR, codegen.AND, codegen.ADD, codegen.SUB, codegen.MUL, codegen.DIV, codegen.MOD:
+			l, r := s[m.t-1], s[m.t]
+			m.t--
+			s[m.t], err = m.integerOp(ins.Op, l, r)
+
+		case codegen.EQR, codegen.NER, codegen.LSR, codegen.LER, codegen.GTR, codegen.GER,
+			codegen.ADR, codegen.SUR, codegen.MUR, codegen.DIR:
+			l, r := toReal(s[m.t-1]), toReal(s[m.t])
+			m.t--
+			s[m.t], err = m.realOp(ins.Op, l, r)
+
+		case codegen.SFN:
+			s[m.t], err = m.builtin(dt.Builtin(ins.X), ins.Y == 1, s[m.t])
+		case codegen.RED:
+			addr := s[m.t]
+			m.t--
+			s[addr], err = m.read(dt.TabEntryType(ins.X))
+		case codegen.RDL:
+			err = m.skipLine()
+
+		case codegen.WRS:
+			_, err = m.out.WriteString(m.program.Strings[ins.Y])
+		case codegen.WRW:
+			_, err = m.out.WriteString(formatCell(dt.TabEntryType(ins.X), s[m.t]))
+			m.t--
+		case codegen.WRB:
+			addr := s[m.t]
+			m.t--
+			for i := 0; i < ins.Y && s[addr+i] != 0; i++ {
+				if _, err = m.out.WriteRune(rune(s[addr+i])); err != nil {
+					break
+				}
+			}
+
+		case codegen.WRL:
+			err = m.out.WriteByte('\n')
+
+		case codegen.HLT:
+			return nil
+
+		default:
+			return m.trap(TRAP_BAD_OPCODE, "bad opcode %d", int(ins.Op))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// selectCase scans the case table starting at pc and returns where execution
+// continues: the JMP following the matching CAS, or the instruction after the
+// table when no label matches.
+func (m *VM) selectCase(v int, pc int) int {
+	code := m.program.Code
+	for pc < len(code) && code[pc].Op == codegen.CAS {
+		if code[pc].X <= v && v <= code[pc].Y {
+			return pc + 1
+		}
+		pc += 2
+	}
+	return pc
+}
+
+// call finishes the frame started by MKS once the arguments are in place.
+func (m *VM) call(paramSize int) error {
+	base := m.t - paramSize - codegen.FrameHeader + 1
+	blockIndex := m.stack[base+4]
+
+	if blockIndex < 0 || blockIndex >= len(m.program.Blocks) {
+		return m.trap(TRAP_BAD_OPCODE, "call to unknown block %d", blockIndex)
+	}
+
+	block := m.program.Blocks[blockIndex]
+	top := base + codegen.FrameHeader + block.ParamSize + block.VarSize - 1
+
+	if top >= len(m.stack) {
+		return m.trap(TRAP_STACK_OVERFLOW, "stack overflow calling '%s'", block.Name)
+	}
+
+	m.stack[base+1] = m.pc
+	m.stack[base+2] = m.display[block.Level-1]
+	m.stack[base+3] = m.b
+
+	clear(m.stack[m.t+1 : top+1])
+
+	m.b = base
+	m.t = top
+	m.display[block.Level] = base
+	m.pc = block.Entry
+
+	return nil
+}
+
+// exit returns to the caller and rebuilds the display from its static chain,
+// since the callee may have overwritten entries the caller still needs.
+func (m *VM) exit() {
+	frame := m.b
+
+	m.pc = m.stack[frame+1]
+	m.b = m.stack[frame+3]
+
+	level := m.program.Blocks[m.stack[m.b+4]].Level
+	for base := m.b; level >= 0; level-- {
+		m.display[level] = base
+		base = m.stack[base+2]
+	}
+}
+
+func (m *VM) integerOp(op codegen.Opcode, l int, r int) (int, error) {
+	switch op {
+	case codegen.EQL:
+		return fromBool(l == r), nil
+	case codegen.NEQ:
+		return fromBool(l != r), nil
+	case codegen.LSS:
+		return fromBool(l < r), nil
+	case codegen.LEQ:
+		return fromBool(l <= r), nil
+	case codegen.GRT:
+		return fromBool(l > r), nil
+	case codegen.GEQ:
+		return fromBool(l >= r), nil
+	case codegen.ORR:
+		return fromBool(l != 0 || r != 0), nil
+	case codegen.AND:
+		return fromBool(l != 0 && r != 0), nil
+	case codegen.ADD:
+		return l + r, nil
+	case codegen.SUB:
+		return l - r, nil
+	case codegen.MUL:
+		return l * r, nil
+	case codegen.DIV:
+		if r == 0 {
+			return 0, m.trap(TRAP_DIVISION_BY_ZERO, "division by zero")
+		}
+		return l / r, nil
+	default:
+		if r == 0 {
+			return 0, m.trap(TRAP_DIVISION_BY_ZERO, "modulo by zero")
+		}
+		return l % r, nil
+	}
+}
+
+func (m *VM) realOp(op codegen.Opcode, l float64, r float64) (int, error) {
+	switch op {
+	case codegen.EQR:
+		return fromBool(l == r), nil
+	case codegen.NER:
+		return fromBool(l != r), nil
+	case codegen.LSR:
+		return fromBool(l < r), nil
+	case codegen.LER:
+		return fromBool(l <= r), nil
+	case codegen.GTR:
+		return fromBool(l > r), nil
+	case codegen.GER:
+		return fromBool(l >= r), nil
+	case codegen.ADR:
+		return fromReal(l + r), nil
+	case codegen.SUR:
+		return fromReal(l - r), nil
+	case codegen.MUR:
+		return fromReal(l * r), nil
+	default:
+		if r == 0 {
+			return 0, m.trap(TRAP_DIVISION_BY_ZERO, "division by zero")
+		}
+		return fromReal(l / r), nil
+	}
+}
+
+// compareBlocks compares two string blocks cell by cell up to the first 0
+// cell, treating a shorter block as padded with 0.
+func compareBlocks(l []int, r []int) int {
+	for k := 0; k < len(l) || k < len(r); k++ {
+		var lc, rc int
+		if k < len(l) {
+			lc = l[k]
+		}
+		if k < len(r) {
+			rc = r[k]
+		}
+
+		switch {
+		case lc < rc:
+			return -1
+		case lc > rc:
+			return 1
+		case lc == 0:
+			return 0
+		}
+	}
+	return 0
+}
+
+func toReal(cell int) float64 {
+	return math.Float64frombits(uint64(cell))
+}
+
+func fromReal(v float64) int {
+	return int(math.Float64bits(v))
+}
+
+func fromBool(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func formatCell(typ dt.TabEntryType, cell int) string {
+	switch typ {
+	case dt.TAB_ENTRY_REAL:
+		return strconv.FormatFloat(toReal(cell), 'g', -1, 64)
+	case dt.TAB_ENTRY_BOOLEAN:
+		if cell != 0 {
+			return "true"
+		}
+		return "false"
+	case dt.TAB_ENTRY_CHAR:
+		return string(rune(cell))
+	default:
+		return strconv.Itoa(cell)
+	}
+}
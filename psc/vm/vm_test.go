@@ -0,0 +1,160 @@
+package vm_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azzkaaaa/NIG-Tubes-IF2224/psc/compiler"
+)
+
+const rules = "../../config/tokenizer_m3.json"
+
+// result is what one engine wrote and the error that stopped it.
+type result struct {
+	Out string
+	Err error
+}
+
+// runBoth runs the program at path on the interpreter and on the VM. ok is
+// false when the program does not get past the analyzer.
+func runBoth(t *testing.T, path string) (ip result, machine result, ok bool) {
+	t.Helper()
+
+	res, err := compiler.CompileFile(path, compiler.Options{Rules: rules, StopAfter: compiler.STAGE_CODEGEN})
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	if res.HasErrors() {
+		return ip, machine, false
+	}
+
+	var out strings.Builder
+	ip.Err = res.Run(&out, compiler.RunOptions{Engine: compiler.ENGINE_INTERP})
+	ip.Out = out.String()
+
+	out.Reset()
+	machine.Err = res.Run(&out, compiler.RunOptions{Engine: compiler.ENGINE_VM})
+	machine.Out = out.String()
+
+	return ip, machine, true
+}
+
+// compare fails t when the two engines disagree on the output or on whether
+// the program stopped with an error.
+func compare(t *testing.T, name string, ip result, machine result) {
+	t.Helper()
+
+	if ip.Out != machine.Out {
+		t.Errorf("%s: interp wrote %q, vm wrote %q", name, ip.Out, machine.Out)
+	}
+	if (ip.Err == nil) != (machine.Err == nil) {
+		t.Errorf("%s: interp stopped with %v, vm with %v", name, ip.Err, machine.Err)
+	}
+}
+
+func TestEnginesAgreeOnMilestonePrograms(t *testing.T) {
+	paths, err := filepath.Glob("../../test/milestone-3/input-*.pas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no milestone-3 programs found")
+	}
+
+	for _, path := range paths {
+		ip, machine, ok := runBoth(t, path)
+		if !ok {
+			continue
+		}
+		compare(t, filepath.Base(path), ip, machine)
+	}
+}
+
+const enginesProgram = `program Mesin;
+variabel x, y, n: integer;
+  s: string;
+
+fungsi Faktorial(k: integer): integer;
+mulai
+  jika k <= 1 maka
+    Faktorial := 1
+  selain_itu
+    Faktorial := k * Faktorial(k - 1)
+selesai;
+
+prosedur Tukar(variabel a, b: integer);
+variabel c: integer;
+mulai
+  c := a;
+  a := b;
+  b := c
+selesai;
+
+prosedur Luar(k: integer);
+  prosedur Dalam;
+  mulai
+    n := n + k
+  selesai;
+mulai
+  Dalam;
+  jika k > 0 maka Luar(k - 1)
+selesai;
+
+mulai
+  writeln('rekursi ', Faktorial(5));
+
+  x := 1;
+  y := 2;
+  Tukar(x, y);
+  writeln('variabel ', x, ', ', y);
+
+  n := 0;
+  Luar(3);
+  writeln('bersarang ', n);
+
+  s := 'hi';
+  writeln('sama ', s = 'hi', ', ', s <> 'ha', ', ', s > 'ha');
+
+  untuk x := 1 ke 4 lakukan
+    kasus x dari
+      1: write('satu');
+      2, 3: write(', dua-tiga')
+    selain_itu
+      writeln(', lain')
+    selesai;
+
+  n := 1;
+  ulangi
+    n := n * 2
+  sampai n > 100;
+  writeln('ulangi ', n);
+
+  y := 0;
+  x := x bagi y;
+  writeln('salah')
+selesai.
+`
+
+func TestEnginesAgree(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mesin.pas")
+	if err := os.WriteFile(path, []byte(enginesProgram), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ip, machine, ok := runBoth(t, path)
+	if !ok {
+		t.Fatal("program does not pass analysis")
+	}
+
+	compare(t, "mesin", ip, machine)
+
+	want := "rekursi 120\nvariabel 2, 1\nbersarang 6\nsama true, true, true\nsatu, dua-tiga, dua-tiga, lain\nulangi 128\n"
+	if machine.Out != want {
+		t.Errorf("vm wrote %q, want %q", machine.Out, want)
+	}
+	if machine.Err == nil || !strings.Contains(machine.Err.Error(), "division by zero") {
+		t.Errorf("vm stopped with %v, want a division by zero trap", machine.Err)
+	}
+}